@@ -0,0 +1,231 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+// Package bind extracts values from JSON documents into struct fields
+// tagged with the JSON Pointer of their value ([Bind]), and builds
+// documents from such structs ([Build]):
+//
+//	type Item struct {
+//		Name  string `jsonptr:"/data/attributes/name,required"`
+//		Count int    `jsonptr:"/data/attributes/count,default=1"`
+//	}
+//
+// Options follow the pointer, separated by commas (the pointer can't contain
+// a comma):
+//   - required: for Bind, a missing value is an error
+//   - default=value: for Bind, the value used if missing, as JSON (or as a
+//     string if not valid JSON). It must be the last option.
+//   - omitempty: for Build, the field is skipped if it has an empty value,
+//     as defined by [encoding/json]
+//
+// Fields of struct type without a tag (including embedded structs) are
+// processed recursively, with pointers from the root of the document. Other
+// fields without a tag, or with tag "-", are ignored.
+//
+// Values are converted between the types of the fields and JSON like
+// [encoding/json] does.
+//
+// This package uses reflect, which package jsonptr avoids.
+package bind
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+// field is a struct field bound to a pointer.
+type field struct {
+	v        reflect.Value
+	name     string // Go name, for error messages
+	ptr      string
+	required bool
+	def      json.RawMessage
+	omit     bool // omitempty
+}
+
+var errTarget = errors.New("bind: target must be a non-nil pointer to a struct")
+
+// Bind sets the fields of the struct pointed by target with the values of
+// doc at the pointers given by the jsonptr tags of the fields.
+//
+// doc is either a serialized document ([]byte, json.RawMessage, scanned
+// only once for all the fields, or a [jsonptr.JSONDecoder], from which one
+// value is read), or any document supported by [jsonptr.Get].
+//
+// A value that is not found leaves the field untouched, unless it is
+// required or has a default. All the failures (missing required value, path
+// through a value which is not a container, conversion error) are reported
+// in a [jsonptr.Errors], each located at the pointer of the field. An
+// invalid tag is reported as an error before any field is set.
+func Bind(doc interface{}, target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errTarget
+	}
+	var fields []field
+	if err := collect(rv.Elem(), &fields); err != nil {
+		return err
+	}
+
+	switch d := doc.(type) {
+	case json.RawMessage:
+		return bindRaw(d, fields)
+	case []byte:
+		return bindRaw(d, fields)
+	case jsonptr.JSONDecoder:
+		var raw json.RawMessage
+		if err := d.Decode(&raw); err != nil {
+			return &jsonptr.DocumentError{Err: err}
+		}
+		return bindRaw(raw, fields)
+	}
+
+	var errs jsonptr.Errors
+	for i := range fields {
+		f := &fields[i]
+		v, err := jsonptr.Get(doc, f.ptr)
+		if err != nil {
+			f.missing(&errs, err)
+			continue
+		}
+		errs.Add(jsonptr.MustParse(f.ptr), f.set(v))
+	}
+	return errs.Err()
+}
+
+// bindRaw sets fields from the serialized document doc.
+func bindRaw(doc []byte, fields []field) error {
+	ptrs := make([]string, len(fields))
+	for i := range fields {
+		ptrs[i] = fields[i].ptr
+	}
+	values, err := jsonptr.GetRawAll(doc, ptrs)
+	missing, ok := err.(jsonptr.Errors)
+	if err != nil && !ok {
+		return err
+	}
+	var errs jsonptr.Errors
+	for i, raw := range values {
+		f := &fields[i]
+		if raw == nil {
+			// Errors are in the order of the missing values
+			var err error = &jsonptr.PtrError{Ptr: f.ptr, Err: jsonptr.ErrProperty}
+			if len(missing) > 0 {
+				err, missing = missing[0].Err, missing[1:]
+			}
+			f.missing(&errs, err)
+			continue
+		}
+		errs.Add(jsonptr.MustParse(f.ptr), f.unmarshal(raw))
+	}
+	return errs.Err()
+}
+
+// missing handles the absence of the value of f, reported by err.
+func (f *field) missing(errs *jsonptr.Errors, err error) {
+	ptr := jsonptr.MustParse(f.ptr)
+	switch {
+	case f.def != nil:
+		errs.Add(ptr, f.unmarshal(f.def))
+	case f.required:
+		errs.Add(ptr, err)
+	default:
+		// Only a value that is not found is optional
+		if _, notFound := err.(*jsonptr.PtrError); !notFound {
+			errs.Add(ptr, err)
+		}
+	}
+}
+
+// set converts v (from the data model) into the field.
+func (f *field) set(v interface{}) error {
+	if v != nil {
+		if rv := reflect.ValueOf(v); rv.Type().AssignableTo(f.v.Type()) {
+			f.v.Set(rv)
+			return nil
+		}
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return f.unmarshal(raw)
+}
+
+func (f *field) unmarshal(raw json.RawMessage) error {
+	return json.Unmarshal(raw, f.v.Addr().Interface())
+}
+
+// collect appends to fields the tagged fields of struct s.
+func collect(s reflect.Value, fields *[]field) error {
+	t := s.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, tagged := sf.Tag.Lookup("jsonptr")
+		if !tagged {
+			if sf.Type.Kind() == reflect.Struct && (sf.PkgPath == "" || sf.Anonymous) {
+				if err := collect(s.Field(i), fields); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+		if sf.PkgPath != "" {
+			return errors.New("bind: unexported field " + t.Name() + "." + sf.Name + " has a jsonptr tag")
+		}
+		f := field{v: s.Field(i), name: t.Name() + "." + sf.Name}
+		if err := f.parseTag(tag); err != nil {
+			return errors.New("bind: invalid tag of " + f.name + ": " + err.Error())
+		}
+		*fields = append(*fields, f)
+	}
+	return nil
+}
+
+// parseTag sets the pointer and the options of f from its jsonptr tag.
+// Options start at the first comma.
+func (f *field) parseTag(tag string) error {
+	p := strings.IndexByte(tag, ',')
+	if p < 0 {
+		p = len(tag)
+	}
+	if _, err := jsonptr.Parse(tag[:p]); err != nil {
+		return err
+	}
+	f.ptr = tag[:p]
+	for p < len(tag) {
+		opts := tag[p+1:]
+		if strings.HasPrefix(opts, "default=") {
+			def := opts[len("default="):]
+			if json.Valid([]byte(def)) {
+				f.def = json.RawMessage(def)
+			} else {
+				f.def, _ = json.Marshal(def)
+			}
+			break
+		}
+		n := strings.IndexByte(opts, ',')
+		if n < 0 {
+			n = len(opts)
+		}
+		switch opt := opts[:n]; opt {
+		case "required":
+			f.required = true
+		case "omitempty":
+			f.omit = true
+		default:
+			return errors.New("unknown option " + strconv.Quote(opt))
+		}
+		p += 1 + n
+	}
+	return nil
+}
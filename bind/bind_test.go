@@ -0,0 +1,191 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package bind_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dolmen-go/jsonptr"
+	"github.com/dolmen-go/jsonptr/bind"
+)
+
+type Meta struct {
+	Version int `jsonptr:"/meta/version,default=1"`
+}
+
+type payload struct {
+	Meta
+	ID    string                `jsonptr:"/data/id,required"`
+	Name  string                `jsonptr:"/data/attributes/name"`
+	Tags  []string              `jsonptr:"/data/attributes/tags"`
+	Score float64               `jsonptr:"/data/attributes/score,default=0.5"`
+	Kind  string                `jsonptr:"/data/type,default=item"`
+	Attrs map[string]int        `jsonptr:"/data/attributes/counts"`
+	Raw   json.RawMessage       `jsonptr:"/data/attributes/tags/0"`
+	Links struct{ Self string } `jsonptr:"/data/links"`
+	Owner struct {
+		Login string `jsonptr:"/data/relationships/owner/login"`
+	}
+	Skipped string `jsonptr:"-"`
+	Other   string
+}
+
+const payloadJSON = `{
+	"data": {
+		"id": "42",
+		"type": "widget",
+		"attributes": {"name": "Foo", "tags": ["a", "b"], "counts": {"x": 1}},
+		"links": {"Self": "/w/42"},
+		"relationships": {"owner": {"login": "bob"}}
+	}
+}`
+
+func TestBind(t *testing.T) {
+	var generic interface{}
+	if err := json.Unmarshal([]byte(payloadJSON), &generic); err != nil {
+		t.Fatal(err)
+	}
+	ordered, _ := jsonptr.UnmarshalOrdered([]byte(payloadJSON))
+
+	expected := payload{
+		Meta:  Meta{Version: 1},
+		ID:    "42",
+		Name:  "Foo",
+		Tags:  []string{"a", "b"},
+		Score: 0.5,
+		Kind:  "widget",
+		Attrs: map[string]int{"x": 1},
+		Raw:   json.RawMessage(`"a"`),
+		Other: "untouched",
+	}
+	expected.Links.Self = "/w/42"
+	expected.Owner.Login = "bob"
+
+	for _, doc := range []interface{}{
+		json.RawMessage(payloadJSON),
+		[]byte(payloadJSON),
+		json.NewDecoder(bytes.NewReader([]byte(payloadJSON))),
+		generic,
+		ordered,
+	} {
+		p := payload{Other: "untouched", Skipped: "x"}
+		if err := bind.Bind(doc, &p); err != nil {
+			t.Errorf("%T: %v", doc, err)
+			continue
+		}
+		p.Skipped = ""
+		// Normalize raw values extracted from decoded documents
+		var raw interface{}
+		_ = json.Unmarshal(p.Raw, &raw)
+		p.Raw, _ = json.Marshal(raw)
+		if !reflect.DeepEqual(p, expected) {
+			t.Errorf("%T:\ngot:      %+v\nexpected: %+v", doc, p, expected)
+		}
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	const doc = `{"data":{"type":1,"attributes":"none","tags":{}}}`
+	for _, d := range []interface{}{
+		json.RawMessage(doc),
+		jsonptr.MustValue(jsonptr.UnmarshalOrdered([]byte(doc))),
+	} {
+		var p payload
+		err := bind.Bind(d, &p)
+		errs, ok := err.(jsonptr.Errors)
+		if !ok {
+			t.Fatalf("%T: got %T %v", d, err, err)
+		}
+		var got []string
+		for _, e := range errs {
+			got = append(got, e.Error())
+		}
+		expected := []string{
+			`"/data/id": property not found`,
+			`"/data/attributes/name": "/data/attributes": not an object or array but string`,
+			`"/data/attributes/tags": "/data/attributes": not an object or array but string`,
+			`"/data/type": json: cannot unmarshal number into Go value of type string`,
+			`"/data/attributes/counts": "/data/attributes": not an object or array but string`,
+			`"/data/attributes/tags/0": "/data/attributes": not an object or array but string`,
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("%T:\ngot:      %q\nexpected: %q", d, got, expected)
+		}
+		// Other fields are set
+		if p.Version != 1 || p.Score != 0.5 {
+			t.Errorf("%T: got %+v", d, p)
+		}
+	}
+
+	var p payload
+	for _, target := range []interface{}{nil, p, &p.ID, (*payload)(nil)} {
+		if err := bind.Bind(json.RawMessage(`{}`), target); err == nil {
+			t.Errorf("%T: error expected", target)
+		}
+	}
+	var bad struct {
+		A int `jsonptr:"a"`
+	}
+	if err := bind.Bind(json.RawMessage(`{}`), &bad); err == nil {
+		t.Error("error expected")
+	}
+	if err := bind.Bind(json.RawMessage(`{`), &p); err == nil {
+		t.Error("error expected")
+	}
+}
+
+func TestBindTagOptions(t *testing.T) {
+	for _, target := range []interface{}{
+		&struct {
+			A int `jsonptr:"/a,requird"`
+		}{},
+		&struct {
+			A int `jsonptr:"/a,omitempty,"`
+		}{},
+		&struct {
+			A int `jsonptr:"/a,xrequired"`
+		}{},
+		&struct {
+			A int `jsonptr:"/a,b"`
+		}{},
+	} {
+		err := bind.Bind(json.RawMessage(`{"a":1}`), target)
+		if err == nil || !strings.Contains(err.Error(), "invalid tag") {
+			t.Errorf("%T: got %v", target, err)
+		}
+		if _, err = bind.Build(target); err == nil {
+			t.Errorf("%T: Build: error expected", target)
+		}
+	}
+
+	var v struct {
+		A string `jsonptr:"/a,omitempty,required,default=x,y"`
+		B string `jsonptr:"/b,required"`
+	}
+	err := bind.Bind(json.RawMessage(`{}`), &v)
+	if v.A != "x,y" || err == nil || err.Error() != `"/b": property not found` {
+		t.Errorf("got %+v, %v", v, err)
+	}
+}
+
+func ExampleBind() {
+	var user struct {
+		Name  string `jsonptr:"/data/attributes/name,required"`
+		Email string `jsonptr:"/data/attributes/contact/email"`
+		Role  string `jsonptr:"/data/attributes/role,default=guest"`
+		Age   int    `jsonptr:"/data/attributes/age,required"`
+	}
+	err := bind.Bind(json.RawMessage(`{"data":{"attributes":{"name":"Alice","contact":{"email":"alice@example.com"},"age":"30"}}}`), &user)
+	fmt.Println(user.Name, user.Email, user.Role)
+	fmt.Println(err)
+	// Output:
+	// Alice alice@example.com guest
+	// "/data/attributes/age": json: cannot unmarshal string into Go value of type int
+}
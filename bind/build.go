@@ -0,0 +1,146 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package bind
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+var (
+	// ErrConflict is wrapped in the *[jsonptr.PtrError] reported by Build
+	// for tags with conflicting locations.
+	ErrConflict = errors.New("conflicting locations")
+
+	errSource = errors.New("bind: source must be a struct or a pointer to a struct")
+)
+
+// Build returns a document made of the values of the fields of the struct
+// source, each placed at the pointer given by its jsonptr tag, in the order
+// of the fields.
+//
+// Missing containers are created: an array if the next reference token is
+// an array index (or "-", which appends), an *[jsonptr.Object] otherwise.
+// Values are converted to the data model through their JSON serialization,
+// with objects as *jsonptr.Object. The document is nil if no field is
+// placed.
+//
+// Tags which would place values at the same location, or inside another
+// value, or use a location both as an array and as an object, or append
+// ("-") to an array which also has elements placed by index, are reported
+// as a *[jsonptr.PtrError] wrapping [ErrConflict], whatever the
+// values. Failures to serialize values are reported in a [jsonptr.Errors].
+func Build(source interface{}) (interface{}, error) {
+	rv := reflect.Indirect(reflect.ValueOf(source))
+	if rv.Kind() != reflect.Struct {
+		return nil, errSource
+	}
+	var fields []field
+	if err := collect(rv, &fields); err != nil {
+		return nil, err
+	}
+	ptrs := make([]jsonptr.Pointer, len(fields))
+	for i := range fields {
+		ptr := jsonptr.MustParse(fields[i].ptr)
+		for k, tok := range ptr {
+			if tok == "-" && k < len(ptr)-1 {
+				return nil, errors.New("bind: invalid tag of " + fields[i].name + ": \"-\" must be the last reference token")
+			}
+		}
+		for _, other := range ptrs[:i] {
+			if conflict(other, ptr) {
+				return nil, &jsonptr.PtrError{Ptr: fields[i].ptr, Err: ErrConflict}
+			}
+		}
+		ptrs[i] = ptr
+	}
+
+	var doc interface{}
+	var errs jsonptr.Errors
+	for i := range fields {
+		f := &fields[i]
+		if f.omit && isEmptyValue(f.v) {
+			continue
+		}
+		raw, err := json.Marshal(f.v.Interface())
+		if err != nil {
+			errs.Add(ptrs[i], err)
+			continue
+		}
+		// No error can happen as raw is valid JSON
+		v, _ := jsonptr.UnmarshalOrdered(raw)
+		errs.Add(ptrs[i], place(&doc, ptrs[i], v))
+	}
+	return doc, errs.Err()
+}
+
+// place sets v at ptr in doc, creating the missing containers.
+func place(doc *interface{}, ptr jsonptr.Pointer, v interface{}) error {
+	for k := range ptr {
+		parent := ptr[:k]
+		if cur, err := parent.In(*doc); err == nil && cur != nil {
+			continue
+		}
+		var container interface{} = jsonptr.NewObject()
+		if isIndex(ptr[k]) {
+			container = []interface{}{}
+		}
+		if err := parent.Set(doc, container); err != nil {
+			return err
+		}
+	}
+	return ptr.Set(doc, v)
+}
+
+// conflict reports if values placed at a and b would overlap.
+func conflict(a, b jsonptr.Pointer) bool {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for k := 0; k < n; k++ {
+		if a[k] != b[k] {
+			// Same container, used as an array and as an object? Or an
+			// array both appended to and indexed: the index of the
+			// appended element depends on which values are placed
+			return isIndex(a[k]) != isIndex(b[k]) || a[k] == "-" || b[k] == "-"
+		}
+		if a[k] == "-" {
+			// Last token of both: distinct elements appended
+			return false
+		}
+	}
+	// Same location, or one inside the other
+	return true
+}
+
+// isIndex reports if tok is an array index or "-".
+func isIndex(tok string) bool {
+	_, err := jsonptr.Pointer{tok}.LeafIndex()
+	return err == nil
+}
+
+// isEmptyValue is the definition of an empty value for omitempty in
+// encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package bind_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/dolmen-go/jsonptr"
+	"github.com/dolmen-go/jsonptr/bind"
+)
+
+func TestBuild(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	type source struct {
+		Meta
+		ID      string            `jsonptr:"/data/id"`
+		Kind    string            `jsonptr:"/data/type"`
+		Name    string            `jsonptr:"/data/attributes/name,omitempty"`
+		Nick    string            `jsonptr:"/data/attributes/nick"`
+		First   string            `jsonptr:"/data/tags/0"`
+		Third   string            `jsonptr:"/data/tags/2"`
+		More    string            `jsonptr:"/data/more/-"`
+		Last    string            `jsonptr:"/data/more/-,omitempty"`
+		Origin  point             `jsonptr:"/data/geo/0/origin"`
+		Labels  map[string]string `jsonptr:"/data/labels,omitempty"`
+		Count   *int              `jsonptr:"/data/count"`
+		Weird   string            `jsonptr:"/data/a~1b"`
+		Skipped string            `jsonptr:"-"`
+		Other   string
+	}
+	for _, test := range []struct {
+		src      interface{}
+		expected string
+	}{
+		{
+			source{Meta: Meta{Version: 2}, ID: "42", Kind: "widget", First: "a", Third: "c", More: "d", Last: "e", Origin: point{1, 2}, Weird: "w", Skipped: "x", Other: "y"},
+			`{"meta":{"version":2},"data":{"id":"42","type":"widget","attributes":{"nick":""},"tags":["a",null,"c"],"more":["d","e"],"geo":[{"origin":{"X":1,"Y":2}}],"count":null,"a/b":"w"}}`,
+		},
+		{
+			&source{Name: "n", Labels: map[string]string{"k": "v"}},
+			`{"meta":{"version":0},"data":{"id":"","type":"","attributes":{"name":"n","nick":""},"tags":["",null,""],"more":[""],"geo":[{"origin":{"X":0,"Y":0}}],"labels":{"k":"v"},"count":null,"a/b":""}}`,
+		},
+		{
+			struct {
+				A []int `jsonptr:"/0/a,omitempty"`
+				B int   `jsonptr:"/1"`
+			}{B: 1},
+			`[null,1]`,
+		},
+		{
+			struct {
+				A []int `jsonptr:",omitempty"`
+			}{A: []int{1}},
+			`[1]`,
+		},
+		{
+			struct {
+				A int `jsonptr:"/a,omitempty"`
+			}{},
+			`null`,
+		},
+		{
+			struct{}{},
+			`null`,
+		},
+	} {
+		doc, err := bind.Build(test.src)
+		if err != nil {
+			t.Errorf("%+v: %v", test.src, err)
+			continue
+		}
+		if b, _ := json.Marshal(doc); string(b) != test.expected {
+			t.Errorf("%+v:\ngot:      %s\nexpected: %s", test.src, b, test.expected)
+		}
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	for _, test := range []struct {
+		src interface{}
+		err string
+	}{
+		{struct {
+			A int `jsonptr:"/a"`
+			B int `jsonptr:"/a,omitempty"`
+		}{}, `"/a": conflicting locations`},
+		{struct {
+			A int `jsonptr:"/a"`
+			B int `jsonptr:"/a/b,omitempty"`
+		}{}, `"/a/b": conflicting locations`},
+		{struct {
+			A int `jsonptr:"/a/b/c"`
+			B int `jsonptr:"/a"`
+		}{}, `"/a": conflicting locations`},
+		{struct {
+			A int `jsonptr:"/a/0"`
+			B int `jsonptr:"/a/x"`
+		}{}, `"/a/x": conflicting locations`},
+		{struct {
+			A int `jsonptr:"/a/-"`
+			B int `jsonptr:"/a/b"`
+		}{}, `"/a/b": conflicting locations`},
+		{struct {
+			X string `jsonptr:"/a/-"`
+			Y string `jsonptr:"/a/0"`
+		}{"x", "y"}, `"/a/0": conflicting locations`},
+		{struct {
+			X string `jsonptr:"/a/1/b"`
+			Y string `jsonptr:"/a/-"`
+		}{"x", "y"}, `"/a/-": conflicting locations`},
+		{struct {
+			A int `jsonptr:"/a/-"`
+			B int `jsonptr:"/a/-/b"`
+		}{}, `bind: invalid tag of .B: "-" must be the last reference token`},
+		{struct {
+			A int `jsonptr:""`
+			B int `jsonptr:"/b"`
+		}{}, `"/b": conflicting locations`},
+		{struct {
+			A int `jsonptr:"a"`
+		}{}, `bind: invalid tag of .A: "a": invalid JSON pointer`},
+		{struct {
+			A func() `jsonptr:"/a"`
+			B int    `jsonptr:"/b"`
+		}{}, `"/a": json: unsupported type: func()`},
+		{42, `bind: source must be a struct or a pointer to a struct`},
+	} {
+		_, err := bind.Build(test.src)
+		if err == nil {
+			t.Errorf("%+v: error expected", test.src)
+			continue
+		}
+		if err.Error() != test.err {
+			t.Errorf("%+v: got %q, expected %q", test.src, err, test.err)
+		}
+	}
+
+	// Conflicts are detected whatever the values
+	_, err := bind.Build(struct {
+		A int `jsonptr:"/a/0,omitempty"`
+		B int `jsonptr:"/a/b,omitempty"`
+	}{})
+	if e, ok := err.(*jsonptr.PtrError); !ok || e.Err != bind.ErrConflict {
+		t.Errorf("got %#v", err)
+	}
+}
+
+func ExampleBuild() {
+	type order struct {
+		ID       string  `jsonptr:"/order/id"`
+		Customer string  `jsonptr:"/order/customer/name"`
+		Email    string  `jsonptr:"/order/customer/contact/email,omitempty"`
+		Item     string  `jsonptr:"/order/lines/0/sku"`
+		Qty      int     `jsonptr:"/order/lines/0/qty"`
+		Total    float64 `jsonptr:"/order/total"`
+	}
+	doc, err := bind.Build(order{ID: "A1", Customer: "Alice", Item: "X-1", Qty: 2, Total: 9.5})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	b, _ := json.Marshal(doc)
+	fmt.Printf("%s\n", b)
+	// Output:
+	// {"order":{"id":"A1","customer":{"name":"Alice"},"lines":[{"sku":"X-1","qty":2}],"total":9.5}}
+}
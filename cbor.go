@@ -0,0 +1,499 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// CBOR is a CBOR (RFC 8949) encoded document. It can be used as an input to
+// [Get] and [Pointer.In].
+//
+// The items crossed by the pointer are skipped by their header, without
+// decoding. Maps are navigated as objects (their keys must be text strings)
+// and arrays by index. The item located is decoded into the data model of
+// [encoding/json.Unmarshal]: numbers become float64, text strings string,
+// byte strings []byte, maps map[string]interface{}, arrays []interface{};
+// undefined becomes nil and tags are ignored.
+//
+// A map key which is not a text string is reported as a *DocumentError
+// wrapping ErrMapKey. A text string decoded (or compared to a reference
+// token) which is not valid UTF-8 is malformed.
+//
+// Specification: https://www.rfc-editor.org/rfc/rfc8949
+type CBOR []byte
+
+// ErrMapKey is wrapped in the *DocumentError reported for a CBOR map key
+// which is not a text string.
+var ErrMapKey = errors.New("map key is not a text string")
+
+// CBOR major types
+const (
+	cborUint = iota
+	cborNegInt
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+const cborBreak = 0xff
+
+// cborHead parses the head of the data item at doc[i].
+// For indefinite lengths, indef is true.
+func cborHead(doc []byte, i int) (major byte, arg uint64, indef bool, next int, ok bool) {
+	if i >= len(doc) {
+		return 0, 0, false, i, false
+	}
+	major = doc[i] >> 5
+	info := doc[i] & 0x1f
+	i++
+	switch {
+	case info < 24:
+		return major, uint64(info), false, i, true
+	case info == 24:
+		if i+1 > len(doc) {
+			return
+		}
+		return major, uint64(doc[i]), false, i + 1, true
+	case info == 25:
+		if i+2 > len(doc) {
+			return
+		}
+		return major, uint64(binary.BigEndian.Uint16(doc[i:])), false, i + 2, true
+	case info == 26:
+		if i+4 > len(doc) {
+			return
+		}
+		return major, uint64(binary.BigEndian.Uint32(doc[i:])), false, i + 4, true
+	case info == 27:
+		if i+8 > len(doc) {
+			return
+		}
+		return major, binary.BigEndian.Uint64(doc[i:]), false, i + 8, true
+	case info == 31:
+		switch major {
+		case cborBytes, cborText, cborArray, cborMap:
+			return major, 0, true, i, true
+		}
+	}
+	// Reserved values, or break out of an indefinite length item
+	return
+}
+
+// cborSkipTags returns the index of the item following the tags at doc[i].
+func cborSkipTags(doc []byte, i int) int {
+	for {
+		major, _, _, next, ok := cborHead(doc, i)
+		if !ok || major != cborTag {
+			return i
+		}
+		i = next
+	}
+}
+
+// cborSkip returns the index following the data item at doc[i].
+// Nesting is handled without recursion.
+func cborSkip(doc []byte, i int) (int, bool) {
+	// remaining is the number of items left at each level (indefinite for
+	// indefinite length)
+	remaining := []uint64{1}
+	const indefinite = math.MaxUint64
+	for len(remaining) > 0 {
+		top := len(remaining) - 1
+		if remaining[top] == 0 {
+			remaining = remaining[:top]
+			continue
+		}
+		if i >= len(doc) {
+			return i, false
+		}
+		if doc[i] == cborBreak {
+			if remaining[top] != indefinite {
+				return i, false
+			}
+			remaining = remaining[:top]
+			i++
+			continue
+		}
+		if remaining[top] != indefinite {
+			remaining[top]--
+		}
+		major, arg, indef, next, ok := cborHead(doc, i)
+		if !ok {
+			return i, false
+		}
+		i = next
+		switch major {
+		case cborBytes, cborText:
+			if !indef {
+				if arg > uint64(len(doc)-i) {
+					return len(doc), false
+				}
+				i += int(arg)
+				continue
+			}
+			// Chunks of definite length, up to the break
+			for {
+				if i >= len(doc) {
+					return i, false
+				}
+				if doc[i] == cborBreak {
+					i++
+					break
+				}
+				m, n, indef, next, ok := cborHead(doc, i)
+				if !ok || m != major || indef || n > uint64(len(doc)-next) {
+					return i, false
+				}
+				i = next + int(n)
+			}
+		case cborArray, cborMap:
+			n := uint64(indefinite)
+			if !indef {
+				n = arg
+				if major == cborMap {
+					if n > uint64(len(doc)) {
+						return len(doc), false
+					}
+					n *= 2
+				}
+			}
+			remaining = append(remaining, n)
+		case cborTag:
+			remaining = append(remaining, 1)
+		}
+	}
+	return i, true
+}
+
+// cborReadText decodes the text string at doc[i]. The chunks of an
+// indefinite length string must be text strings of definite length, and
+// each must be valid UTF-8.
+func cborReadText(doc []byte, i int) (s string, next int, ok bool) {
+	major, arg, indef, next, ok := cborHead(doc, i)
+	if !ok || major != cborText {
+		return "", i, false
+	}
+	if !indef {
+		if arg > uint64(len(doc)-next) || !utf8.Valid(doc[next:next+int(arg)]) {
+			return "", i, false
+		}
+		return string(doc[next : next+int(arg)]), next + int(arg), true
+	}
+	var b strings.Builder
+	for i = next; ; {
+		if i >= len(doc) {
+			return "", i, false
+		}
+		if doc[i] == cborBreak {
+			return b.String(), i + 1, true
+		}
+		m, n, indef, next, ok := cborHead(doc, i)
+		if !ok || m != cborText || indef || n > uint64(len(doc)-next) {
+			return "", i, false
+		}
+		chunk := doc[next : next+int(n)]
+		if !utf8.Valid(chunk) {
+			return "", i, false
+		}
+		b.Write(chunk)
+		i = next + int(n)
+	}
+}
+
+func cborError(ptr string, offset int) *DocumentError {
+	return &DocumentError{
+		Ptr: ptr,
+		Err: fmt.Errorf("malformed CBOR at offset %d", offset),
+	}
+}
+
+func cborKeyError(ptr string) *DocumentError {
+	return &DocumentError{Ptr: ptr, Err: ErrMapKey}
+}
+
+// getCBOR is the implementation of Get for CBOR documents.
+func getCBOR(doc CBOR, ptr string, opts *Options) (interface{}, ptrError) {
+	if max := opts.maxBytes(); max > 0 && int64(len(doc)) > max {
+		return nil, limitError("")
+	}
+	i := 0
+	depth := 0
+	if len(ptr) > 0 {
+		p := int(1)
+		cur := ptr[1:]
+		for {
+			q := strings.IndexByte(cur, '/')
+			if q != -1 {
+				cur = cur[:q]
+			} else {
+				q = len(cur)
+			}
+			p += q
+
+			i = cborSkipTags(doc, i)
+			major, arg, indef, next, ok := cborHead(doc, i)
+			if !ok {
+				return nil, cborError(ptr[:p-q-1], i)
+			}
+			depth++
+			if max := opts.maxDepth(); max > 0 && depth > max {
+				return nil, limitError(ptr[:p-q-1])
+			}
+			switch major {
+			case cborMap:
+				key, err := UnescapeString(cur)
+				if err != nil {
+					return nil, tokenError(ptr, p, err)
+				}
+				var seen map[string]bool
+				if opts.disallowDuplicateKeys() {
+					seen = make(map[string]bool)
+				}
+				// Like for JSON, the last value wins in case of duplicate keys
+				found := -1
+				var keys []string
+				i = next
+				for n := uint64(0); indef || n < arg; n++ {
+					if indef && i < len(doc) && doc[i] == cborBreak {
+						break
+					}
+					k, next, ok := cborReadText(doc, i)
+					if !ok {
+						if major, _, _, _, ok := cborHead(doc, i); ok && major != cborText {
+							return nil, cborKeyError(ptr[:p-q-1])
+						}
+						return nil, cborError(ptr[:p-q-1], i)
+					}
+					if seen != nil {
+						if seen[k] {
+							return nil, duplicateKeyError(ptr[:p-q] + EscapeString(k))
+						}
+						seen[k] = true
+					}
+					if k == key {
+						found = next
+					} else if found < 0 {
+						keys = append(keys, k)
+					}
+					if i, ok = cborSkip(doc, next); !ok {
+						return nil, cborError(ptr[:p-q]+EscapeString(k), next)
+					}
+				}
+				if found < 0 {
+					return nil, propertyError(ptr[:p], key, keys)
+				}
+				i = found
+			case cborArray:
+				n, err := arrayIndex(cur)
+				if err != nil {
+					return nil, tokenError(ptr, p, err)
+				}
+				if n < 0 {
+					return nil, indexError(ptr[:p], -1)
+				}
+				i = next
+				j := 0
+				for ; j < n; j++ {
+					if indef {
+						if i < len(doc) && doc[i] == cborBreak {
+							break
+						}
+					} else if uint64(j) >= arg {
+						break
+					}
+					if i, ok = cborSkip(doc, i); !ok {
+						return nil, cborError(ptr[:p-q]+strconv.Itoa(j), i)
+					}
+				}
+				if j == n {
+					if !indef && uint64(n) < arg {
+						break
+					}
+					if indef && !(i < len(doc) && doc[i] == cborBreak) {
+						break
+					}
+				}
+				length := j
+				if !indef {
+					length = int(arg)
+				}
+				return nil, indexError(ptr[:p], length)
+			default:
+				v, _, err := cborDecode(doc, i, 0, nil)
+				if err != nil {
+					err.rebase(ptr[:p-q-1])
+					return nil, err
+				}
+				return nil, docError(ptr[:p-q-1], v)
+			}
+
+			p++
+			if p > len(ptr) {
+				break
+			}
+			cur = ptr[p:]
+		}
+	}
+
+	if opts.maxDepth() <= 0 {
+		// The default limit applies only to the recursion of cborDecode
+		depth = 0
+	}
+	v, end, err := cborDecode(doc, i, depth, opts)
+	if err != nil {
+		err.rebase(ptr)
+		return nil, err
+	}
+	if len(ptr) == 0 && end != len(doc) {
+		return nil, cborError("", end)
+	}
+	return v, nil
+}
+
+// cborDecode decodes the data item at doc[i] into the data model.
+func cborDecode(doc []byte, i int, depth int, opts *Options) (v interface{}, next int, err ptrError) {
+	i = cborSkipTags(doc, i)
+	major, arg, indef, next, ok := cborHead(doc, i)
+	if !ok {
+		return nil, i, cborError("", i)
+	}
+	switch major {
+	case cborUint:
+		return float64(arg), next, nil
+	case cborNegInt:
+		return -1 - float64(arg), next, nil
+	case cborBytes:
+		if !indef {
+			if arg > uint64(len(doc)-next) {
+				return nil, i, cborError("", i)
+			}
+			return append([]byte{}, doc[next:next+int(arg)]...), next + int(arg), nil
+		}
+		b := []byte{}
+		for i = next; ; {
+			if i >= len(doc) {
+				return nil, i, cborError("", i)
+			}
+			if doc[i] == cborBreak {
+				return b, i + 1, nil
+			}
+			m, n, indef, next, ok := cborHead(doc, i)
+			if !ok || m != cborBytes || indef || n > uint64(len(doc)-next) {
+				return nil, i, cborError("", i)
+			}
+			b = append(b, doc[next:next+int(n)]...)
+			i = next + int(n)
+		}
+	case cborText:
+		s, next, ok := cborReadText(doc, i)
+		if !ok {
+			return nil, i, cborError("", i)
+		}
+		return s, next, nil
+	case cborArray, cborMap:
+		depth++
+		max := opts.maxDepth()
+		if max <= 0 {
+			max = defaultMaxDepth
+		}
+		if depth > max {
+			return nil, i, limitError("")
+		}
+	case cborSimple:
+		switch doc[i] & 0x1f {
+		case 20:
+			return false, next, nil
+		case 21:
+			return true, next, nil
+		case 22, 23: // null, undefined
+			return nil, next, nil
+		case 25:
+			return float16(uint16(arg)), next, nil
+		case 26:
+			return float64(math.Float32frombits(uint32(arg))), next, nil
+		case 27:
+			return math.Float64frombits(arg), next, nil
+		}
+		return nil, i, &DocumentError{Err: fmt.Errorf("unsupported CBOR simple value %d at offset %d", arg, i)}
+	}
+
+	i = next
+	if major == cborArray {
+		arr := []interface{}{}
+		for n := uint64(0); indef || n < arg; n++ {
+			if indef && i < len(doc) && doc[i] == cborBreak {
+				i++
+				break
+			}
+			var v interface{}
+			if v, i, err = cborDecode(doc, i, depth, opts); err != nil {
+				err.rebase("/" + strconv.Itoa(len(arr)))
+				return nil, i, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, i, nil
+	}
+
+	obj := make(map[string]interface{})
+	for n := uint64(0); indef || n < arg; n++ {
+		if indef && i < len(doc) && doc[i] == cborBreak {
+			i++
+			break
+		}
+		k, next, ok := cborReadText(doc, i)
+		if !ok {
+			if major, _, _, _, ok := cborHead(doc, i); ok && major != cborText {
+				return nil, i, cborKeyError("")
+			}
+			return nil, i, cborError("", i)
+		}
+		if opts.disallowDuplicateKeys() {
+			if _, dup := obj[k]; dup {
+				return nil, i, duplicateKeyError("/" + EscapeString(k))
+			}
+		}
+		var v interface{}
+		if v, i, err = cborDecode(doc, next, depth, opts); err != nil {
+			err.rebase("/" + EscapeString(k))
+			return nil, i, err
+		}
+		obj[k] = v
+	}
+	return obj, i, nil
+}
+
+// float16 converts a IEEE 754 half-precision number.
+func float16(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
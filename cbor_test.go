@@ -0,0 +1,242 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+// cbor decodes a CBOR document written in hex, with spaces and comments
+// (from '#' to the end of line) ignored.
+func cbor(s string) jsonptr.CBOR {
+	var h strings.Builder
+	for _, line := range strings.Split(s, "\n") {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		h.WriteString(strings.Replace(line, " ", "", -1))
+	}
+	b, err := hex.DecodeString(strings.Replace(h.String(), "\t", "", -1))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+var cborDoc = cbor(`
+	a7                # map(7)
+	61 61             # "a"
+	84                # array(4)
+	01 21             # 1, -2
+	61 78             # "x"
+	a1 61 62 f5       # {"b": true}
+	61 63 f6          # "c": null
+	61 64 42 01 02    # "d": h'0102'
+	61 65 f9 3e 00    # "e": 1.5 (half-precision)
+	61 66 1a 00 01 86 a0 # "f": 100000
+	61 67             # "g"
+	9f                # array(*)
+	01                # 1
+	9f ff             # []
+	7f 61 61 61 62 ff # (_ "a", "b")
+	ff
+	61 68             # "h"
+	c1 1a 51 4b 67 b0 # 1(1363896240)
+`)
+
+func TestGetCBOR(t *testing.T) {
+	for _, test := range []struct {
+		ptr      string
+		expected interface{}
+	}{
+		{"/a", []interface{}{float64(1), float64(-2), "x", map[string]interface{}{"b": true}}},
+		{"/a/0", float64(1)},
+		{"/a/1", float64(-2)},
+		{"/a/2", "x"},
+		{"/a/3/b", true},
+		{"/c", nil},
+		{"/d", []byte{1, 2}},
+		{"/e", 1.5},
+		{"/f", float64(100000)},
+		{"/g", []interface{}{float64(1), []interface{}{}, "ab"}},
+		{"/g/1", []interface{}{}},
+		{"/g/2", "ab"},
+		{"/h", float64(1363896240)},
+	} {
+		got, err := jsonptr.Get(cborDoc, test.ptr)
+		if err != nil {
+			t.Errorf("%q: %v", test.ptr, err)
+		} else if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%q: got %#v", test.ptr, got)
+		}
+	}
+
+	root, err := jsonptr.Get(cborDoc, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := root.(map[string]interface{}); !ok || len(m) != 7 {
+		t.Errorf("got %#v", root)
+	}
+
+	got, err := jsonptr.MustParse("/payload/a/3/b").In(map[string]interface{}{"payload": cborDoc})
+	if err != nil || got != true {
+		t.Errorf("In: got %#v, %v", got, err)
+	}
+}
+
+func TestGetCBORErrors(t *testing.T) {
+	for _, test := range []struct {
+		doc jsonptr.CBOR
+		ptr string
+		err error
+		at  string
+	}{
+		{cborDoc, "/x", jsonptr.ErrProperty, "/x"},
+		{cborDoc, "/a/4", jsonptr.ErrIndex, "/a/4"},
+		{cborDoc, "/a/-", jsonptr.ErrIndex, "/a/-"},
+		{cborDoc, "/g/3", jsonptr.ErrIndex, "/g/3"},
+		{cborDoc, "/g/0/x", jsonptr.ErrNotContainer, "/g/0"},
+		{cborDoc, "/c/x", jsonptr.ErrNotContainer, "/c"},
+		{cborDoc, "/a/x", jsonptr.ErrSyntax, "/a/x"},
+		{cbor("a2 01 02 61 61 03"), "/a", jsonptr.ErrMapKey, ""},
+		{cbor("a2 01 02 61 61 03"), "", jsonptr.ErrMapKey, ""},
+		{cbor("a1 61 61 a1 01 02"), "", jsonptr.ErrMapKey, "/a"},
+		{cbor("a1 61 61 82 01 a1 f5 02"), "/a", jsonptr.ErrMapKey, "/a/1"},
+		{cbor("a1 61 61 a1 01 02"), "/a/b", jsonptr.ErrMapKey, "/a"},
+	} {
+		_, err := jsonptr.Get(test.doc, test.ptr)
+		if err == nil {
+			t.Errorf("%x %q: error expected", test.doc, test.ptr)
+			continue
+		}
+		t.Logf("%x %q: %v", test.doc, test.ptr, err)
+		var e error
+		var at string
+		switch err := err.(type) {
+		case *jsonptr.PtrError:
+			e, at = err.Err, err.Ptr
+		case *jsonptr.DocumentError:
+			e, at = err.Err, err.Ptr
+		case *jsonptr.BadPointerError:
+			e, at = err.Err, err.BadPtr
+		}
+		if e != test.err || at != test.at {
+			t.Errorf("%x %q: got %v at %q, expected %v at %q", test.doc, test.ptr, e, at, test.err, test.at)
+		}
+	}
+
+	// Malformed documents
+	for _, test := range []struct {
+		doc string
+		ptr string
+	}{
+		{"a1 61 61", "/a"},
+		{"a1 61", "/a"},
+		{"a1 61 61 82 01", "/a"},
+		{"a1 61 61 82 01", "/b"},
+		{"9f 01", "/1"},
+		{"01 02", ""},
+		{"1c", ""},
+		{"7f 61 61 01 ff", ""},
+		{"7f 7f 61 61 ff ff", ""}, // indefinite chunk
+		{"61 ff", ""},             // invalid UTF-8
+		{"7f 61 c3 61 a9 ff", ""}, // UTF-8 split across chunks
+		{"a1 62 c3 28 01", "/x"},  // invalid UTF-8 key
+		{"a1 7f 7f 61 61 ff ff 01", "/a"},
+		{"", ""},
+		{"", "/a"},
+	} {
+		_, err := jsonptr.Get(cbor(test.doc), test.ptr)
+		if _, ok := err.(*jsonptr.DocumentError); !ok {
+			t.Errorf("%s %q: DocumentError expected, got %v", test.doc, test.ptr, err)
+		} else {
+			t.Logf("%s %q: %v", test.doc, test.ptr, err)
+		}
+	}
+}
+
+func TestGetCBOROptions(t *testing.T) {
+	doc := cbor("a2 61 61 01 61 61 02") // {"a": 1, "a": 2}
+	if got, err := jsonptr.Get(doc, "/a"); err != nil || got != float64(2) {
+		t.Errorf("got %v, %v", got, err)
+	}
+	strict := jsonptr.Options{DisallowDuplicateKeys: true}
+	for _, ptr := range []string{"", "/a"} {
+		_, err := strict.Get(doc, ptr)
+		if e, ok := err.(*jsonptr.PtrError); !ok || e.Err != jsonptr.ErrDuplicateKey || e.Ptr != "/a" {
+			t.Errorf("%q: got %#v", ptr, err)
+		}
+	}
+	limits := jsonptr.Options{MaxDepth: 2}
+	for _, ptr := range []string{"/f", "/a/0", "/g/0"} {
+		if _, err := limits.Get(cborDoc, ptr); err != nil {
+			t.Errorf("%q: %v", ptr, err)
+		}
+	}
+	for _, ptr := range []string{"", "/g", "/g/1", "/a/3", "/a/3/b"} {
+		if _, err := limits.Get(cborDoc, ptr); err == nil {
+			t.Errorf("%q: limit error expected", ptr)
+		}
+	}
+}
+
+func TestGetCBORDeep(t *testing.T) {
+	// Nested arrays, deep enough to overflow the stack if decoded
+	// recursively without limit
+	doc := append(bytes.Repeat([]byte{0x81}, 20e6), 0x00)
+	_, err := jsonptr.Get(jsonptr.CBOR(doc), "/0")
+	if e, ok := err.(*jsonptr.DocumentError); !ok || e.Err != jsonptr.ErrLimit {
+		t.Errorf("got %#v", err)
+	}
+
+	// The default limit doesn't apply to navigation
+	if v, err := jsonptr.Get(jsonptr.CBOR(doc), strings.Repeat("/0", 20e6)); err != nil || v != float64(0) {
+		t.Errorf("got %v, %v", v, err)
+	}
+	if v, err := jsonptr.Get(jsonptr.CBOR(doc), strings.Repeat("/0", 20e6-2)); err != nil || !reflect.DeepEqual(v, []interface{}{[]interface{}{0.0}}) {
+		t.Errorf("got %v, %v", v, err)
+	}
+	// Decoding below the limit
+	if _, err := jsonptr.Get(jsonptr.CBOR(doc[20e6-10000+1:]), ""); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetCBORNestedText(t *testing.T) {
+	// Indefinite length text strings nested as chunks
+	doc := append(bytes.Repeat([]byte{0x7f}, 20e6), 0x60)
+	_, err := jsonptr.Get(jsonptr.CBOR(doc), "")
+	if _, ok := err.(*jsonptr.DocumentError); !ok {
+		t.Errorf("got %#v", err)
+	}
+}
+
+func ExampleCBOR() {
+	// {"device": {"id": "sensor-1", "readings": [21.5, 22]}}
+	payload := jsonptr.CBOR{
+		0xa1, 0x66, 'd', 'e', 'v', 'i', 'c', 'e',
+		0xa2, 0x62, 'i', 'd', 0x68, 's', 'e', 'n', 's', 'o', 'r', '-', '1',
+		0x68, 'r', 'e', 'a', 'd', 'i', 'n', 'g', 's',
+		0x82, 0xf9, 0x4d, 0x60, 0x16,
+	}
+	id, _ := jsonptr.Get(payload, "/device/id")
+	fmt.Println(id)
+	r, _ := jsonptr.Get(payload, "/device/readings/0")
+	fmt.Println(r)
+	_, err := jsonptr.Get(payload, "/device/name")
+	fmt.Println(err)
+	// Output:
+	// sensor-1
+	// 21.5
+	// "/device/name": property not found
+}
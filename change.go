@@ -0,0 +1,249 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// change describes a modification made by setChange or removeChange, with
+// what is needed to revert it.
+type change struct {
+	// ptr is the location of the change, with "-" resolved to an index
+	ptr string
+	// op is "add", "replace" or "remove", as in JSON Patch (RFC 6902)
+	op    string
+	old   interface{} // for "replace" and "remove"
+	value interface{} // for "add" and "replace"
+	// shift is true if elements following ptr in an array have moved
+	shift bool
+
+	undo undo
+}
+
+// undo reverts a change.
+type undo struct {
+	kind  undoKind
+	ptr   string
+	value interface{}
+	index int
+}
+
+type undoKind uint8
+
+const (
+	undoSet      undoKind = iota // set value at ptr
+	undoRemove                   // remove ptr
+	undoInsert                   // insert value at index in the array at ptr
+	undoTruncate                 // truncate the array at ptr to index elements
+	undoInsertAt                 // insert member ptr of an *Object at position index
+)
+
+// setChange is like set, and describes the change.
+func setChange(doc *interface{}, ptr string, value interface{}, opts *Options) (*change, error) {
+	c := &change{ptr: ptr, op: "replace", value: value}
+	if len(ptr) == 0 {
+		c.old = *doc
+		c.undo = undo{kind: undoSet, value: *doc}
+		*doc = value
+		return c, nil
+	}
+	p := strings.LastIndexByte(ptr, '/')
+	if p < 0 {
+		return nil, syntaxError(ptr)
+	}
+	parentPtr := ptr[:p]
+	parent, err := get(*doc, parentPtr, opts)
+	if err != nil {
+		return nil, err
+	}
+	// Errors are reported by set
+	switch parent := parent.(type) {
+	case map[string]interface{}:
+		key, _ := UnescapeString(ptr[p+1:])
+		switch old, exists := parent[key]; {
+		case parent == nil:
+			c.op = "add"
+			c.undo = undo{kind: undoSet, ptr: parentPtr, value: parent}
+		case exists:
+			c.old = old
+			c.undo = undo{kind: undoSet, ptr: ptr, value: old}
+		default:
+			c.op = "add"
+			c.undo = undo{kind: undoRemove, ptr: ptr}
+		}
+	case *Object:
+		key, _ := UnescapeString(ptr[p+1:])
+		switch old, exists := parent.Get(key); {
+		case parent == nil:
+			c.op = "add"
+			c.undo = undo{kind: undoSet, ptr: parentPtr, value: parent}
+		case exists:
+			c.old = old
+			c.undo = undo{kind: undoSet, ptr: ptr, value: old}
+		default:
+			c.op = "add"
+			c.undo = undo{kind: undoRemove, ptr: ptr}
+		}
+	case []interface{}:
+		n, _ := arrayIndex(ptr[p+1:])
+		if n == -1 {
+			n = len(parent)
+			c.ptr = parentPtr + "/" + strconv.Itoa(n)
+		}
+		if n >= 0 && n < len(parent) {
+			c.old = parent[n]
+			c.undo = undo{kind: undoSet, ptr: ptr, value: parent[n]}
+		} else {
+			c.op = "add"
+			c.undo = undo{kind: undoTruncate, ptr: parentPtr, index: len(parent)}
+		}
+	default:
+		switch typedKind(parent) {
+		case typedArray:
+			n, _ := arrayIndex(ptr[p+1:])
+			if n == -1 {
+				n = typedLen(parent)
+				c.ptr = parentPtr + "/" + strconv.Itoa(n)
+			}
+			if old, ok := getTypedIndex(parent, n); ok {
+				c.old = old
+			} else {
+				c.op = "add"
+			}
+			c.undo = typedUndo(parentPtr, parent)
+		case typedObject:
+			key, _ := UnescapeString(ptr[p+1:])
+			if old, ok := getTypedKey(parent, key); ok {
+				c.old = old
+			} else {
+				c.op = "add"
+			}
+			c.undo = typedUndo(parentPtr, parent)
+		}
+	}
+	if err := set(doc, ptr, value, opts); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// insertChange is like setChange, but inserts into arrays, as the "add"
+// operation of JSON Patch: following elements are shifted, and an index
+// beyond the end of the array is an error.
+func insertChange(doc *interface{}, ptr string, value interface{}) (*change, error) {
+	p := strings.LastIndexByte(ptr, '/')
+	if p < 0 {
+		return setChange(doc, ptr, value, nil)
+	}
+	parentPtr := ptr[:p]
+	parent, err := get(*doc, parentPtr, nil)
+	arr, ok := parent.([]interface{})
+	if err != nil || !ok {
+		// Errors are reported by setChange
+		return setChange(doc, ptr, value, nil)
+	}
+	n, err := arrayIndex(ptr[p+1:])
+	switch {
+	case err != nil:
+		return nil, tokenError(ptr, len(ptr), err)
+	case n == -1:
+		n = len(arr)
+	case n > len(arr):
+		return nil, indexError(ptr, len(arr))
+	}
+	ptr = parentPtr + "/" + strconv.Itoa(n)
+	a := make([]interface{}, 0, len(arr)+1)
+	a = append(append(append(a, arr[:n]...), value), arr[n:]...)
+	// No error can happen as the parent exists
+	_ = set(doc, parentPtr, a, nil)
+	return &change{
+		ptr:   ptr,
+		op:    "add",
+		value: value,
+		shift: n < len(arr),
+		undo:  undo{kind: undoRemove, ptr: ptr},
+	}, nil
+}
+
+// removeChange is like remove, and describes the change.
+func removeChange(doc *interface{}, ptr string, opts *Options) (*change, error) {
+	c := &change{ptr: ptr, op: "remove"}
+	if p := strings.LastIndexByte(ptr, '/'); p >= 0 {
+		parentPtr := ptr[:p]
+		// Errors are reported by remove
+		switch parent, _ := get(*doc, parentPtr, opts); parent := parent.(type) {
+		case map[string]interface{}:
+			key, _ := UnescapeString(ptr[p+1:])
+			c.undo = undo{kind: undoSet, ptr: ptr, value: parent[key]}
+		case *Object:
+			key, _ := UnescapeString(ptr[p+1:])
+			v, _ := parent.Get(key)
+			c.undo = undo{kind: undoInsertAt, ptr: ptr, value: v}
+			for i, k := range parent.keys {
+				if k == key {
+					c.undo.index = i
+					break
+				}
+			}
+		case []interface{}:
+			n, _ := arrayIndex(ptr[p+1:])
+			if n >= 0 && n < len(parent) {
+				c.shift = n < len(parent)-1
+				c.undo = undo{kind: undoInsert, ptr: parentPtr, value: parent[n], index: n}
+			}
+		default:
+			switch typedKind(parent) {
+			case typedArray:
+				n, _ := arrayIndex(ptr[p+1:])
+				c.shift = n >= 0 && n < typedLen(parent)-1
+				c.undo = typedUndo(parentPtr, parent)
+			case typedObject:
+				c.undo = typedUndo(parentPtr, parent)
+			}
+		}
+	}
+	old, err := remove(doc, ptr, opts)
+	if err != nil {
+		return nil, err
+	}
+	c.old = old
+	return c, nil
+}
+
+// typedUndo returns the undo record of a change of the typed container
+// parent at ptr, which is modified in place: the container is restored
+// from a copy.
+func typedUndo(ptr string, parent interface{}) undo {
+	v, _ := copyTyped(parent)
+	return undo{kind: undoSet, ptr: ptr, value: v}
+}
+
+// revert applies the undo record of the change to doc, which must be in the
+// state following the change.
+func (c *change) revert(doc *interface{}) {
+	u := &c.undo
+	switch u.kind {
+	case undoSet:
+		_ = set(doc, u.ptr, u.value, nil)
+	case undoRemove:
+		_, _ = remove(doc, u.ptr, nil)
+	case undoInsert:
+		v, _ := get(*doc, u.ptr, nil)
+		arr := v.([]interface{})
+		a := make([]interface{}, 0, len(arr)+1)
+		a = append(append(append(a, arr[:u.index]...), u.value), arr[u.index:]...)
+		_ = set(doc, u.ptr, a, nil)
+	case undoTruncate:
+		v, _ := get(*doc, u.ptr, nil)
+		_ = set(doc, u.ptr, v.([]interface{})[:u.index], nil)
+	case undoInsertAt:
+		p := strings.LastIndexByte(u.ptr, '/')
+		v, _ := get(*doc, u.ptr[:p], nil)
+		key, _ := UnescapeString(u.ptr[p+1:])
+		v.(*Object).insert(u.index, key, u.value)
+	}
+}
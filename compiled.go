@@ -0,0 +1,237 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Compiled is a JSON Pointer prepared for repeated evaluation: reference
+// tokens are unescaped, and parsed as array indexes, once.
+//
+// Get doesn't allocate memory on a deserialized document, except to report
+// an error.
+type Compiled struct {
+	src    string
+	tokens []compiledToken
+}
+
+type compiledToken struct {
+	key   string // unescaped
+	index int    // array index, -1 for "-", -2 if not an array index
+	end   int    // offset of the end of the token in src
+}
+
+// Compile parses a JSON Pointer for repeated evaluation.
+//
+// In case of error a *BadPointerError is returned.
+func Compile(ptr string) (*Compiled, error) {
+	if err := checkSyntax(ptr); err != nil {
+		return nil, err
+	}
+	c := &Compiled{src: ptr}
+	if ptr == "" {
+		return c, nil
+	}
+	c.tokens = make([]compiledToken, 0, strings.Count(ptr, "/"))
+	p := 1
+	for _, tok := range strings.Split(ptr[1:], "/") {
+		p += len(tok)
+		// No error can happen as the syntax has been checked
+		key, _ := UnescapeString(tok)
+		c.tokens = append(c.tokens, newCompiledToken(key, p))
+		p++
+	}
+	return c, nil
+}
+
+// MustCompile wraps Compile and panics in case of error.
+func MustCompile(ptr string) *Compiled {
+	c, err := Compile(ptr)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Compile returns ptr prepared for repeated evaluation.
+func (ptr Pointer) Compile() *Compiled {
+	var src []byte
+	tokens := make([]compiledToken, len(ptr))
+	for i, key := range ptr {
+		src = AppendEscape(append(src, '/'), key)
+		tokens[i] = newCompiledToken(key, len(src))
+	}
+	return &Compiled{src: string(src), tokens: tokens}
+}
+
+func newCompiledToken(key string, end int) compiledToken {
+	n, err := arrayIndex(key)
+	if err != nil {
+		n = -2
+	}
+	return compiledToken{key: key, index: n, end: end}
+}
+
+// String returns the pointer in its serialized form.
+func (c *Compiled) String() string {
+	return c.src
+}
+
+// Get is like the [Get] function.
+func (c *Compiled) Get(doc interface{}) (interface{}, error) {
+	start := 0
+	for i := range c.tokens {
+		tok := &c.tokens[i]
+		switch here := doc.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if doc, ok = here[tok.key]; !ok {
+				return nil, propertyError(c.src[:tok.end], tok.key, mapKeys(here))
+			}
+		case *Object:
+			var ok bool
+			if doc, ok = here.Get(tok.key); !ok {
+				return nil, propertyError(c.src[:tok.end], tok.key, here.keys)
+			}
+		case []interface{}:
+			if tok.index == -2 {
+				return nil, tokenError(c.src, tok.end, ErrSyntax)
+			}
+			if tok.index < 0 || tok.index >= len(here) {
+				return nil, indexError(c.src[:tok.end], len(here))
+			}
+			doc = here[tok.index]
+		case JSONDecoder:
+			v, err := getJSON(here, c.src[start:], nil)
+			if err != nil {
+				err.rebase(c.src[:start])
+			}
+			return v, err
+		case json.RawMessage:
+			v, err := getRaw(here, c.src[start:], nil)
+			if err != nil {
+				err.rebase(c.src[:start])
+			}
+			return v, err
+		case JSONC:
+			v, err := getRaw(here.clean(), c.src[start:], nil)
+			if err != nil {
+				err.rebase(c.src[:start])
+			}
+			return v, err
+		case CBOR:
+			v, err := getCBOR(here, c.src[start:], nil)
+			if err != nil {
+				err.rebase(c.src[:start])
+			}
+			return v, err
+		default:
+			var ok bool
+			switch typedKind(here) {
+			case typedObject:
+				if doc, ok = getTypedKey(here, tok.key); !ok {
+					return nil, propertyError(c.src[:tok.end], tok.key, typedKeys(here))
+				}
+			case typedArray:
+				if tok.index == -2 {
+					return nil, tokenError(c.src, tok.end, ErrSyntax)
+				}
+				if doc, ok = getTypedIndex(here, tok.index); !ok {
+					return nil, indexError(c.src[:tok.end], typedLen(here))
+				}
+			default:
+				return nil, docError(c.src[:start], doc)
+			}
+		}
+		start = tok.end
+	}
+
+	doc, err := getLeaf(doc, nil)
+	if err != nil {
+		err.rebase(c.src)
+	}
+	return doc, err
+}
+
+// parent returns the container of the pointed value, if it can be reached
+// through the deserialized data model. Otherwise ok is false, and the
+// generic implementation (which reports errors) has to be used.
+func (c *Compiled) parent(doc interface{}) (parent interface{}, ok bool) {
+	for _, tok := range c.tokens[:len(c.tokens)-1] {
+		switch here := doc.(type) {
+		case map[string]interface{}:
+			if doc, ok = here[tok.key]; !ok {
+				return nil, false
+			}
+		case *Object:
+			if doc, ok = here.Get(tok.key); !ok {
+				return nil, false
+			}
+		case []interface{}:
+			if tok.index < 0 || tok.index >= len(here) {
+				return nil, false
+			}
+			doc = here[tok.index]
+		default:
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+// Set is like the [Set] function.
+func (c *Compiled) Set(doc *interface{}, value interface{}) error {
+	if len(c.tokens) == 0 {
+		*doc = value
+		return nil
+	}
+	if parent, ok := c.parent(*doc); ok {
+		tok := &c.tokens[len(c.tokens)-1]
+		switch parent := parent.(type) {
+		case map[string]interface{}:
+			if parent != nil {
+				parent[tok.key] = value
+				return nil
+			}
+		case *Object:
+			if parent != nil {
+				parent.Set(tok.key, value)
+				return nil
+			}
+		case []interface{}:
+			if tok.index >= 0 && tok.index < len(parent) {
+				parent[tok.index] = value
+				return nil
+			}
+		}
+	}
+	// Creation of a container, growth of an array, or error
+	return set(doc, c.src, value, nil)
+}
+
+// Delete is like the [Delete] function.
+func (c *Compiled) Delete(doc *interface{}) (interface{}, error) {
+	if len(c.tokens) == 0 {
+		return remove(doc, c.src, nil)
+	}
+	if parent, ok := c.parent(*doc); ok {
+		tok := &c.tokens[len(c.tokens)-1]
+		switch parent := parent.(type) {
+		case map[string]interface{}:
+			if v, found := parent[tok.key]; found {
+				delete(parent, tok.key)
+				return v, nil
+			}
+		case *Object:
+			if v, found := parent.Delete(tok.key); found {
+				return v, nil
+			}
+		}
+	}
+	// Arrays (the slice must be replaced in its parent), or error
+	return remove(doc, c.src, nil)
+}
@@ -0,0 +1,172 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+const compiledDoc = `{"a":{"b":[0,{"c":"x","d~/e":true}],"":null},"f":[[1,2],[]],"g":"s"}`
+
+var compiledPointers = []string{
+	``, `/a`, `/a/b`, `/a/b/1/c`, `/a/b/1/d~0~1e`, `/a/`, `/f/0/1`,
+	// Errors
+	`/x`, `/a/x`, `/a/b/2`, `/a/b/-`, `/a/b/01`, `/a/b/x`, `/f/1/0`, `/g/h`, `/a/b/0/x`,
+}
+
+func TestCompiledGet(t *testing.T) {
+	var generic interface{}
+	_ = json.Unmarshal([]byte(compiledDoc), &generic)
+	ordered, _ := jsonptr.UnmarshalOrdered([]byte(compiledDoc))
+
+	for _, ptr := range compiledPointers {
+		c := jsonptr.MustCompile(ptr)
+		if c.String() != ptr {
+			t.Errorf("String: got %q, expected %q", c, ptr)
+		}
+		p := jsonptr.MustParse(ptr)
+		if pc := p.Compile(); pc.String() != ptr {
+			t.Errorf("Pointer.Compile: got %q, expected %q", pc, ptr)
+		}
+		for _, doc := range []interface{}{
+			generic,
+			ordered,
+			json.RawMessage(compiledDoc),
+			map[string]interface{}{"a": map[string]interface{}{"b": json.RawMessage(`[0,{"c":"x","d~/e":true}]`), "": nil}, "f": []interface{}{[]interface{}{1, 2}}},
+		} {
+			expected, expectedErr := jsonptr.Get(doc, ptr)
+			for _, c := range []*jsonptr.Compiled{c, p.Compile()} {
+				got, err := c.Get(doc)
+				if !reflect.DeepEqual(got, expected) || !reflect.DeepEqual(err, expectedErr) {
+					t.Errorf("%q in %T: got %v, %#v; expected %v, %#v", ptr, doc, got, err, expected, expectedErr)
+				}
+			}
+		}
+	}
+
+	if _, err := jsonptr.Compile("a"); err == nil {
+		t.Error("error expected")
+	}
+}
+
+func TestCompiledSetDelete(t *testing.T) {
+	for _, ptr := range append(compiledPointers, `/a/b/-`, `/a/b/5`, `/a/new`, `/f/1/0`) {
+		for _, ordered := range []bool{false, true} {
+			docs := [2]interface{}{}
+			for i := range docs {
+				if ordered {
+					docs[i], _ = jsonptr.UnmarshalOrdered([]byte(compiledDoc))
+				} else {
+					_ = json.Unmarshal([]byte(compiledDoc), &docs[i])
+				}
+			}
+			c := jsonptr.MustCompile(ptr)
+
+			err := c.Set(&docs[0], "v")
+			expectedErr := jsonptr.Set(&docs[1], ptr, "v")
+			if !reflect.DeepEqual(err, expectedErr) || !reflect.DeepEqual(docs[0], docs[1]) {
+				t.Errorf("Set %q: got %v, expected %v", ptr, err, expectedErr)
+			}
+
+			v, err := c.Delete(&docs[0])
+			expectedV, expectedErr := jsonptr.Delete(&docs[1], ptr)
+			if !reflect.DeepEqual(v, expectedV) || !reflect.DeepEqual(err, expectedErr) || !reflect.DeepEqual(docs[0], docs[1]) {
+				t.Errorf("Delete %q: got %v, %v; expected %v, %v", ptr, v, err, expectedV, expectedErr)
+			}
+		}
+	}
+}
+
+func TestCompiledAllocs(t *testing.T) {
+	var generic interface{}
+	_ = json.Unmarshal([]byte(compiledDoc), &generic)
+	ordered, _ := jsonptr.UnmarshalOrdered([]byte(compiledDoc))
+	c := jsonptr.MustCompile("/a/b/1/d~0~1e")
+	for _, doc := range []interface{}{generic, ordered} {
+		allocs := testing.AllocsPerRun(100, func() {
+			if v, err := c.Get(doc); err != nil || v != true {
+				panic("unexpected result")
+			}
+			if err := c.Set(&doc, true); err != nil {
+				panic(err)
+			}
+		})
+		if allocs != 0 {
+			t.Errorf("%T: got %v allocations", doc, allocs)
+		}
+	}
+}
+
+func BenchmarkCompiled(b *testing.B) {
+	var generic interface{}
+	_ = json.Unmarshal([]byte(compiledDoc), &generic)
+	ordered, _ := jsonptr.UnmarshalOrdered([]byte(compiledDoc))
+	for _, ptr := range []string{"/g", "/a/b/1/c", "/a/b/1/d~0~1e"} {
+		c := jsonptr.MustCompile(ptr)
+		p := jsonptr.MustParse(ptr)
+		for _, doc := range []struct {
+			name string
+			doc  interface{}
+		}{{"map", generic}, {"Object", ordered}} {
+			name := strings.Replace(ptr, "/", "_", -1) + "/" + doc.name
+			b.Run(name+"/Compiled.Get", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := c.Get(doc.doc); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run(name+"/Get", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := jsonptr.Get(doc.doc, ptr); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run(name+"/Pointer.In", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := p.In(doc.doc); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run(name+"/Compiled.Set", func(b *testing.B) {
+				b.ReportAllocs()
+				d := doc.doc
+				for i := 0; i < b.N; i++ {
+					if err := c.Set(&d, true); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func ExampleCompiled() {
+	price := jsonptr.MustCompile("/item/price")
+	for _, doc := range []string{
+		`{"item":{"name":"a","price":10}}`,
+		`{"item":{"name":"b","price":12.5}}`,
+		`{"item":{"name":"c"}}`,
+	} {
+		var v interface{}
+		_ = json.Unmarshal([]byte(doc), &v)
+		fmt.Println(price.Get(v))
+	}
+	// Output:
+	// 10 <nil>
+	// 12.5 <nil>
+	// <nil> "/item/price": property not found
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseDotPath parses a path in the dot/bracket notation of JavaScript, used
+// by lodash, gjson or jq, such as spec.containers[0].image.
+//
+// Member names are separated by dots. A name which is not a plain word (see
+// [Pointer.DotPath]) is quoted in brackets: ["app.kubernetes.io/name"] or
+// ['a.b']. Quoted names use the escapes of Go (and JSON) strings.
+// Array indexes are in brackets: [0]. As JSON Pointers don't distinguish
+// member names from array indexes, a name made only of digits may also be
+// written after a dot: items.0.name is the same as items[0].name.
+//
+// A leading dot (jq style) is allowed. The empty path and "." are the root.
+//
+// In case of error a *BadPointerError is returned, with Input set to path.
+func ParseDotPath(path string) (Pointer, error) {
+	if path == "." {
+		return nil, nil
+	}
+	var ptr Pointer
+	i := 0
+	if path != "" && path[0] == '.' {
+		i = 1
+	}
+	for i < len(path) {
+		if path[i] == '[' {
+			tok, end, ok := parseDotPathBracket(path, i)
+			if !ok {
+				return nil, dotPathError(path, end, len(ptr))
+			}
+			ptr = append(ptr, tok)
+			i = end
+			continue
+		}
+		// A name follows a dot, except at the start
+		if len(ptr) > 0 {
+			if path[i] != '.' {
+				return nil, dotPathError(path, i, len(ptr))
+			}
+			i++
+		}
+		start := i
+		for i < len(path) && path[i] != '.' && path[i] != '[' {
+			if !isDotPathNameByte(path[i]) {
+				return nil, dotPathError(path, i, len(ptr))
+			}
+			i++
+		}
+		if i == start {
+			return nil, dotPathError(path, i, len(ptr))
+		}
+		ptr = append(ptr, path[start:i])
+	}
+	return ptr, nil
+}
+
+// parseDotPathBracket parses the bracketed token starting at path[i] ('[').
+// end is the offset following the ']', or the offset of the error.
+func parseDotPathBracket(path string, i int) (tok string, end int, ok bool) {
+	i++
+	if i >= len(path) {
+		return "", i, false
+	}
+	switch q := path[i]; q {
+	case '"', '\'':
+		j := i + 1
+		for ; j < len(path) && path[j] != q; j++ {
+			if path[j] == '\\' {
+				j++
+			}
+		}
+		if j >= len(path) {
+			return "", len(path), false
+		}
+		s := path[i : j+1]
+		if q == '\'' {
+			s = singleToDoubleQuoted(s)
+		}
+		var err error
+		if tok, err = strconv.Unquote(s); err != nil {
+			return "", i, false
+		}
+		i = j + 1
+	default:
+		j := strings.IndexByte(path[i:], ']')
+		if j < 0 {
+			return "", len(path), false
+		}
+		tok = path[i : i+j]
+		if tok != "-" && !isDigits(tok) {
+			return "", i, false
+		}
+		i += j
+	}
+	if i >= len(path) || path[i] != ']' {
+		return "", i, false
+	}
+	return tok, i + 1, true
+}
+
+// singleToDoubleQuoted converts a single-quoted string to a double-quoted
+// one for strconv.Unquote.
+func singleToDoubleQuoted(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 1; i < len(s)-1; i++ {
+		switch c := s[i]; c {
+		case '\\':
+			if s[i+1] != '\'' {
+				b.WriteByte(c)
+			}
+			i++
+			b.WriteByte(s[i])
+		case '"':
+			b.WriteString(`\"`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func dotPathError(path string, offset int, token int) *BadPointerError {
+	end := offset + 1
+	if end > len(path) {
+		end = len(path)
+	}
+	return &BadPointerError{BadPtr: path[:end], Err: ErrSyntax, Input: path, Offset: offset, Token: token}
+}
+
+// isDotPathNameByte reports if c may appear in a name written after a dot.
+func isDotPathNameByte(c byte) bool {
+	switch c {
+	case '.', '[', ']', '"', '\'', '\\', ' ', '\t', '\n', '\r':
+		return false
+	}
+	return c >= 0x20 && c != 0x7f
+}
+
+func isDigits(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// DotPath returns the pointer in the dot/bracket notation parsed by
+// [ParseDotPath], such as spec.containers[0].image.
+//
+// Array indexes (and "-") are written in brackets. Names are written after
+// a dot if they are plain words: not empty, valid UTF-8, not starting with
+// a digit, and without dots, brackets, quotes, backslashes, spaces or control
+// characters. Other names are quoted in brackets, with the escapes of
+// [strconv.Quote].
+//
+// ParseDotPath(ptr.DotPath()) always returns the same pointer as ptr.
+func (ptr Pointer) DotPath() string {
+	var b []byte
+	for _, tok := range ptr {
+		switch {
+		case tok == "-" || isDigits(tok) && (tok[0] != '0' || len(tok) == 1):
+			b = append(append(append(b, '['), tok...), ']')
+		case isDotPathName(tok):
+			if len(b) > 0 {
+				b = append(b, '.')
+			}
+			b = append(b, tok...)
+		default:
+			b = append(strconv.AppendQuote(append(b, '['), tok), ']')
+		}
+	}
+	return string(b)
+}
+
+// isDotPathName reports if tok can be written as is after a dot.
+func isDotPathName(tok string) bool {
+	if len(tok) == 0 || (tok[0] >= '0' && tok[0] <= '9') || !utf8.ValidString(tok) {
+		return false
+	}
+	for i := 0; i < len(tok); i++ {
+		if !isDotPathNameByte(tok[i]) {
+			return false
+		}
+	}
+	return true
+}
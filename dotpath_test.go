@@ -0,0 +1,129 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+func TestDotPath(t *testing.T) {
+	for _, test := range []struct {
+		ptr  string
+		path string
+	}{
+		{``, ``},
+		{`/spec/containers/0/image`, `spec.containers[0].image`},
+		{`/0/a`, `[0].a`},
+		{`/a/-`, `a[-]`},
+		{`/a/10/0`, `a[10][0]`},
+		{`/a/007`, `a["007"]`},
+		{`/a/1x`, `a["1x"]`},
+		{`/`, `[""]`},
+		{`/a//b`, `a[""].b`},
+		{`/metadata/labels/app.kubernetes.io~1name`, `metadata.labels["app.kubernetes.io/name"]`},
+		{`/a[0]`, `["a[0]"]`},
+		{`/a b`, `["a b"]`},
+		{`/"/'/\`, `["\""]["'"]["\\"]`},
+		{`/é/$ref/~0x/-a`, `é.$ref.~x.-a`},
+		{"/\n\x00\xff", `["\n\x00\xff"]`},
+	} {
+		ptr := jsonptr.MustParse(test.ptr)
+		if got := ptr.DotPath(); got != test.path {
+			t.Errorf("%q: got %s, expected %s", test.ptr, got, test.path)
+		}
+		back, err := jsonptr.ParseDotPath(test.path)
+		if err != nil {
+			t.Errorf("%s: %v", test.path, err)
+		} else if back.String() != test.ptr {
+			t.Errorf("%s: got %q, expected %q", test.path, back, test.ptr)
+		}
+	}
+}
+
+func TestParseDotPath(t *testing.T) {
+	for _, test := range []struct {
+		path string
+		ptr  string
+	}{
+		{`.`, ``},
+		{`.a.b`, `/a/b`},
+		{`.[0]`, `/0`},
+		{`items.0.name`, `/items/0/name`},
+		{`a['b.c']`, `/a/b.c`},
+		{`a['it\'s "x"']`, `/a/it's "x"`},
+		{`a["é\t"]`, "/a/é\t"},
+		{`a[007]`, `/a/007`},
+		{`a/b.c~d`, `/a~1b/c~0d`},
+	} {
+		ptr, err := jsonptr.ParseDotPath(test.path)
+		if err != nil {
+			t.Errorf("%s: %v", test.path, err)
+		} else if ptr.String() != test.ptr {
+			t.Errorf("%s: got %q, expected %q", test.path, ptr, test.ptr)
+		}
+	}
+
+	for _, test := range []struct {
+		path   string
+		offset int
+	}{
+		{`..a`, 1},
+		{`a.`, 2},
+		{`a..b`, 2},
+		{`a[`, 2},
+		{`a[]`, 2},
+		{`a[x]`, 2},
+		{`a[-1]`, 2},
+		{`a[0`, 3},
+		{`a["b"`, 5},
+		{`a["b]`, 5},
+		{`a["\q"]`, 2},
+		{`a[0]b`, 4},
+		{`a b`, 1},
+		{`a]`, 1},
+		{`a.b'`, 3},
+	} {
+		_, err := jsonptr.ParseDotPath(test.path)
+		e, ok := err.(*jsonptr.BadPointerError)
+		if !ok {
+			t.Errorf("%s: BadPointerError expected, got %v", test.path, err)
+			continue
+		}
+		t.Log(err)
+		if e.Err != jsonptr.ErrSyntax || e.Input != test.path || e.Offset != test.offset {
+			t.Errorf("%s: got %v at offset %d, expected offset %d", test.path, e.Err, e.Offset, test.offset)
+		}
+	}
+}
+
+func TestDotPathRoundTrip(t *testing.T) {
+	tokens := []string{"", "a", "0", "00", "-", "--", "1.5", ".", "[", "]", "'", `"`, `\`, " ", "\x7f", "\xc3", "é", "~", "/", "a.b[0]"}
+	for _, a := range tokens {
+		for _, b := range tokens {
+			ptr := jsonptr.Pointer{a, b}
+			back, err := jsonptr.ParseDotPath(ptr.DotPath())
+			if err != nil {
+				t.Errorf("%q: %s: %v", ptr, ptr.DotPath(), err)
+			} else if back.String() != ptr.String() {
+				t.Errorf("%q: %s: got %q", ptr, ptr.DotPath(), back)
+			}
+		}
+	}
+}
+
+func ExampleParseDotPath() {
+	ptr, _ := jsonptr.ParseDotPath(`spec.containers[0].image`)
+	fmt.Println(ptr)
+	ptr, _ = jsonptr.ParseDotPath(`metadata.labels["app.kubernetes.io/name"]`)
+	fmt.Println(ptr)
+	fmt.Println(ptr.DotPath())
+	// Output:
+	// /spec/containers/0/image
+	// /metadata/labels/app.kubernetes.io~1name
+	// metadata.labels["app.kubernetes.io/name"]
+}
@@ -0,0 +1,211 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrorAt is an error tied to a location in a document.
+type ErrorAt struct {
+	Ptr Pointer
+	Err error
+}
+
+// Error implements the 'error' interface.
+func (e *ErrorAt) Error() string {
+	msg := e.Err.Error()
+	prefix := strconv.Quote(e.Ptr.String()) + ": "
+	if strings.HasPrefix(msg, prefix) {
+		// Error types of this package already report the location
+		return msg
+	}
+	return prefix + msg
+}
+
+// Unwrap allows to unwrap the error (see [errors.Unwrap]).
+func (e *ErrorAt) Unwrap() error {
+	return e.Err
+}
+
+// Errors collects errors, each tied to a location in a document, to report
+// every problem found in a document (for example when validating a request
+// body) instead of just the first one.
+//
+// [errors.Is] and [errors.As] look into every member.
+type Errors []*ErrorAt
+
+// Add appends err, located at ptr. A nil err is ignored.
+func (errs *Errors) Add(ptr Pointer, err error) {
+	if err == nil {
+		return
+	}
+	*errs = append(*errs, &ErrorAt{Ptr: ptr, Err: err})
+}
+
+// Append appends an error returned by this package, using the location
+// reported in the error (PtrError, DocumentError, BadPointerError). Other
+// errors are located at the root of the document. A nil err is ignored.
+func (errs *Errors) Append(err error) {
+	var ptr string
+	switch e := err.(type) {
+	case nil:
+		return
+	case *PtrError:
+		ptr = e.Ptr
+	case *DocumentError:
+		ptr = e.Ptr
+	case *BadPointerError:
+		ptr = e.BadPtr
+	}
+	p, perr := Parse(ptr)
+	if perr != nil {
+		p = nil
+	}
+	errs.Add(p, err)
+}
+
+// Err returns errs as an error, or nil if errs is empty.
+func (errs Errors) Err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Error implements the 'error' interface, with one line per error.
+func (errs Errors) Error() string {
+	var b strings.Builder
+	for i, e := range errs {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(e.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the errors, for [errors.Is] and [errors.As] (Go 1.20+).
+func (errs Errors) Unwrap() []error {
+	list := make([]error, len(errs))
+	for i, e := range errs {
+		list[i] = e
+	}
+	return list
+}
+
+// Sort sorts the errors in document order: a parent before its children,
+// array elements by index, object properties by name.
+// The original order of errors at the same location is preserved.
+func (errs Errors) Sort() {
+	sort.SliceStable(errs, func(i, j int) bool {
+		return Compare(errs[i].Ptr, errs[j].Ptr) < 0
+	})
+}
+
+// Compare compares pointers in document order. The result is 0 if a == b,
+// -1 if a comes before b, and +1 if a comes after b.
+//
+// A pointer comes before the pointers to its descendants. Tokens which are
+// both array indexes are compared as numbers, others as strings.
+func Compare(a, b Pointer) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] == b[i] {
+			continue
+		}
+		m, errm := arrayIndex(a[i])
+		n, errn := arrayIndex(b[i])
+		if errm == nil && errn == nil && m >= 0 && n >= 0 {
+			if m < n {
+				return -1
+			}
+			return 1
+		}
+		if a[i] < b[i] {
+			return -1
+		}
+		return 1
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// MarshalJSON implements [encoding/json.Marshaler].
+//
+// Errors are rendered as the "errors" extension member of [Problem]:
+// an array of objects with a "detail" member and a "pointer" member
+// (a JSON Pointer in URI fragment representation, as in RFC 9457).
+func (errs Errors) MarshalJSON() ([]byte, error) {
+	type errorJSON struct {
+		Detail  string `json:"detail"`
+		Pointer string `json:"pointer"`
+	}
+	list := make([]errorJSON, len(errs))
+	for i, e := range errs {
+		list[i] = errorJSON{
+			Detail:  e.Err.Error(),
+			Pointer: e.Ptr.URIFragment(),
+		}
+	}
+	return json.Marshal(list)
+}
+
+// ProblemContentType is the media type of [Problem] documents.
+const ProblemContentType = "application/problem+json"
+
+// Problem is a problem details object (RFC 9457) with an "errors" extension
+// member listing each error with its location.
+//
+// Specification: https://www.rfc-editor.org/rfc/rfc9457
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Errors   Errors `json:"errors,omitempty"`
+}
+
+// URIFragment returns the URI fragment identifier representation of the
+// pointer (RFC 6901 section 6), including the leading '#'.
+func (ptr Pointer) URIFragment() string {
+	const hex = "0123456789ABCDEF"
+	s := ptr.String()
+	b := make([]byte, 1, len(s)+1)
+	b[0] = '#'
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isFragmentChar(c) {
+			b = append(b, c)
+		} else {
+			b = append(b, '%', hex[c>>4], hex[c&15])
+		}
+	}
+	return string(b)
+}
+
+// isFragmentChar reports if c can appear unescaped in a URI fragment (RFC 3986).
+func isFragmentChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	switch c {
+	case '-', '.', '_', '~', // unreserved
+		'!', '$', '&', '\'', '(', ')', '*', '+', ',', ';', '=', // sub-delims
+		':', '@', '/', '?':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+//go:build go1.13 && !go1.20
+// +build go1.13,!go1.20
+
+package jsonptr
+
+import "errors"
+
+// Before Go 1.20, errors.Is and errors.As don't know about Unwrap() []error.
+
+// Is allows errors.Is to look into every member.
+func (errs Errors) Is(target error) bool {
+	for _, e := range errs {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As allows errors.As to look into every member.
+func (errs Errors) As(target interface{}) bool {
+	for _, e := range errs {
+		if errors.As(e, target) {
+			return true
+		}
+	}
+	return false
+}
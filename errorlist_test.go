@@ -0,0 +1,143 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+//go:build go1.13
+// +build go1.13
+
+package jsonptr_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+func TestErrors(t *testing.T) {
+	doc := map[string]interface{}{
+		"items": []interface{}{"a", "b"},
+		"name":  true,
+	}
+
+	var errs jsonptr.Errors
+	if errs.Err() != nil {
+		t.Fatal("empty Errors must give a nil error")
+	}
+	for _, ptr := range []string{"/name/x", "/items/10", "/items/2", "/nmae", "/items/0"} {
+		_, err := jsonptr.Get(doc, ptr)
+		errs.Append(err)
+	}
+	errs.Add(jsonptr.Pointer{}, errors.New("root problem"))
+	errs.Add(jsonptr.Pointer{"items", "9"}, errors.New("custom"))
+	errs.Add(jsonptr.Pointer{"x"}, nil)
+	if len(errs) != 6 {
+		t.Fatalf("got %d errors", len(errs))
+	}
+
+	errs.Sort()
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Ptr.String())
+	}
+	if fmt.Sprint(got) != "[ /items/2 /items/9 /items/10 /name /nmae]" {
+		t.Errorf("Sort: got %q", got)
+	}
+
+	err := errs.Err()
+	if !errors.Is(err, jsonptr.ErrIndex) || !errors.Is(err, jsonptr.ErrNotContainer) || errors.Is(err, jsonptr.ErrSyntax) {
+		t.Error("errors.Is failure")
+	}
+	var docErr *jsonptr.DocumentError
+	if !errors.As(err, &docErr) || docErr.Ptr != "/name" {
+		t.Errorf("errors.As failure: %v", docErr)
+	}
+
+	const expected = `"": root problem
+"/items/2": invalid array index
+"/items/9": custom
+"/items/10": invalid array index
+"/name": not an object or array but bool
+"/nmae": property not found`
+	if err.Error() != expected {
+		t.Errorf("Error(): got\n%s", err)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	for _, test := range []struct {
+		a, b     jsonptr.Pointer
+		expected int
+	}{
+		{nil, nil, 0},
+		{nil, jsonptr.Pointer{"a"}, -1},
+		{jsonptr.Pointer{"a", "b"}, jsonptr.Pointer{"a"}, 1},
+		{jsonptr.Pointer{"a", "2"}, jsonptr.Pointer{"a", "10"}, -1},
+		{jsonptr.Pointer{"a", "b"}, jsonptr.Pointer{"a", "10"}, 1},
+		{jsonptr.Pointer{"a", "-"}, jsonptr.Pointer{"a", "10"}, -1},
+		{jsonptr.Pointer{"a", "x", "z"}, jsonptr.Pointer{"a", "y"}, -1},
+	} {
+		if got := jsonptr.Compare(test.a, test.b); got != test.expected {
+			t.Errorf("%q %q: got %d", test.a, test.b, got)
+		}
+	}
+}
+
+func TestPointerURIFragment(t *testing.T) {
+	// Examples from RFC 6901 section 6
+	for _, test := range []struct {
+		ptr      jsonptr.Pointer
+		expected string
+	}{
+		{nil, "#"},
+		{jsonptr.Pointer{"foo"}, "#/foo"},
+		{jsonptr.Pointer{"foo", "0"}, "#/foo/0"},
+		{jsonptr.Pointer{""}, "#/"},
+		{jsonptr.Pointer{"a/b"}, "#/a~1b"},
+		{jsonptr.Pointer{"c%d"}, "#/c%25d"},
+		{jsonptr.Pointer{"e^f"}, "#/e%5Ef"},
+		{jsonptr.Pointer{"g|h"}, "#/g%7Ch"},
+		{jsonptr.Pointer{"i\\j"}, "#/i%5Cj"},
+		{jsonptr.Pointer{"k\"l"}, "#/k%22l"},
+		{jsonptr.Pointer{" "}, "#/%20"},
+		{jsonptr.Pointer{"m~n"}, "#/m~0n"},
+		{jsonptr.Pointer{"é"}, "#/%C3%A9"},
+	} {
+		if got := test.ptr.URIFragment(); got != test.expected {
+			t.Errorf("%q: got %q", test.ptr, got)
+		}
+	}
+}
+
+func ExampleProblem() {
+	var errs jsonptr.Errors
+	errs.Add(jsonptr.Pointer{"age"}, errors.New("must be a positive integer"))
+	errs.Add(jsonptr.Pointer{"profile", "color"}, errors.New("must be 'green', 'red' or 'blue'"))
+
+	problem := jsonptr.Problem{
+		Type:   "https://example.net/validation-error",
+		Title:  "Your request is not valid.",
+		Status: 422,
+		Errors: errs,
+	}
+	out, _ := json.MarshalIndent(&problem, "", "  ")
+	fmt.Println(string(out))
+	// Output:
+	// {
+	//   "type": "https://example.net/validation-error",
+	//   "title": "Your request is not valid.",
+	//   "status": 422,
+	//   "errors": [
+	//     {
+	//       "detail": "must be a positive integer",
+	//       "pointer": "#/age"
+	//     },
+	//     {
+	//       "detail": "must be 'green', 'red' or 'blue'",
+	//       "pointer": "#/profile/color"
+	//     }
+	//   ]
+	// }
+}
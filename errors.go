@@ -8,7 +8,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 )
 
 var (
@@ -16,6 +18,16 @@ var (
 	ErrIndex    = errors.New("invalid array index")
 	ErrProperty = errors.New("property not found")
 
+	ErrDuplicateKey = errors.New("duplicate key")
+
+	ErrOrder = errors.New("out of document order")
+
+	ErrLimit = errors.New("limit exceeded")
+
+	ErrNotContainer = errors.New("not an object or array")
+
+	ErrElemType = errors.New("value doesn't match the element type")
+
 	ErrRoot = errors.New("can't go up from root")
 
 	ErrDeleteRoot = errors.New("can't delete root")
@@ -30,10 +42,18 @@ type ptrError interface {
 
 // BadPointerError signals JSON Pointer parsing errors
 type BadPointerError struct {
-	// Ptr is the substring of the original pointer where the error occurred
+	// BadPtr is the prefix of the original pointer up to the end of the
+	// reference token where the error occurred
 	BadPtr string
-	// Err is ErrSyntax
+	// Err is ErrSyntax (or ErrParam for [Template.Expand])
 	Err error
+	// Input is the full pointer
+	Input string
+	// Offset is the byte offset of the error in Input
+	Offset int
+	// Token is the index of the reference token where the error occurred,
+	// or -1 if the error is not in a reference token (missing leading '/')
+	Token int
 }
 
 // Error implements the 'error' interface
@@ -46,22 +66,98 @@ func (e *BadPointerError) Unwrap() error {
 	return e.Err
 }
 
+// MarshalJSON implements [encoding/json.Marshaler].
+func (e *BadPointerError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Message string `json:"message"`
+		Pointer string `json:"pointer"`
+		Error   string `json:"error"`
+		Input   string `json:"input"`
+		Offset  int    `json:"offset"`
+		Token   int    `json:"token"`
+	}{
+		Message: e.Error(),
+		Pointer: e.BadPtr,
+		Error:   e.Err.Error(),
+		Input:   e.Input,
+		Offset:  e.Offset,
+		Token:   e.Token,
+	})
+}
+
 func (e *BadPointerError) rebase(base string) {
 	if e != nil {
 		e.BadPtr = base + e.BadPtr
+		e.Input = base + e.Input
+		e.Offset += len(base)
+		if e.Token >= 0 {
+			e.Token += strings.Count(base, "/")
+		}
 	}
 }
 
+// syntaxError reports a non-empty pointer which doesn't start with '/'.
 func syntaxError(ptr string) *BadPointerError {
-	return &BadPointerError{ptr, ErrSyntax}
+	return &BadPointerError{BadPtr: ptr, Err: ErrSyntax, Input: ptr, Token: -1}
+}
+
+// tokenError reports an error in the reference token of ptr which ends at end.
+func tokenError(ptr string, end int, err error) *BadPointerError {
+	start := strings.LastIndexByte(ptr[:end], '/') + 1
+	return &BadPointerError{
+		BadPtr: ptr[:end],
+		Err:    err,
+		Input:  ptr,
+		Offset: start,
+		Token:  strings.Count(ptr[:start], "/") - 1,
+	}
+}
+
+// checkSyntax checks that ptr is a valid JSON pointer.
+func checkSyntax(ptr string) *BadPointerError {
+	if len(ptr) == 0 {
+		return nil
+	}
+	if ptr[0] != '/' {
+		return syntaxError(ptr)
+	}
+	// Optimize for the common case
+	i := strings.IndexByte(ptr, '~')
+	if i < 0 {
+		return nil
+	}
+	for ; i < len(ptr); i++ {
+		if ptr[i] != '~' {
+			continue
+		}
+		if i+1 == len(ptr) || (ptr[i+1] != '0' && ptr[i+1] != '1') {
+			end := strings.IndexByte(ptr[i:], '/')
+			if end < 0 {
+				end = len(ptr)
+			} else {
+				end += i
+			}
+			e := tokenError(ptr, end, ErrSyntax)
+			e.Offset = i
+			return e
+		}
+		i++
+	}
+	return nil
 }
 
 // PtrError signals JSON Pointer navigation errors.
 type PtrError struct {
 	// Ptr is the substring of the original pointer where the error occurred.
 	Ptr string
-	// Err is one of ErrIndex, ErrProperty.
+	// Err is one of ErrIndex, ErrProperty, ErrDuplicateKey, ErrOrder, ErrLimit,
+	// ErrNoRoute, ErrPatch.
 	Err error
+	// Len is the length of the array, for ErrIndex (-1 if unknown).
+	Len int
+	// Keys is the list of existing properties with a name close to the one
+	// which was not found, for ErrProperty. Closest first.
+	Keys []string
 }
 
 // Error implements the 'error' interface
@@ -74,28 +170,148 @@ func (e *PtrError) Unwrap() error {
 	return e.Err
 }
 
+// MarshalJSON implements [encoding/json.Marshaler].
+func (e *PtrError) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Message string   `json:"message"`
+		Pointer string   `json:"pointer"`
+		Error   string   `json:"error"`
+		Len     *int     `json:"length,omitempty"`
+		Keys    []string `json:"keys,omitempty"`
+	}{
+		Message: e.Error(),
+		Pointer: e.Ptr,
+		Error:   e.Err.Error(),
+		Keys:    e.Keys,
+	}
+	if e.Err == ErrIndex && e.Len >= 0 {
+		v.Len = &e.Len
+	}
+	return json.Marshal(&v)
+}
+
 func (e *PtrError) rebase(base string) {
 	if e != nil {
 		e.Ptr = base + e.Ptr
 	}
 }
 
-func indexError(ptr string) *PtrError {
-	return &PtrError{ptr, ErrIndex}
+// indexError reports an index out of range in an array of the given length.
+func indexError(ptr string, length int) *PtrError {
+	return &PtrError{Ptr: ptr, Err: ErrIndex, Len: length}
+}
+
+// propertyError reports a missing key among the keys of an object.
+func propertyError(ptr string, key string, keys []string) *PtrError {
+	return &PtrError{Ptr: ptr, Err: ErrProperty, Keys: nearbyKeys(key, keys)}
+}
+
+func duplicateKeyError(ptr string) *PtrError {
+	return &PtrError{Ptr: ptr, Err: ErrDuplicateKey}
+}
+
+func limitPtrError(ptr string) *PtrError {
+	return &PtrError{Ptr: ptr, Err: ErrLimit}
+}
+
+// mapKeys returns the keys of an object, for propertyError.
+func mapKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// nearbyKeys returns the keys close to key, closest first.
+func nearbyKeys(key string, keys []string) []string {
+	type candidate struct {
+		key  string
+		dist int
+	}
+	var candidates []candidate
+	for _, k := range keys {
+		d := editDistance(key, k)
+		if strings.EqualFold(key, k) {
+			d = 0
+		}
+		if d <= 2 && d < len(key) && d < len(k) {
+			candidates = append(candidates, candidate{k, d})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].dist != candidates[j].dist {
+			return candidates[i].dist < candidates[j].dist
+		}
+		return candidates[i].key < candidates[j].key
+	})
+	result := make([]string, len(candidates))
+	for i := range candidates {
+		result[i] = candidates[i].key
+	}
+	return result
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	row := make([]int, len(rb)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		prev := row[0]
+		row[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur := row[j]
+			row[j] = min3(row[j]+1, row[j-1]+1, prev+cost)
+			prev = cur
+		}
+	}
+	return row[len(rb)]
 }
 
-func propertyError(ptr string) *PtrError {
-	return &PtrError{ptr, ErrProperty}
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
 }
 
 // DocumentError signals a document that can't be processed by this library
 type DocumentError struct {
 	Ptr string
 	Err error
+	// GoType is the Go type of the value found at Ptr, for ErrNotContainer
+	// and ErrTest, or of the container of Ptr, for ErrElemType.
+	GoType string
+	// JSONType is the JSON type ("string", "number", "boolean", "null",
+	// "array" or "object") of the same value as GoType.
+	// Empty if the value has no JSON equivalent.
+	JSONType string
 }
 
 // Error implements the 'error' interface.
 func (e *DocumentError) Error() string {
+	if e.Err == ErrNotContainer {
+		return strconv.Quote(e.Ptr) + ": " + e.Err.Error() + " but " + e.GoType
+	}
+	if e.Err == ErrElemType {
+		return strconv.Quote(e.Ptr) + ": " + e.Err.Error() + " of " + e.GoType
+	}
+	if e.Err == ErrMapKey || e.Err == ErrTest {
+		return strconv.Quote(e.Ptr) + ": " + e.Err.Error()
+	}
 	return e.Err.Error()
 }
 
@@ -104,19 +320,85 @@ func (e *DocumentError) Unwrap() error {
 	return e.Err
 }
 
+// MarshalJSON implements [encoding/json.Marshaler].
+func (e *DocumentError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Message  string `json:"message"`
+		Pointer  string `json:"pointer"`
+		Error    string `json:"error"`
+		GoType   string `json:"goType,omitempty"`
+		JSONType string `json:"jsonType,omitempty"`
+	}{
+		Message:  e.Error(),
+		Pointer:  e.Ptr,
+		Error:    e.Err.Error(),
+		GoType:   e.GoType,
+		JSONType: e.JSONType,
+	})
+}
+
 func (e *DocumentError) rebase(base string) {
 	if e != nil {
 		e.Ptr = base + e.Ptr
 	}
 }
 
+// docError reports that the value doc at ptr can't be navigated.
 func docError(ptr string, doc interface{}) *DocumentError {
-	return &DocumentError{ptr, fmt.Errorf("%q: not an object or array but %T", ptr, doc)}
+	return &DocumentError{
+		Ptr:      ptr,
+		Err:      ErrNotContainer,
+		GoType:   fmt.Sprintf("%T", doc),
+		JSONType: jsonType(doc),
+	}
+}
+
+// jsonType returns the JSON type of a value of the data model.
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64, float32, json.Number,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}, *Object:
+		return "object"
+	}
+	switch typedKind(v) {
+	case typedArray:
+		return "array"
+	case typedObject:
+		return "object"
+	default:
+		return ""
+	}
+}
+
+// elemTypeError reports a value set at ptr that can't be stored in the
+// typed container parent.
+func elemTypeError(ptr string, parent interface{}) *DocumentError {
+	return &DocumentError{
+		Ptr:      ptr,
+		Err:      ErrElemType,
+		GoType:   fmt.Sprintf("%T", parent),
+		JSONType: jsonType(parent),
+	}
+}
+
+func limitError(ptr string) *DocumentError {
+	return &DocumentError{Ptr: ptr, Err: ErrLimit}
 }
 
 func jsonError(ptr string, err error) *DocumentError {
 	if e, ok := err.(*json.SyntaxError); ok {
-		return &DocumentError{"", e}
+		return &DocumentError{Err: e}
 	}
-	return &DocumentError{ptr, err}
+	return &DocumentError{Ptr: ptr, Err: err}
 }
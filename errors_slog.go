@@ -0,0 +1,57 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+//go:build go1.21
+// +build go1.21
+
+package jsonptr
+
+import "log/slog"
+
+var (
+	_ slog.LogValuer = (*BadPointerError)(nil)
+	_ slog.LogValuer = (*PtrError)(nil)
+	_ slog.LogValuer = (*DocumentError)(nil)
+)
+
+// LogValue implements [log/slog.LogValuer].
+func (e *BadPointerError) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("pointer", e.BadPtr),
+		slog.String("error", e.Err.Error()),
+		slog.String("input", e.Input),
+		slog.Int("offset", e.Offset),
+		slog.Int("token", e.Token),
+	)
+}
+
+// LogValue implements [log/slog.LogValuer].
+func (e *PtrError) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("pointer", e.Ptr),
+		slog.String("error", e.Err.Error()),
+	}
+	if e.Err == ErrIndex && e.Len >= 0 {
+		attrs = append(attrs, slog.Int("length", e.Len))
+	}
+	if len(e.Keys) > 0 {
+		attrs = append(attrs, slog.Any("keys", e.Keys))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements [log/slog.LogValuer].
+func (e *DocumentError) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("pointer", e.Ptr),
+		slog.String("error", e.Err.Error()),
+	}
+	if e.GoType != "" {
+		attrs = append(attrs, slog.String("goType", e.GoType))
+	}
+	if e.JSONType != "" {
+		attrs = append(attrs, slog.String("jsonType", e.JSONType))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,37 @@
+//go:build go1.21
+// +build go1.21
+
+package jsonptr
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestErrorsLogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Info("get", "err", propertyError("/nme", "nme", []string{"name"}))
+	logger.Info("get", "err", indexError("/3", 3))
+	logger.Info("get", "err", docError("/a", "x"))
+	logger.Info("get", "err", syntaxError("a"))
+	logger.Info("get", "err", tokenError("/a/~2/b", 5, ErrSyntax))
+
+	const expected = `level=INFO msg=get err.pointer=/nme err.error="property not found" err.keys=[name]
+level=INFO msg=get err.pointer=/3 err.error="invalid array index" err.length=3
+level=INFO msg=get err.pointer=/a err.error="not an object or array" err.goType=string err.jsonType=string
+level=INFO msg=get err.pointer=a err.error="invalid JSON pointer" err.input=a err.offset=0 err.token=-1
+level=INFO msg=get err.pointer=/a/~2 err.error="invalid JSON pointer" err.input=/a/~2/b err.offset=3 err.token=1
+`
+	if got := buf.String(); got != expected {
+		t.Errorf("got:\n%s", got)
+	}
+}
@@ -1,6 +1,11 @@
 package jsonptr
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
 
 // Wrapper is a copy of https://godoc.org/golang.org/x/exp/errors#Wrapper
 type Wrapper interface {
@@ -11,10 +16,125 @@ type Wrapper interface {
 
 var (
 	_ = []Wrapper{
-		indexError("/1"),
-		propertyError("/a"),
+		indexError("/1", 1),
+		propertyError("/a", "a", nil),
 		syntaxError("azerty"),
 		docError("", complex(1, 2)),
 		jsonError("", &json.SyntaxError{Offset: 0}),
 	}
+
+	_ = []json.Marshaler{
+		indexError("/1", 1),
+		syntaxError("azerty"),
+		docError("", complex(1, 2)),
+	}
 )
+
+func TestNearbyKeys(t *testing.T) {
+	keys := []string{"name", "Name", "names", "nmae", "id", "description", "nam", "x"}
+	for _, test := range []struct {
+		key      string
+		expected []string
+	}{
+		{"nme", []string{"name", "nmae", "Name", "nam", "names"}},
+		{"ID", []string{"id"}},
+		{"y", nil},
+		{"descrption", []string{"description"}},
+		{"zzzzz", nil},
+	} {
+		got := nearbyKeys(test.key, keys)
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%q: got %q", test.key, got)
+		}
+	}
+}
+
+func TestErrorDetails(t *testing.T) {
+	doc := map[string]interface{}{
+		"name":  "x",
+		"title": "y",
+		"list":  []interface{}{1, 2, 3},
+	}
+	raw, _ := json.Marshal(doc)
+
+	for _, in := range []interface{}{
+		doc,
+		json.RawMessage(raw),
+		json.NewDecoder(strings.NewReader(string(raw))),
+	} {
+		_, err := Get(in, "/nmae")
+		if e, ok := err.(*PtrError); !ok || e.Err != ErrProperty || !reflect.DeepEqual(e.Keys, []string{"name"}) {
+			t.Errorf("%T: got %#v", in, err)
+		}
+	}
+
+	for _, in := range []interface{}{
+		doc,
+		json.RawMessage(raw),
+		json.NewDecoder(strings.NewReader(string(raw))),
+	} {
+		_, err := Get(in, "/list/3")
+		if e, ok := err.(*PtrError); !ok || e.Err != ErrIndex || e.Len != 3 {
+			t.Errorf("%T: got %#v", in, err)
+		}
+	}
+
+	for _, in := range []interface{}{
+		doc,
+		json.RawMessage(raw),
+		json.NewDecoder(strings.NewReader(string(raw))),
+	} {
+		_, err := Get(in, "/title/x")
+		e, ok := err.(*DocumentError)
+		if !ok || e.Err != ErrNotContainer || e.Ptr != "/title" || e.GoType != "string" || e.JSONType != "string" {
+			t.Errorf("%T: got %#v", in, err)
+		} else if e.Error() != `"/title": not an object or array but string` {
+			t.Errorf("%T: got %q", in, e.Error())
+		}
+	}
+
+	_, err := Pointer{"name", "x"}.In(doc)
+	if e, ok := err.(*DocumentError); !ok || e.Ptr != "/name" {
+		t.Errorf("In: got %#v", err)
+	}
+	_, err = Pointer{"x"}.In(1.5)
+	if e, ok := err.(*DocumentError); !ok || e.Ptr != "" || e.JSONType != "number" {
+		t.Errorf("In: got %#v", err)
+	}
+}
+
+func TestErrorsMarshalJSON(t *testing.T) {
+	for _, test := range []struct {
+		err      error
+		expected string
+	}{
+		{syntaxError("a"), `{"message":"\"a\": invalid JSON pointer","pointer":"a","error":"invalid JSON pointer","input":"a","offset":0,"token":-1}`},
+		{checkSyntax("/a/b~x"), `{"message":"\"/a/b~x\": invalid JSON pointer","pointer":"/a/b~x","error":"invalid JSON pointer","input":"/a/b~x","offset":4,"token":1}`},
+		{indexError("/3", 3), `{"message":"\"/3\": invalid array index","pointer":"/3","error":"invalid array index","length":3}`},
+		{indexError("/-", -1), `{"message":"\"/-\": invalid array index","pointer":"/-","error":"invalid array index"}`},
+		{propertyError("/nme", "nme", []string{"name"}), `{"message":"\"/nme\": property not found","pointer":"/nme","error":"property not found","keys":["name"]}`},
+		{docError("/a", true), `{"message":"\"/a\": not an object or array but bool","pointer":"/a","error":"not an object or array","goType":"bool","jsonType":"boolean"}`},
+		{limitError("/a"), `{"message":"limit exceeded","pointer":"/a","error":"limit exceeded"}`},
+	} {
+		got, err := json.Marshal(test.err)
+		if err != nil {
+			t.Errorf("%v: %v", test.err, err)
+		} else if string(got) != test.expected {
+			t.Errorf("%v: got %s", test.err, got)
+		}
+	}
+}
+
+func TestBadPointerRebase(t *testing.T) {
+	doc := map[string]interface{}{"a": json.RawMessage(`{"b":[1]}`)}
+	for _, get := range []func() (interface{}, error){
+		func() (interface{}, error) { return Get(doc, "/a/b/x") },
+		func() (interface{}, error) { return Pointer{"a", "b", "x"}.In(doc) },
+	} {
+		_, err := get()
+		expected := &BadPointerError{BadPtr: "/a/b/x", Err: ErrSyntax, Input: "/a/b/x", Offset: 5, Token: 2}
+		if !reflect.DeepEqual(err, expected) {
+			t.Errorf("got %#v", err)
+		}
+	}
+}
@@ -0,0 +1,406 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+// Package httpdoc serves a JSON document over HTTP, where the path of the
+// URL is a JSON Pointer into the document:
+//
+//	GET    /doc/a/b   returns the value at /a/b
+//	PUT    /doc/a/b   sets the value at /a/b (jsonptr.Set)
+//	DELETE /doc/a/b   deletes the value at /a/b (jsonptr.Delete)
+//	PATCH  /doc/a/b   applies a JSON Patch (RFC 6902) or a JSON Merge Patch
+//	                  (RFC 7396) to the value at /a/b
+//
+// Responses carry an ETag computed from the value. Updates honor If-Match,
+// and GET honors If-None-Match.
+//
+// Request bodies are limited to [Handler.MaxBodySize].
+//
+// Errors are reported with the JSON serialization of the error:
+//   - *jsonptr.BadPointerError: 400 Bad Request
+//   - *jsonptr.PtrError: 404 Not Found
+//   - *jsonptr.DocumentError: 409 Conflict
+package httpdoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+// Media types of PATCH requests.
+const (
+	JSONPatch  = "application/json-patch+json"
+	MergePatch = "application/merge-patch+json"
+)
+
+// DefaultMaxBodySize is the default of [Handler.MaxBodySize].
+const DefaultMaxBodySize = 1 << 20
+
+// Handler is an [net/http.Handler] exposing the document of a
+// [jsonptr.Store].
+//
+// The path of the request URL is the pointer: the handler is meant to be
+// mounted with [net/http.StripPrefix], so that "/doc" addresses the root of
+// the document and "/doc/a" the property "a". Each segment of the path is
+// unescaped, so "%2F" in a segment is a "/" in a key, like "~1".
+type Handler struct {
+	// MaxBodySize is the maximum size of a request body, in bytes
+	// (DefaultMaxBodySize if zero). A larger body is rejected with status
+	// 413 Request Entity Too Large.
+	MaxBodySize int64
+
+	store *jsonptr.Store
+}
+
+// NewHandler returns a Handler serving the document of store.
+func NewHandler(store *jsonptr.Store) *Handler {
+	return &Handler{store: store}
+}
+
+// statusError is an error reported with a plain HTTP status.
+type statusError struct {
+	status int
+	msg    string
+}
+
+func (e *statusError) Error() string {
+	return e.msg
+}
+
+var errPrecondition = &statusError{http.StatusPreconditionFailed, "ETag mismatch"}
+
+// ServeHTTP implements [net/http.Handler].
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ptr, err := pointer(r.URL)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if _, err := jsonptr.Parse(ptr); err != nil {
+		writeError(w, err)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		h.get(w, r, ptr)
+	case http.MethodPut:
+		h.put(w, r, ptr)
+	case http.MethodDelete:
+		h.delete(w, r, ptr)
+	case http.MethodPatch:
+		h.patch(w, r, ptr)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, DELETE, PATCH")
+		writeError(w, &statusError{http.StatusMethodNotAllowed, "method not allowed"})
+	}
+}
+
+// pointer returns the pointer addressed by the path of u. The segments of
+// the escaped path are unescaped one by one, so that an escaped "/" is
+// not a separator.
+func pointer(u *url.URL) (string, error) {
+	segments := strings.Split(u.EscapedPath(), "/")
+	for i, seg := range segments {
+		token, err := url.PathUnescape(seg)
+		if err != nil {
+			return "", &statusError{http.StatusBadRequest, err.Error()}
+		}
+		segments[i] = strings.Replace(token, "/", "~1", -1)
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request, ptr string) {
+	var body []byte
+	err := h.store.View(func(doc interface{}) error {
+		v, err := jsonptr.Get(doc, ptr)
+		if err != nil {
+			return err
+		}
+		// Serialize under the lock, as the value is shared
+		body, err = json.Marshal(v)
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	tag := etag(body)
+	w.Header().Set("ETag", tag)
+	if matchETag(r.Header["If-None-Match"], tag, true) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(body)
+}
+
+func (h *Handler) put(w http.ResponseWriter, r *http.Request, ptr string) {
+	value, err := h.readJSON(w, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var created bool
+	tag, err := h.update(r, ptr, func(tx *jsonptr.Tx, oldTag string) error {
+		created = oldTag == ""
+		return tx.Set(ptr, value)
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("ETag", tag)
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	} else {
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request, ptr string) {
+	_, err := h.update(r, ptr, func(tx *jsonptr.Tx, _ string) error {
+		_, err := tx.Delete(ptr)
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) patch(w http.ResponseWriter, r *http.Request, ptr string) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var fn func(tx *jsonptr.Tx, oldTag string) error
+	switch mediaType {
+	case JSONPatch:
+		body, err := h.readBody(w, r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		var patch []jsonptr.Operation
+		if err := json.Unmarshal(body, &patch); err != nil {
+			writeError(w, &statusError{http.StatusBadRequest, err.Error()})
+			return
+		}
+		// Paths are relative to the target of the request
+		for i := range patch {
+			op := &patch[i]
+			if _, err := jsonptr.Parse(op.Path); err != nil {
+				writeError(w, err)
+				return
+			}
+			op.Path = ptr + op.Path
+			if op.Op == "move" || op.Op == "copy" {
+				if _, err := jsonptr.Parse(op.From); err != nil {
+					writeError(w, err)
+					return
+				}
+				op.From = ptr + op.From
+			}
+		}
+		fn = func(tx *jsonptr.Tx, oldTag string) error {
+			if oldTag == "" {
+				// The target must exist: report why it doesn't
+				_, err := tx.Get(ptr)
+				return err
+			}
+			return tx.Patch(patch)
+		}
+	case MergePatch:
+		patch, err := h.readJSON(w, r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		fn = func(tx *jsonptr.Tx, _ string) error {
+			target, err := tx.Get(ptr)
+			if err != nil {
+				return err
+			}
+			return tx.Set(ptr, mergePatch(target, patch))
+		}
+	default:
+		w.Header().Set("Accept-Patch", JSONPatch+", "+MergePatch)
+		writeError(w, &statusError{http.StatusUnsupportedMediaType, "unsupported patch format"})
+		return
+	}
+	tag, err := h.update(r, ptr, fn)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("ETag", tag)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// update runs fn in a transaction of the store, once the If-Match header
+// has been verified against the ETag of the value at ptr (empty if it
+// doesn't exist). It returns the new ETag of the value at ptr.
+func (h *Handler) update(r *http.Request, ptr string, fn func(tx *jsonptr.Tx, oldTag string) error) (tag string, err error) {
+	err = h.store.Update(func(tx *jsonptr.Tx) error {
+		oldTag, err := currentETag(tx, ptr)
+		if err != nil {
+			return err
+		}
+		if ifMatch, ok := r.Header["If-Match"]; ok && !matchETag(ifMatch, oldTag, false) {
+			return errPrecondition
+		}
+		if err = fn(tx, oldTag); err != nil {
+			return err
+		}
+		tag, err = currentETag(tx, ptr)
+		return err
+	})
+	return
+}
+
+// currentETag returns the ETag of the value at ptr, or "" if there is none.
+func currentETag(tx *jsonptr.Tx, ptr string) (string, error) {
+	v, err := tx.Get(ptr)
+	if err != nil {
+		return "", nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return etag(b), nil
+}
+
+// etag returns a strong entity tag for a serialized value.
+func etag(body []byte) string {
+	h := fnv.New64a()
+	_, _ = h.Write(body)
+	return fmt.Sprintf(`"%016x"`, h.Sum64())
+}
+
+// matchETag reports if tag (empty if the resource doesn't exist) is in the
+// list of entity tags of an If-Match or If-None-Match header (RFC 9110).
+func matchETag(header []string, tag string, weak bool) bool {
+	if tag == "" {
+		return false
+	}
+	for _, h := range header {
+		for _, t := range strings.Split(h, ",") {
+			t = strings.TrimSpace(t)
+			if weak {
+				t = strings.TrimPrefix(t, "W/")
+			}
+			if t == "*" || t == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readBody reads the request body, up to MaxBodySize bytes.
+func (h *Handler) readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	max := h.MaxBodySize
+	if max <= 0 {
+		max = DefaultMaxBodySize
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, max))
+	if err != nil {
+		if int64(len(body)) >= max {
+			return nil, &statusError{http.StatusRequestEntityTooLarge, "request body too large"}
+		}
+		return nil, &statusError{http.StatusBadRequest, err.Error()}
+	}
+	return body, nil
+}
+
+// readJSON decodes the request body, preserving the order of properties.
+func (h *Handler) readJSON(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	body, err := h.readBody(w, r)
+	if err != nil {
+		return nil, err
+	}
+	v, err := jsonptr.UnmarshalOrdered(body)
+	if err != nil {
+		return nil, &statusError{http.StatusBadRequest, err.Error()}
+	}
+	return v, nil
+}
+
+// mergePatch returns the result of applying a JSON Merge Patch (RFC 7396),
+// decoded with [jsonptr.UnmarshalOrdered], to target. Target is not
+// modified.
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(*jsonptr.Object)
+	if !ok {
+		return patch
+	}
+	if m, ok := target.(map[string]interface{}); ok {
+		result := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			result[k] = v
+		}
+		for _, k := range p.Keys() {
+			if v, _ := p.Get(k); v == nil {
+				delete(result, k)
+			} else {
+				result[k] = mergePatch(result[k], v)
+			}
+		}
+		return result
+	}
+	result := jsonptr.NewObject()
+	if obj, ok := target.(*jsonptr.Object); ok {
+		for _, k := range obj.Keys() {
+			v, _ := obj.Get(k)
+			result.Set(k, v)
+		}
+	}
+	for _, k := range p.Keys() {
+		if v, _ := p.Get(k); v == nil {
+			result.Delete(k)
+		} else {
+			old, _ := result.Get(k)
+			result.Set(k, mergePatch(old, v))
+		}
+	}
+	return result
+}
+
+// writeError writes the response for err.
+func writeError(w http.ResponseWriter, err error) {
+	var status int
+	switch e := err.(type) {
+	case *statusError:
+		http.Error(w, e.msg, e.status)
+		return
+	case *jsonptr.BadPointerError:
+		status = http.StatusBadRequest
+	case *jsonptr.PtrError:
+		if e.Err == jsonptr.ErrPatch {
+			status = http.StatusBadRequest
+		} else {
+			status = http.StatusNotFound
+		}
+	case *jsonptr.DocumentError:
+		status = http.StatusConflict
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	body, _ := json.Marshal(err)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
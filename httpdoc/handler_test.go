@@ -0,0 +1,207 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package httpdoc_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dolmen-go/jsonptr"
+	"github.com/dolmen-go/jsonptr/httpdoc"
+)
+
+func newServer(t *testing.T, doc string) (*jsonptr.Store, *httptest.Server) {
+	v, err := jsonptr.UnmarshalOrdered([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := jsonptr.NewStore(v)
+	return store, httptest.NewServer(http.StripPrefix("/doc", httpdoc.NewHandler(store)))
+}
+
+type response struct {
+	status int
+	etag   string
+	body   string
+}
+
+func do(t *testing.T, srv *httptest.Server, method, path string, header map[string]string, body string) response {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := ioutil.ReadAll(resp.Body)
+	return response{resp.StatusCode, resp.Header.Get("ETag"), strings.TrimSpace(string(b))}
+}
+
+func TestHandler(t *testing.T) {
+	store, srv := newServer(t, `{"a":{"b":[1,2]},"s":"x","a/b":true}`)
+	defer srv.Close()
+
+	for _, test := range []struct {
+		method, path string
+		header       map[string]string
+		body         string
+		status       int
+		response     string
+	}{
+		{"GET", "/doc", nil, "", 200, `{"a":{"b":[1,2]},"s":"x","a/b":true}`},
+		{"GET", "/doc/a/b", nil, "", 200, `[1,2]`},
+		{"GET", "/doc/a/b/1", nil, "", 200, `2`},
+		{"GET", "/doc/a~1b", nil, "", 200, `true`},
+		{"GET", "/doc/a%2Fb", nil, "", 200, `true`},
+		{"GET", "/doc/a%2fb", nil, "", 200, `true`},
+		{"GET", "/doc/a%20b", nil, "", 404, `{"message":"\"/a b\": property not found","pointer":"/a b","error":"property not found","keys":["a/b"]}`},
+		{"GET", "/doc/a/b/5", nil, "", 404, `{"message":"\"/a/b/5\": invalid array index","pointer":"/a/b/5","error":"invalid array index","length":2}`},
+		{"GET", "/doc/a~2", nil, "", 400, `{"message":"\"/a~2\": invalid JSON pointer","pointer":"/a~2","error":"invalid JSON pointer","input":"/a~2","offset":2,"token":0}`},
+		{"HEAD", "/doc/s", nil, "", 200, ``},
+		{"POST", "/doc/s", nil, "", 405, `method not allowed`},
+
+		{"PUT", "/doc/c", nil, `{"z":1,"y":2}`, 201, ``},
+		{"GET", "/doc/c", nil, "", 200, `{"z":1,"y":2}`},
+		{"PUT", "/doc/c/y", nil, `3`, 204, ``},
+		{"PUT", "/doc/a/b/-", nil, `3`, 201, ``},
+		{"PUT", "/doc/s/t", nil, `1`, 409, `{"message":"\"/s\": not an object or array but string","pointer":"/s","error":"not an object or array","goType":"string","jsonType":"string"}`},
+		{"PUT", "/doc/x/y", nil, `1`, 404, `{"message":"\"/x\": property not found","pointer":"/x","error":"property not found"}`},
+		{"PUT", "/doc/s", nil, `{`, 400, `unexpected end of JSON input`},
+
+		{"DELETE", "/doc/a/b/0", nil, "", 204, ``},
+		{"DELETE", "/doc/a/b/5", nil, "", 400, `{"message":"\"/a/b/5\": invalid array index","pointer":"/a/b/5","error":"invalid array index","input":"/a/b/5","offset":5,"token":2}`},
+		{"DELETE", "/doc", nil, "", 400, `{"message":"\"\": can't delete root","pointer":"","error":"can't delete root","input":"","offset":0,"token":-1}`},
+		{"GET", "/doc/a", nil, "", 200, `{"b":[2,3]}`},
+
+		{"PATCH", "/doc/a", map[string]string{"Content-Type": httpdoc.JSONPatch}, `[{"op":"add","path":"/b/0","value":1},{"op":"copy","from":"/b","path":"/c"}]`, 204, ``},
+		{"GET", "/doc/a", nil, "", 200, `{"b":[1,2,3],"c":[1,2,3]}`},
+		{"PATCH", "/doc/a", map[string]string{"Content-Type": httpdoc.JSONPatch}, `[{"op":"remove","path":"/c"},{"op":"test","path":"/b/0","value":0}]`, 409, `{"message":"\"/a/b/0\": test operation failed","pointer":"/a/b/0","error":"test operation failed","goType":"float64","jsonType":"number"}`},
+		{"PATCH", "/doc/a", map[string]string{"Content-Type": httpdoc.JSONPatch}, `[{"op":"bad","path":"/c"}]`, 400, `{"message":"\"/a/c\": invalid patch operation","pointer":"/a/c","error":"invalid patch operation"}`},
+		{"PATCH", "/doc/a", map[string]string{"Content-Type": httpdoc.JSONPatch}, `[{"op":"remove","path":"c"}]`, 400, `{"message":"\"c\": invalid JSON pointer","pointer":"c","error":"invalid JSON pointer","input":"c","offset":0,"token":-1}`},
+		{"PATCH", "/doc/x", map[string]string{"Content-Type": httpdoc.JSONPatch}, `[]`, 404, `{"message":"\"/x\": property not found","pointer":"/x","error":"property not found"}`},
+		{"GET", "/doc/a", nil, "", 200, `{"b":[1,2,3],"c":[1,2,3]}`},
+		{"PATCH", "/doc", map[string]string{"Content-Type": httpdoc.MergePatch + "; charset=utf-8"}, `{"a":{"c":null,"d":{"e":1,"f":null}},"s":null,"a/b":[]}`, 204, ``},
+		{"GET", "/doc", nil, "", 200, `{"a":{"b":[1,2,3],"d":{"e":1}},"a/b":[],"c":{"z":1,"y":3}}`},
+		{"PATCH", "/doc", map[string]string{"Content-Type": "application/json"}, `{}`, 415, `unsupported patch format`},
+	} {
+		resp := do(t, srv, test.method, test.path, test.header, test.body)
+		if resp.status != test.status || resp.body != test.response {
+			t.Errorf("%s %s %s: got %d %s, expected %d %s", test.method, test.path, test.body, resp.status, resp.body, test.status, test.response)
+		}
+	}
+
+	if v, _ := store.Get("/c/z"); v != 1.0 {
+		t.Errorf("store: got %#v", v)
+	}
+}
+
+func TestHandlerMaxBodySize(t *testing.T) {
+	store := jsonptr.NewStore(map[string]interface{}{})
+	h := httpdoc.NewHandler(store)
+	h.MaxBodySize = 10
+	srv := httptest.NewServer(http.StripPrefix("/doc", h))
+	defer srv.Close()
+
+	if resp := do(t, srv, "PUT", "/doc/a", nil, `"123456"`); resp.status != 201 {
+		t.Errorf("got %d %s", resp.status, resp.body)
+	}
+	for _, test := range []struct {
+		header map[string]string
+		body   string
+	}{
+		{nil, `"1234567890"`},
+		{map[string]string{"Content-Type": httpdoc.JSONPatch}, `[{"op":"remove","path":"/a"}]`},
+		{map[string]string{"Content-Type": httpdoc.MergePatch}, `{"a":"1234567890"}`},
+	} {
+		method := "PATCH"
+		if test.header == nil {
+			method = "PUT"
+		}
+		if resp := do(t, srv, method, "/doc/a", test.header, test.body); resp.status != 413 {
+			t.Errorf("%s %s: got %d %s", method, test.body, resp.status, resp.body)
+		}
+	}
+	if v, _ := store.Get("/a"); v != "123456" {
+		t.Errorf("got %v", v)
+	}
+}
+
+func TestHandlerETag(t *testing.T) {
+	_, srv := newServer(t, `{"a":1,"b":2}`)
+	defer srv.Close()
+
+	get := do(t, srv, "GET", "/doc/a", nil, "")
+	if get.etag == "" {
+		t.Fatal("ETag expected")
+	}
+	if r := do(t, srv, "GET", "/doc/a", map[string]string{"If-None-Match": `"x", W/` + get.etag}, ""); r.status != http.StatusNotModified {
+		t.Errorf("If-None-Match: got %d", r.status)
+	}
+	if r := do(t, srv, "GET", "/doc/b", map[string]string{"If-None-Match": get.etag}, ""); r.status != http.StatusOK {
+		t.Errorf("If-None-Match: got %d", r.status)
+	}
+
+	// A change elsewhere doesn't change the ETag
+	if r := do(t, srv, "PUT", "/doc/b", map[string]string{"If-Match": "*"}, `3`); r.status != http.StatusNoContent {
+		t.Errorf("PUT: got %d", r.status)
+	}
+	put := do(t, srv, "PUT", "/doc/a", map[string]string{"If-Match": get.etag}, `2`)
+	if put.status != http.StatusNoContent || put.etag == "" || put.etag == get.etag {
+		t.Errorf("PUT: got %d %s", put.status, put.etag)
+	}
+	if r := do(t, srv, "GET", "/doc/a", nil, ""); r.etag != put.etag {
+		t.Errorf("ETag: got %s, expected %s", r.etag, put.etag)
+	}
+
+	// Lost update
+	for _, method := range []string{"PUT", "DELETE", "PATCH"} {
+		r := do(t, srv, method, "/doc/a", map[string]string{"If-Match": get.etag, "Content-Type": httpdoc.MergePatch}, `3`)
+		if r.status != http.StatusPreconditionFailed {
+			t.Errorf("%s: got %d", method, r.status)
+		}
+	}
+	if r := do(t, srv, "PUT", "/doc/c", map[string]string{"If-Match": "*"}, `1`); r.status != http.StatusPreconditionFailed {
+		t.Errorf("If-Match * on missing: got %d", r.status)
+	}
+	if r := do(t, srv, "GET", "/doc", nil, ""); r.body != `{"a":2,"b":3}` {
+		t.Errorf("got %s", r.body)
+	}
+
+	if r := do(t, srv, "DELETE", "/doc/a", map[string]string{"If-Match": put.etag}, ""); r.status != http.StatusNoContent {
+		t.Errorf("DELETE: got %d", r.status)
+	}
+}
+
+func ExampleHandler() {
+	store := jsonptr.NewStore(map[string]interface{}{
+		"server": map[string]interface{}{"port": 8080},
+	})
+	srv := httptest.NewServer(http.StripPrefix("/config", httpdoc.NewHandler(store)))
+	defer srv.Close()
+
+	req, _ := http.NewRequest("PATCH", srv.URL+"/config/server", strings.NewReader(`{"port":443,"host":"example.com"}`))
+	req.Header.Set("Content-Type", httpdoc.MergePatch)
+	resp, _ := http.DefaultClient.Do(req)
+	resp.Body.Close()
+	fmt.Println(resp.Status)
+
+	resp, _ = http.Get(srv.URL + "/config/server/host")
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	fmt.Println(resp.Status, string(body))
+	// Output:
+	// 204 No Content
+	// 200 OK "example.com"
+}
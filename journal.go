@@ -0,0 +1,164 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Journal wraps a document to record each change made with [Journal.Set]
+// and [Journal.Delete], with its inverse, for undo and redo.
+//
+// The document must be a deserialized document (made of []interface{},
+// map[string]interface{}, *[Object] and terminal values), modified only
+// through the Journal.
+type Journal struct {
+	doc    interface{}
+	done   []*change
+	undone []*change // Last undone first
+}
+
+// NewJournal returns a Journal for doc.
+func NewJournal(doc interface{}) *Journal {
+	return &Journal{doc: doc}
+}
+
+// Doc returns the document in its current state.
+func (j *Journal) Doc() interface{} {
+	return j.doc
+}
+
+// Get is like the [Get] function, on the document of the journal.
+func (j *Journal) Get(ptr string) (interface{}, error) {
+	return Get(j.doc, ptr)
+}
+
+// Set is like the [Set] function, and records the previous value (or its
+// absence). The changes that were undone can't be redone anymore.
+//
+// A copy of value is recorded, as value, now part of the document, may be
+// modified by later changes.
+func (j *Journal) Set(ptr string, value interface{}) error {
+	if err := checkSyntax(ptr); err != nil {
+		return err
+	}
+	c, err := setChange(&j.doc, ptr, value, nil)
+	if err != nil {
+		return err
+	}
+	c.value = copyValue(value)
+	j.record(c)
+	return nil
+}
+
+// Delete is like the [Delete] function, and records the removed value (and
+// its position in an array or an *[Object]). The changes that were undone
+// can't be redone anymore.
+func (j *Journal) Delete(ptr string) (interface{}, error) {
+	if err := checkSyntax(ptr); err != nil {
+		return nil, err
+	}
+	c, err := removeChange(&j.doc, ptr, nil)
+	if err != nil {
+		return nil, err
+	}
+	j.record(c)
+	return c.old, nil
+}
+
+func (j *Journal) record(c *change) {
+	j.done = append(j.done, c)
+	j.undone = nil
+}
+
+// Undo reverts the last change. It returns false if there is nothing to undo.
+func (j *Journal) Undo() bool {
+	if len(j.done) == 0 {
+		return false
+	}
+	c := j.done[len(j.done)-1]
+	j.done = j.done[:len(j.done)-1]
+	c.revert(&j.doc)
+	j.undone = append(j.undone, c)
+	return true
+}
+
+// Redo applies again the last undone change. It returns false if there is
+// nothing to redo.
+func (j *Journal) Redo() bool {
+	if len(j.undone) == 0 {
+		return false
+	}
+	c := j.undone[len(j.undone)-1]
+	j.undone = j.undone[:len(j.undone)-1]
+	// No error can happen as the document is back in the state in which
+	// the change was made
+	if c.op == "remove" {
+		c, _ = removeChange(&j.doc, c.ptr, nil)
+	} else {
+		// The recorded value is kept out of the document
+		value := c.value
+		c, _ = setChange(&j.doc, c.ptr, copyValue(value), nil)
+		c.value = value
+	}
+	j.done = append(j.done, c)
+	return true
+}
+
+// Checkpoint returns a mark of the current state of the document, for
+// [Journal.Restore] and [Journal.Operations]: the number of changes in the
+// history.
+func (j *Journal) Checkpoint() int {
+	return len(j.done)
+}
+
+// Restore undoes (or redoes) changes to go back to the state marked by
+// checkpoint. It returns false if the checkpoint is out of the history
+// (undo and redo).
+//
+// A checkpoint taken before changes that were undone then replaced by new
+// changes marks the new history.
+func (j *Journal) Restore(checkpoint int) bool {
+	if checkpoint < 0 || checkpoint > len(j.done)+len(j.undone) {
+		return false
+	}
+	for len(j.done) > checkpoint {
+		j.Undo()
+	}
+	for len(j.done) < checkpoint {
+		j.Redo()
+	}
+	return true
+}
+
+// Operations returns the changes made since checkpoint (0 for the whole
+// history) as a JSON Patch (RFC 6902) that transforms the document at
+// checkpoint into the current document.
+//
+// Values are copies: they are not shared with the document, nor with the
+// history.
+func (j *Journal) Operations(checkpoint int) []Operation {
+	if checkpoint < 0 || checkpoint > len(j.done) {
+		return nil
+	}
+	ops := make([]Operation, 0, len(j.done)-checkpoint)
+	for _, c := range j.done[checkpoint:] {
+		if c.op == "add" && c.undo.kind == undoTruncate {
+			// Setting an index beyond the end of an array pads with nulls
+			p := strings.LastIndexByte(c.ptr, '/')
+			n, _ := arrayIndex(c.ptr[p+1:])
+			for i := c.undo.index; i < n; i++ {
+				ops = append(ops, Operation{Op: "add", Path: c.ptr[:p+1] + strconv.Itoa(i)})
+			}
+		}
+		op := Operation{Op: c.op, Path: c.ptr}
+		if c.op != "remove" {
+			op.Value = copyValue(c.value)
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
@@ -0,0 +1,264 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+func TestJournal(t *testing.T) {
+	const original = `{"a":{"x":1,"y":2,"z":3},"b":[1,2,3],"c":"c"}`
+	doc, err := jsonptr.UnmarshalOrdered([]byte(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := jsonptr.NewJournal(doc)
+	marshal := func() string {
+		b, err := json.Marshal(j.Doc())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	states := []string{original}
+	for _, op := range []func() error{
+		func() error { return j.Set("/c", "x") },
+		func() error { return j.Set("/d", []interface{}{}) },
+		func() error { return j.Set("/d/-", 1) },
+		func() error { return j.Set("/b/5", "x") },
+		func() error { _, err := j.Delete("/a/y"); return err },
+		func() error { _, err := j.Delete("/b/0"); return err },
+		func() error { return j.Set("/a/x", nil) },
+		func() error { return j.Set("", "root") },
+	} {
+		if err := op(); err != nil {
+			t.Fatal(err)
+		}
+		states = append(states, marshal())
+	}
+	if j.Redo() {
+		t.Error("nothing to redo")
+	}
+
+	for i := len(states) - 2; i >= 0; i-- {
+		if !j.Undo() {
+			t.Fatalf("Undo %d failed", i)
+		}
+		if got := marshal(); got != states[i] {
+			t.Errorf("Undo %d: got %s, expected %s", i, got, states[i])
+		}
+	}
+	if j.Undo() {
+		t.Error("nothing to undo")
+	}
+	for i := 1; i < len(states); i++ {
+		if !j.Redo() {
+			t.Fatalf("Redo %d failed", i)
+		}
+		if got := marshal(); got != states[i] {
+			t.Errorf("Redo %d: got %s, expected %s", i, got, states[i])
+		}
+	}
+
+	for _, cp := range []int{3, 0, 5, len(states) - 1} {
+		if !j.Restore(cp) {
+			t.Fatalf("Restore(%d) failed", cp)
+		}
+		if got := marshal(); got != states[cp] {
+			t.Errorf("Restore(%d): got %s, expected %s", cp, got, states[cp])
+		}
+	}
+	if j.Restore(len(states)) || j.Restore(-1) {
+		t.Error("Restore out of history")
+	}
+
+	// A new change drops the changes that were undone
+	j.Restore(2)
+	if err := j.Set("/e", true); err != nil {
+		t.Fatal(err)
+	}
+	if j.Redo() || j.Restore(4) {
+		t.Error("redo after new change")
+	}
+	if cp := j.Checkpoint(); cp != 3 {
+		t.Errorf("Checkpoint: got %d", cp)
+	}
+
+	// Errors are not recorded
+	if err := j.Set("/x/y", 1); err == nil {
+		t.Error("error expected")
+	}
+	if _, err := j.Delete("b"); err == nil {
+		t.Error("error expected")
+	}
+	if cp := j.Checkpoint(); cp != 3 {
+		t.Errorf("Checkpoint: got %d", cp)
+	}
+}
+
+func TestJournalTyped(t *testing.T) {
+	original := func() interface{} {
+		return map[string]interface{}{
+			"tags":    []string{"a", "b"},
+			"labels":  map[string]string{"env": "prod"},
+			"none":    map[string]int(nil),
+			"records": []map[string]interface{}{{"id": 1}},
+		}
+	}
+	j := jsonptr.NewJournal(original())
+	marshal := func() string {
+		b, err := json.Marshal(j.Doc())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	states := []string{marshal()}
+	for _, op := range []func() error{
+		func() error { return j.Set("/tags/0", "z") },
+		func() error { return j.Set("/tags/-", "c") },
+		func() error { _, err := j.Delete("/tags/0"); return err },
+		func() error { return j.Set("/labels/x", "y") },
+		func() error { _, err := j.Delete("/labels/env"); return err },
+		func() error { return j.Set("/none/a", 1) },
+		func() error { return j.Set("/records/0/id", 2) },
+		func() error { return j.Set("/records/1", map[string]interface{}{}) },
+	} {
+		if err := op(); err != nil {
+			t.Fatal(err)
+		}
+		states = append(states, marshal())
+	}
+	for i := len(states) - 2; i >= 0; i-- {
+		if !j.Undo() {
+			t.Fatalf("Undo %d failed", i)
+		}
+		if got := marshal(); got != states[i] {
+			t.Errorf("Undo %d: got %s, expected %s", i, got, states[i])
+		}
+	}
+	if !j.Restore(len(states) - 1) {
+		t.Fatal("Restore failed")
+	}
+	if got := marshal(); got != states[len(states)-1] {
+		t.Errorf("Restore: got %s", got)
+	}
+
+	// Replay on the original document
+	doc := original()
+	if err := jsonptr.ApplyPatch(&doc, j.Operations(0)); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := json.Marshal(doc); string(b) != states[len(states)-1] {
+		t.Errorf("replay: got %s", b)
+	}
+}
+
+func TestJournalOperations(t *testing.T) {
+	j := jsonptr.NewJournal(map[string]interface{}{"a": []interface{}{1}})
+	for _, err := range []error{
+		j.Set("/a/3", "x"),
+		j.Set("/a/-", "y"),
+		j.Set("/b", nil),
+		j.Set("/a/0", 2),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, _ = j.Delete("/b")
+
+	b, _ := json.Marshal(j.Operations(0))
+	const expected = `[{"op":"add","path":"/a/1","value":null},{"op":"add","path":"/a/2","value":null},{"op":"add","path":"/a/3","value":"x"},{"op":"add","path":"/a/4","value":"y"},{"op":"add","path":"/b","value":null},{"op":"replace","path":"/a/0","value":2},{"op":"remove","path":"/b"}]`
+	if string(b) != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", b, expected)
+	}
+
+	if ops := j.Operations(4); len(ops) != 1 || ops[0].Op != "remove" {
+		t.Errorf("got %v", ops)
+	}
+	if ops := j.Operations(5); len(ops) != 0 {
+		t.Errorf("got %v", ops)
+	}
+	if ops := j.Operations(6); ops != nil {
+		t.Errorf("got %v", ops)
+	}
+}
+
+func TestJournalOperationsReplay(t *testing.T) {
+	const original = `{"a":{"b":[1]}}`
+	doc, _ := jsonptr.UnmarshalOrdered([]byte(original))
+	j := jsonptr.NewJournal(doc)
+	x := map[string]interface{}{"y": 1.0, "z": []interface{}{1.0}}
+	for _, op := range []func() error{
+		func() error { return j.Set("/x", x) },
+		func() error { _, err := j.Delete("/x/y"); return err },
+		func() error { return j.Set("/x/z/-", 2.0) },
+		func() error { return j.Set("/a/b/0", map[string]interface{}{}) },
+		func() error { return j.Set("/a/b/0/c", true) },
+		func() error { return j.Set("/w", nil) },
+	} {
+		if err := op(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	replay := func(checkpoint int, from string) {
+		t.Helper()
+		target, _ := jsonptr.UnmarshalOrdered([]byte(from))
+		ops := j.Operations(checkpoint)
+		if err := jsonptr.ApplyPatch(&target, ops); err != nil {
+			b, _ := json.Marshal(ops)
+			t.Fatalf("ApplyPatch %s: %v", b, err)
+		}
+		got, _ := json.Marshal(target)
+		expected, _ := json.Marshal(j.Doc())
+		if string(got) != string(expected) {
+			t.Errorf("replay from %d: got %s, expected %s", checkpoint, got, expected)
+		}
+	}
+	replay(0, original)
+	// Replaying twice: the exported values are not shared with the history
+	replay(0, original)
+
+	// Redo uses the recorded values, not the document
+	if !j.Restore(1) || !j.Restore(6) {
+		t.Fatal("Restore failed")
+	}
+	replay(0, original)
+	replay(2, `{"a":{"b":[1]},"x":{"z":[1]}}`)
+}
+
+func ExampleJournal() {
+	j := jsonptr.NewJournal(map[string]interface{}{
+		"title": "Draft",
+		"tags":  []interface{}{"a", "b"},
+	})
+
+	_ = j.Set("/title", "Final")
+	saved := j.Checkpoint()
+	_, _ = j.Delete("/tags/0")
+	_ = j.Set("/tags/-", "c")
+
+	patch, _ := json.Marshal(j.Operations(saved))
+	fmt.Printf("%s\n", patch)
+
+	j.Undo()
+	fmt.Println(j.Doc())
+	j.Restore(saved)
+	fmt.Println(j.Doc())
+	j.Redo()
+	fmt.Println(j.Doc())
+	// Output:
+	// [{"op":"remove","path":"/tags/0"},{"op":"add","path":"/tags/1","value":"c"}]
+	// map[tags:[b] title:Final]
+	// map[tags:[a b] title:Final]
+	// map[tags:[b] title:Final]
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// JSONC is a serialized JSON document that may contain comments (// to the
+// end of line, or /* */) and trailing commas in arrays and objects, like the
+// configuration files of VS Code. It can be used as an input to [Get] and
+// [Pointer.In].
+//
+// Other extensions of JSON5 (unquoted keys, single-quoted strings, hexadecimal
+// numbers...) are not supported.
+//
+// As plain JSON is valid JSONC, the methods of JSONC may also be used on
+// JSON documents.
+type JSONC []byte
+
+// clean returns a copy of doc where comments and trailing commas are replaced
+// by spaces, so that it can be processed as JSON with the same offsets.
+// Line breaks are kept.
+func (doc JSONC) clean() json.RawMessage {
+	clean := make([]byte, len(doc))
+	copy(clean, doc)
+	// Indexes of the last two significant bytes
+	last, prev := -1, -1
+	for i := 0; i < len(clean); i++ {
+		switch clean[i] {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case '"':
+			end, _ := rawSkipString(clean, i)
+			prev, last = last, i
+			i = end - 1
+			continue
+		case '/':
+			if i+1 >= len(clean) {
+				break
+			}
+			end := -1
+			switch clean[i+1] {
+			case '/':
+				if end = bytes.IndexByte(clean[i:], '\n'); end < 0 {
+					end = len(clean)
+				} else {
+					end += i
+				}
+			case '*':
+				if end = bytes.Index(clean[i+2:], []byte("*/")); end >= 0 {
+					end += i + 4
+				}
+			}
+			if end < 0 {
+				// Not a comment: this is a syntax error that will be
+				// reported by the JSON scanner
+				break
+			}
+			for j := i; j < end; j++ {
+				if clean[j] != '\n' && clean[j] != '\r' {
+					clean[j] = ' '
+				}
+			}
+			i = end - 1
+			continue
+		case '}', ']':
+			if last >= 0 && clean[last] == ',' && prev >= 0 {
+				switch clean[prev] {
+				case ',', '{', '[', ':':
+				default:
+					clean[last] = ' '
+				}
+			}
+		}
+		prev, last = last, i
+	}
+	return clean
+}
+
+// Locate returns the bounds of the value pointed by ptr in doc: the value
+// is doc[start:end].
+//
+// In case of error a BadPointerError, a PtrError or a DocumentError is returned.
+func (doc JSONC) Locate(ptr string) (start int, end int, err error) {
+	if err := checkSyntax(ptr); err != nil {
+		return -1, -1, err
+	}
+	return locate(doc.clean(), ptr)
+}
+
+// GetRaw returns the value pointed by ptr in doc, without decoding it. The
+// result is a slice of doc, with its comments.
+//
+// In case of error a BadPointerError, a PtrError or a DocumentError is returned.
+func (doc JSONC) GetRaw(ptr string) (JSONC, error) {
+	start, end, err := doc.Locate(ptr)
+	if err != nil {
+		return nil, err
+	}
+	return doc[start:end], nil
+}
+
+// Set returns a copy of doc where the value pointed by ptr is replaced with
+// value (serialized with [encoding/json.Marshal]). Comments and layout of the
+// rest of the document are kept intact.
+//
+// If the value does not exist, it is added (compactly serialized) to its
+// parent object, or appended to its parent array if the last reference token
+// is "-" or the length of the array.
+//
+// In case of error a BadPointerError, a PtrError or a DocumentError is returned.
+func (doc JSONC) Set(ptr string, value interface{}) (JSONC, error) {
+	if err := checkSyntax(ptr); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	clean := doc.clean()
+	start, end, err := locate(clean, ptr)
+	if err == nil {
+		return splice(doc, start, end, data), nil
+	}
+
+	// Insertion of a new member in the parent container
+	e, ok := err.(*PtrError)
+	if !ok || e.Ptr != ptr {
+		return nil, err
+	}
+	p := strings.LastIndexByte(ptr, '/')
+	switch e.Err {
+	case ErrProperty:
+		key, _ := UnescapeString(ptr[p+1:])
+		k, _ := json.Marshal(key)
+		data = append(append(k, ':'), data...)
+	case ErrIndex:
+		if n, _ := arrayIndex(ptr[p+1:]); n >= 0 && n != e.Len {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+	_, end, err = locate(clean, ptr[:p])
+	if err != nil {
+		return nil, err
+	}
+	// Insert after the last significant byte before the closing delimiter
+	i := end - 1
+	for i--; clean[i] == ' ' || clean[i] == '\t' || clean[i] == '\n' || clean[i] == '\r'; i-- {
+	}
+	if clean[i] != '{' && clean[i] != '[' {
+		data = append([]byte{','}, data...)
+	}
+	return splice(doc, i+1, i+1, data), nil
+}
+
+// splice returns a copy of doc where doc[start:end] is replaced with data.
+func splice(doc []byte, start, end int, data []byte) []byte {
+	out := make([]byte, 0, len(doc)-(end-start)+len(data))
+	out = append(out, doc[:start]...)
+	out = append(out, data...)
+	return append(out, doc[end:]...)
+}
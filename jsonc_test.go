@@ -0,0 +1,178 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+const launchJSON = `{
+    // Use IntelliSense to learn about possible attributes.
+    "version": "0.2.0",
+    "configurations": [
+        {
+            "name": "All tests", /* the default */
+            "type": "go",
+            "args": ["-test.v", "-test.run", "TestGet",],
+        },
+        /* {
+            "name": "disabled",
+        }, */
+        {
+            "name": "// not a comment",
+            "env": {}
+        },
+    ],
+}
+`
+
+func TestGetJSONC(t *testing.T) {
+	doc := jsonptr.JSONC(launchJSON)
+	for _, test := range []struct {
+		ptr      string
+		expected interface{}
+	}{
+		{"/version", "0.2.0"},
+		{"/configurations/0/name", "All tests"},
+		{"/configurations/0/args/2", "TestGet"},
+		{"/configurations/0/args", []interface{}{"-test.v", "-test.run", "TestGet"}},
+		{"/configurations/1/name", "// not a comment"},
+		{"/configurations/1/env", map[string]interface{}{}},
+	} {
+		got, err := jsonptr.Get(doc, test.ptr)
+		if err != nil {
+			t.Errorf("%q: %v", test.ptr, err)
+		} else if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%q: got %#v", test.ptr, got)
+		}
+	}
+
+	if _, err := jsonptr.Get(doc, "/configurations/2"); err == nil {
+		t.Error("error expected")
+	}
+	root, err := jsonptr.Get(doc, "")
+	if m, ok := root.(map[string]interface{}); !ok || len(m) != 2 {
+		t.Errorf("got %#v, %v", root, err)
+	}
+	got, err := jsonptr.MustParse("/cfg/version").In(map[string]interface{}{"cfg": doc})
+	if err != nil || got != "0.2.0" {
+		t.Errorf("In: got %#v, %v", got, err)
+	}
+
+	raw, err := doc.GetRaw("/configurations/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := strings.Index(launchJSON, `{
+            "name": "All`)
+	end := strings.Index(launchJSON, `/* {`) - len(",\n        ")
+	if expected := launchJSON[start:end]; string(raw) != expected {
+		t.Errorf("GetRaw: got %s\nexpected %s", raw, expected)
+	}
+
+	for _, bad := range []string{
+		`[1,,]`,
+		`[,]`,
+		`{,}`,
+		`{"a":,}`,
+		`[1 /* unterminated`,
+		`[1 / 2]`,
+	} {
+		if v, err := jsonptr.Get(jsonptr.JSONC(bad), ""); err == nil {
+			t.Errorf("%s: error expected, got %#v", bad, v)
+		} else if _, ok := err.(*jsonptr.DocumentError); !ok {
+			t.Errorf("%s: DocumentError expected, got %T", bad, err)
+		}
+	}
+}
+
+func TestJSONCSet(t *testing.T) {
+	for _, test := range []struct {
+		doc      string
+		ptr      string
+		value    interface{}
+		expected string
+	}{
+		{`1 // one`, ``, 2, `2 // one`},
+		{`{"a": 1, // one` + "\n}", `/a`, 2, `{"a": 2, // one` + "\n}"},
+		{`{"a": 1, // one` + "\n}", `/b`, 2, `{"a": 1,"b":2, // one` + "\n}"},
+		{`{"a": 1 /* one */}`, `/b`, 2, `{"a": 1,"b":2 /* one */}`},
+		{`{ /* empty */ }`, `/a~1b`, "<", `{"a/b":"\u003c" /* empty */ }`},
+		{`{"a": [1, 2,]}`, `/a/-`, 3, `{"a": [1, 2,3,]}`},
+		{`{"a": [1, 2]}`, `/a/2`, 3, `{"a": [1, 2,3]}`},
+		{`{"a": [1, 2]}`, `/a/0`, []int{0}, `{"a": [[0], 2]}`},
+		{`{"a": [/* none */]}`, `/a/0`, 1, `{"a": [1/* none */]}`},
+		{`{"a": 1, "a": 2}`, `/a`, 3, `{"a": 1, "a": 3}`},
+	} {
+		got, err := jsonptr.JSONC(test.doc).Set(test.ptr, test.value)
+		if err != nil {
+			t.Errorf("%s %q: %v", test.doc, test.ptr, err)
+		} else if string(got) != test.expected {
+			t.Errorf("%s %q:\ngot      %s\nexpected %s", test.doc, test.ptr, got, test.expected)
+		}
+	}
+
+	for _, test := range []struct {
+		doc string
+		ptr string
+		err error
+	}{
+		{`{"a": [1, 2]}`, `/a/3`, jsonptr.ErrIndex},
+		{`{"a": [1, 2]}`, `/b/c`, jsonptr.ErrProperty},
+		{`{"a": [1, 2]}`, `/a/x`, jsonptr.ErrSyntax},
+		{`{"a": 1}`, `/a/b`, jsonptr.ErrNotContainer},
+		{`{"a": }`, `/a`, nil},
+	} {
+		got, err := jsonptr.JSONC(test.doc).Set(test.ptr, 0)
+		if err == nil {
+			t.Errorf("%s %q: error expected, got %s", test.doc, test.ptr, got)
+			continue
+		}
+		t.Logf("%s %q: %v", test.doc, test.ptr, err)
+		var e error
+		switch err := err.(type) {
+		case *jsonptr.PtrError:
+			e = err.Err
+		case *jsonptr.BadPointerError:
+			e = err.Err
+		case *jsonptr.DocumentError:
+			if test.err != nil {
+				e = err.Err
+			}
+		}
+		if e != test.err {
+			t.Errorf("%s %q: got %v, expected %v", test.doc, test.ptr, e, test.err)
+		}
+	}
+}
+
+func ExampleJSONC_Set() {
+	settings := jsonptr.JSONC(`{
+	// Editor
+	"editor.tabSize": 4,
+	"files.exclude": {
+		"**/.git": true, // hidden
+	},
+}`)
+	tabSize, _ := jsonptr.Get(settings, "/editor.tabSize")
+	fmt.Println(tabSize)
+	settings, _ = settings.Set("/editor.tabSize", 8)
+	settings, _ = settings.Set("/files.exclude/**~1vendor", true)
+	fmt.Println(string(settings))
+	// Output:
+	// 4
+	// {
+	// 	// Editor
+	// 	"editor.tabSize": 8,
+	// 	"files.exclude": {
+	// 		"**/.git": true,"**/vendor":true, // hidden
+	// 	},
+	// }
+}
@@ -0,0 +1,506 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonpath
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+type query struct {
+	relative bool // '@' instead of '$'
+	segments []segment
+}
+
+type segment struct {
+	descendant bool
+	selectors  []selector
+}
+
+type selector interface {
+	// appendNodes appends to out the nodes selected from n.
+	appendNodes(out []Node, n Node, root interface{}) []Node
+}
+
+type (
+	nameSelector     string
+	wildcardSelector struct{}
+	indexSelector    int64
+	sliceSelector    struct {
+		start, end, step int64
+		hasStart, hasEnd bool
+	}
+	filterSelector struct {
+		expr logicalExpr
+	}
+)
+
+// logicalExpr is an expression of LogicalType.
+type logicalExpr interface {
+	test(root, cur interface{}) bool
+}
+
+// valueExpr is an expression of ValueType. ok is false for Nothing.
+type valueExpr interface {
+	value(root, cur interface{}) (v interface{}, ok bool)
+}
+
+type (
+	orExpr      []logicalExpr
+	andExpr     []logicalExpr
+	notExpr     struct{ expr logicalExpr }
+	existExpr   struct{ q *query }
+	compareExpr struct {
+		op          string
+		left, right valueExpr
+	}
+	literal struct{ v interface{} }
+)
+
+// eval returns the nodes selected from cur (the root for an absolute query).
+func (q *query) eval(root, cur interface{}, ptr jsonptr.Pointer) []Node {
+	if !q.relative {
+		cur = root
+	}
+	nodes := []Node{{Ptr: ptr, Value: cur}}
+	for _, seg := range q.segments {
+		var out []Node
+		for _, n := range nodes {
+			out = seg.appendNodes(out, n, root)
+		}
+		nodes = out
+	}
+	return nodes
+}
+
+// singular reports if the query selects at most one node.
+func (q *query) singular() bool {
+	for _, seg := range q.segments {
+		if seg.descendant || len(seg.selectors) != 1 {
+			return false
+		}
+		switch seg.selectors[0].(type) {
+		case nameSelector, indexSelector:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (q *query) value(root, cur interface{}) (interface{}, bool) {
+	nodes := q.eval(root, cur, nil)
+	if len(nodes) != 1 {
+		return nil, false
+	}
+	return nodes[0].Value, true
+}
+
+func (seg *segment) appendNodes(out []Node, n Node, root interface{}) []Node {
+	for _, sel := range seg.selectors {
+		out = sel.appendNodes(out, n, root)
+	}
+	if seg.descendant {
+		eachChild(n, func(child Node) {
+			out = seg.appendNodes(out, child, root)
+		})
+	}
+	return out
+}
+
+// child returns the node of value v at token tok under n.
+func child(n Node, tok string, v interface{}) Node {
+	// Limit the capacity to force a copy of the parent pointer
+	return Node{Ptr: append(n.Ptr[:len(n.Ptr):len(n.Ptr)], tok), Value: v}
+}
+
+// eachChild calls fn with each element of an array or member of an object.
+func eachChild(n Node, fn func(child Node)) {
+	switch v := n.Value.(type) {
+	case []interface{}:
+		for i, elem := range v {
+			fn(child(n, strconv.Itoa(i), elem))
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fn(child(n, k, v[k]))
+		}
+	case *jsonptr.Object:
+		for _, k := range v.Keys() {
+			elem, _ := v.Get(k)
+			fn(child(n, k, elem))
+		}
+	}
+}
+
+func (sel nameSelector) appendNodes(out []Node, n Node, root interface{}) []Node {
+	if v, ok := lookup(n.Value, string(sel)); ok {
+		out = append(out, child(n, string(sel), v))
+	}
+	return out
+}
+
+func (wildcardSelector) appendNodes(out []Node, n Node, root interface{}) []Node {
+	eachChild(n, func(child Node) {
+		out = append(out, child)
+	})
+	return out
+}
+
+func (sel indexSelector) appendNodes(out []Node, n Node, root interface{}) []Node {
+	arr, ok := n.Value.([]interface{})
+	if !ok {
+		return out
+	}
+	i := int64(sel)
+	if i < 0 {
+		i += int64(len(arr))
+	}
+	if i >= 0 && i < int64(len(arr)) {
+		out = append(out, child(n, strconv.FormatInt(i, 10), arr[i]))
+	}
+	return out
+}
+
+func (sel sliceSelector) appendNodes(out []Node, n Node, root interface{}) []Node {
+	arr, ok := n.Value.([]interface{})
+	if !ok || sel.step == 0 {
+		return out
+	}
+	length := int64(len(arr))
+	normalize := func(i int64) int64 {
+		if i < 0 {
+			return length + i
+		}
+		return i
+	}
+	clamp := func(i, min, max int64) int64 {
+		if i < min {
+			return min
+		}
+		if i > max {
+			return max
+		}
+		return i
+	}
+	if sel.step > 0 {
+		start, end := int64(0), length
+		if sel.hasStart {
+			start = clamp(normalize(sel.start), 0, length)
+		}
+		if sel.hasEnd {
+			end = clamp(normalize(sel.end), 0, length)
+		}
+		for i := start; i < end; i += sel.step {
+			out = append(out, child(n, strconv.FormatInt(i, 10), arr[i]))
+		}
+	} else {
+		start, end := length-1, int64(-1)
+		if sel.hasStart {
+			start = clamp(normalize(sel.start), -1, length-1)
+		}
+		if sel.hasEnd {
+			end = clamp(normalize(sel.end), -1, length-1)
+		}
+		for i := start; i > end; i += sel.step {
+			out = append(out, child(n, strconv.FormatInt(i, 10), arr[i]))
+		}
+	}
+	return out
+}
+
+func (sel filterSelector) appendNodes(out []Node, n Node, root interface{}) []Node {
+	eachChild(n, func(child Node) {
+		if sel.expr.test(root, child.Value) {
+			out = append(out, child)
+		}
+	})
+	return out
+}
+
+func (e orExpr) test(root, cur interface{}) bool {
+	for _, x := range e {
+		if x.test(root, cur) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e andExpr) test(root, cur interface{}) bool {
+	for _, x := range e {
+		if !x.test(root, cur) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e notExpr) test(root, cur interface{}) bool {
+	return !e.expr.test(root, cur)
+}
+
+func (e existExpr) test(root, cur interface{}) bool {
+	return len(e.q.eval(root, cur, nil)) > 0
+}
+
+func (e literal) value(root, cur interface{}) (interface{}, bool) {
+	return e.v, true
+}
+
+func (e compareExpr) test(root, cur interface{}) bool {
+	a, aok := e.left.value(root, cur)
+	b, bok := e.right.value(root, cur)
+	switch e.op {
+	case "==":
+		return compareEqual(a, aok, b, bok)
+	case "!=":
+		return !compareEqual(a, aok, b, bok)
+	case "<":
+		return aok && bok && less(a, b)
+	case "<=":
+		return aok && bok && less(a, b) || compareEqual(a, aok, b, bok)
+	case ">":
+		return aok && bok && less(b, a)
+	default: // ">="
+		return aok && bok && less(b, a) || compareEqual(a, aok, b, bok)
+	}
+}
+
+// compareEqual implements == where an operand may be Nothing.
+func compareEqual(a interface{}, aok bool, b interface{}, bok bool) bool {
+	if !aok || !bok {
+		return aok == bok
+	}
+	return equal(a, b)
+}
+
+// less implements < which is only defined for numbers and strings.
+func less(a, b interface{}) bool {
+	if fa, ok := number(a); ok {
+		fb, ok := number(b)
+		return ok && fa < fb
+	}
+	if sa, ok := a.(string); ok {
+		sb, ok := b.(string)
+		return ok && sa < sb
+	}
+	return false
+}
+
+// Function extensions
+
+type argType int
+
+const (
+	valueType argType = iota
+	logicalType
+	nodesType
+)
+
+type function struct {
+	params []argType
+	result argType
+	call   func(f *funcExpr, args []interface{}) (interface{}, bool)
+}
+
+var functions = map[string]*function{
+	"length": {[]argType{valueType}, valueType, fnLength},
+	"count":  {[]argType{nodesType}, valueType, fnCount},
+	"match":  {[]argType{valueType, valueType}, logicalType, fnMatch},
+	"search": {[]argType{valueType, valueType}, logicalType, fnMatch},
+	"value":  {[]argType{nodesType}, valueType, fnValue},
+}
+
+// funcExpr is a function call.
+type funcExpr struct {
+	name string
+	fn   *function
+	args []interface{}  // *query, literal or *funcExpr
+	re   *regexp.Regexp // compiled regular expression, if a literal
+}
+
+// call evaluates the arguments and calls the function. NodesType arguments
+// are passed as []Node, ValueType arguments as valueArg.
+func (f *funcExpr) call(root, cur interface{}) (interface{}, bool) {
+	args := make([]interface{}, len(f.args))
+	for i, arg := range f.args {
+		if f.fn.params[i] == nodesType {
+			args[i] = arg.(*query).eval(root, cur, nil)
+		} else {
+			v, ok := arg.(valueExpr).value(root, cur)
+			args[i] = valueArg{v, ok}
+		}
+	}
+	return f.fn.call(f, args)
+}
+
+type valueArg struct {
+	v  interface{}
+	ok bool
+}
+
+func (f *funcExpr) value(root, cur interface{}) (interface{}, bool) {
+	return f.call(root, cur)
+}
+
+func (f *funcExpr) test(root, cur interface{}) bool {
+	v, _ := f.call(root, cur)
+	return v == true
+}
+
+func fnLength(f *funcExpr, args []interface{}) (interface{}, bool) {
+	switch v := args[0].(valueArg).v; v := v.(type) {
+	case string:
+		return float64(utf8.RuneCountInString(v)), true
+	case []interface{}:
+		return float64(len(v)), true
+	case map[string]interface{}:
+		return float64(len(v)), true
+	case *jsonptr.Object:
+		return float64(v.Len()), true
+	}
+	return nil, false
+}
+
+func fnCount(f *funcExpr, args []interface{}) (interface{}, bool) {
+	return float64(len(args[0].([]Node))), true
+}
+
+func fnValue(f *funcExpr, args []interface{}) (interface{}, bool) {
+	if nodes := args[0].([]Node); len(nodes) == 1 {
+		return nodes[0].Value, true
+	}
+	return nil, false
+}
+
+// fnMatch implements match() and search().
+func fnMatch(f *funcExpr, args []interface{}) (interface{}, bool) {
+	s, ok := args[0].(valueArg).v.(string)
+	if !ok {
+		return false, true
+	}
+	re := f.re
+	if re == nil {
+		pattern, ok := args[1].(valueArg).v.(string)
+		if !ok {
+			return false, true
+		}
+		var err error
+		if re, err = compileIRegexp(pattern, f.name == "match"); err != nil {
+			return false, true
+		}
+	}
+	return re.MatchString(s), true
+}
+
+// Data model helpers
+
+func lookup(obj interface{}, key string) (interface{}, bool) {
+	switch obj := obj.(type) {
+	case map[string]interface{}:
+		v, ok := obj[key]
+		return v, ok
+	case *jsonptr.Object:
+		return obj.Get(key)
+	}
+	return nil, false
+}
+
+// keys returns the member names of an object, or false if obj is not an object.
+func keys(obj interface{}) ([]string, bool) {
+	switch obj := obj.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		return keys, true
+	case *jsonptr.Object:
+		return obj.Keys(), true
+	}
+	return nil, false
+}
+
+func number(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// equal compares values of the data model.
+func equal(a, b interface{}) bool {
+	if fa, ok := number(a); ok {
+		fb, ok := number(b)
+		return ok && fa == fb
+	}
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case bool:
+		b, ok := b.(bool)
+		return ok && a == b
+	case string:
+		b, ok := b.(string)
+		return ok && a == b
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	ka, ok := keys(a)
+	if !ok {
+		return false
+	}
+	kb, ok := keys(b)
+	if !ok || len(ka) != len(kb) {
+		return false
+	}
+	for _, k := range ka {
+		vb, ok := lookup(b, k)
+		if !ok {
+			return false
+		}
+		va, _ := lookup(a, k)
+		if !equal(va, vb) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,234 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+// Package jsonpath implements JSONPath (RFC 9535) queries returning the
+// locations of the selected values as JSON Pointers, ready to be used with
+// [jsonptr.Set] or [jsonptr.Delete].
+//
+// Documents are in the data model of package [github.com/dolmen-go/jsonptr]:
+// trees of []interface{}, map[string]interface{} or *jsonptr.Object.
+// Members of a map[string]interface{} are visited in the order of their
+// names, members of a *jsonptr.Object in their order.
+//
+// The function extensions of RFC 9535 (length, count, match, search and
+// value) are supported.
+//
+// Specification: https://www.rfc-editor.org/rfc/rfc9535
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+// Path is a compiled JSONPath query.
+type Path struct {
+	src string
+	q   *query
+}
+
+// Node is a value selected by a query, with its location.
+type Node struct {
+	Ptr   jsonptr.Pointer
+	Value interface{}
+}
+
+// ErrSyntax is wrapped by *SyntaxError.
+var ErrSyntax = errors.New("invalid JSONPath query")
+
+// SyntaxError reports an invalid JSONPath query.
+type SyntaxError struct {
+	Path   string
+	Offset int // Offset of the error in Path
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("jsonpath: %s at offset %d of %q", e.Msg, e.Offset, e.Path)
+}
+
+// Unwrap returns [ErrSyntax].
+func (e *SyntaxError) Unwrap() error {
+	return ErrSyntax
+}
+
+// Parse compiles a JSONPath query.
+//
+// In case of error a *SyntaxError is returned.
+func Parse(path string) (*Path, error) {
+	p := parser{src: path}
+	q, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(path) {
+		return nil, p.errorf("unexpected %q", path[p.pos])
+	}
+	return &Path{src: path, q: q}, nil
+}
+
+// MustParse is like [Parse] but panics in case of error.
+func MustParse(path string) *Path {
+	p, err := Parse(path)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the query as given to [Parse].
+func (p *Path) String() string {
+	return p.src
+}
+
+// Query returns the nodes selected by the query in doc, in the order
+// defined by RFC 9535.
+//
+// doc may also be a serialized document accepted by [jsonptr.Get] (such as a
+// [encoding/json.RawMessage]): it is then decoded first, and a decoding error
+// is returned.
+func (p *Path) Query(doc interface{}) ([]Node, error) {
+	doc, err := jsonptr.Get(doc, "")
+	if err != nil {
+		return nil, err
+	}
+	return p.q.eval(doc, doc, jsonptr.Pointer{}), nil
+}
+
+// Pointers is like Query, but returns only the locations of the nodes.
+func (p *Path) Pointers(doc interface{}) ([]jsonptr.Pointer, error) {
+	nodes, err := p.Query(doc)
+	if err != nil {
+		return nil, err
+	}
+	ptrs := make([]jsonptr.Pointer, len(nodes))
+	for i := range nodes {
+		ptrs[i] = nodes[i].Ptr
+	}
+	return ptrs, nil
+}
+
+// NormalizedPath returns the normalized path (RFC 9535 section 2.7) of the
+// value located by ptr in doc, such as $['store']['book'][0].
+//
+// doc is needed to tell array indexes from member names. If ptr does not
+// locate a value in doc, the error from [jsonptr.Pointer.In] is returned.
+func NormalizedPath(doc interface{}, ptr jsonptr.Pointer) (string, error) {
+	if _, err := ptr.In(doc); err != nil {
+		return "", err
+	}
+	b := []byte{'$'}
+	for i, tok := range ptr {
+		parent, _ := ptr[:i].In(doc)
+		if _, isArray := parent.([]interface{}); isArray {
+			b = append(append(append(b, '['), tok...), ']')
+		} else {
+			b = appendName(append(b, '['), tok)
+			b = append(b, ']')
+		}
+	}
+	return string(b), nil
+}
+
+// appendName appends the name as a normalized string literal.
+func appendName(b []byte, name string) []byte {
+	const hex = "0123456789abcdef"
+	b = append(b, '\'')
+	for i := 0; i < len(name); i++ {
+		switch c := name[i]; c {
+		case '\'', '\\':
+			b = append(b, '\\', c)
+		case '\b':
+			b = append(b, '\\', 'b')
+		case '\f':
+			b = append(b, '\\', 'f')
+		case '\n':
+			b = append(b, '\\', 'n')
+		case '\r':
+			b = append(b, '\\', 'r')
+		case '\t':
+			b = append(b, '\\', 't')
+		default:
+			if c < 0x20 {
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&15])
+			} else {
+				b = append(b, c)
+			}
+		}
+	}
+	return append(b, '\'')
+}
+
+// ParseNormalizedPath converts a normalized path (RFC 9535 section 2.7),
+// such as $['store']['book'][0], to a JSON Pointer.
+//
+// In case of error a *SyntaxError is returned.
+func ParseNormalizedPath(path string) (jsonptr.Pointer, error) {
+	p := parser{src: path}
+	if !p.consume('$') {
+		return nil, p.errorf("'$' expected")
+	}
+	ptr := jsonptr.Pointer{}
+	for p.pos < len(path) {
+		if !p.consume('[') {
+			return nil, p.errorf("'[' expected")
+		}
+		if p.peek() == '\'' {
+			name, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			ptr = append(ptr, name)
+		} else {
+			start := p.pos
+			n, err := p.parseInt()
+			if err != nil {
+				return nil, err
+			}
+			if n < 0 {
+				p.pos = start
+				return nil, p.errorf("negative index")
+			}
+			ptr = append(ptr, strconv.FormatInt(n, 10))
+		}
+		if !p.consume(']') {
+			return nil, p.errorf("']' expected")
+		}
+	}
+	// Reject anything that is not in normalized form
+	if back := normalizedForm(ptr, path); back != path {
+		return nil, &SyntaxError{Path: path, Offset: commonPrefix(back, path), Msg: "not a normalized path"}
+	}
+	return ptr, nil
+}
+
+// normalizedForm rebuilds the normalized path from the tokens parsed from
+// path, using the brackets of path to tell indexes from names.
+func normalizedForm(ptr jsonptr.Pointer, path string) string {
+	b := []byte{'$'}
+	for _, tok := range ptr {
+		if strings.HasPrefix(path[len(b):], "['") {
+			b = appendName(append(b, '['), tok)
+		} else {
+			b = append(append(b, '['), tok...)
+		}
+		b = append(b, ']')
+		if len(b) > len(path) || path[:len(b)] != string(b) {
+			break
+		}
+	}
+	return string(b)
+}
+
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,316 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dolmen-go/jsonptr"
+	"github.com/dolmen-go/jsonptr/jsonpath"
+)
+
+// The example of RFC 9535 section 1.5
+const storeJSON = `{ "store": {
+    "book": [
+      { "category": "reference",
+        "author": "Nigel Rees",
+        "title": "Sayings of the Century",
+        "price": 8.95
+      },
+      { "category": "fiction",
+        "author": "Evelyn Waugh",
+        "title": "Sword of Honour",
+        "price": 12.99
+      },
+      { "category": "fiction",
+        "author": "Herman Melville",
+        "title": "Moby Dick",
+        "isbn": "0-553-21311-3",
+        "price": 8.99
+      },
+      { "category": "fiction",
+        "author": "J. R. R. Tolkien",
+        "title": "The Lord of the Rings",
+        "isbn": "0-395-19395-8",
+        "price": 22.99
+      }
+    ],
+    "bicycle": {
+      "color": "red",
+      "price": 399
+    }
+  }
+}`
+
+func decode(s string) interface{} {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(s), &doc); err != nil {
+		panic(err)
+	}
+	return doc
+}
+
+func pointers(t *testing.T, path string, doc interface{}) string {
+	t.Helper()
+	p, err := jsonpath.Parse(path)
+	if err != nil {
+		t.Errorf("%s: %v", path, err)
+		return ""
+	}
+	ptrs, err := p.Pointers(doc)
+	if err != nil {
+		t.Errorf("%s: %v", path, err)
+		return ""
+	}
+	s := make([]string, len(ptrs))
+	for i, ptr := range ptrs {
+		s[i] = ptr.String()
+	}
+	return strings.Join(s, " ")
+}
+
+func TestQuery(t *testing.T) {
+	store := decode(storeJSON)
+	for _, test := range []struct {
+		path     string
+		expected string
+	}{
+		{`$`, ``},
+		{`$.store.book[*].author`, `/store/book/0/author /store/book/1/author /store/book/2/author /store/book/3/author`},
+		{`$..author`, `/store/book/0/author /store/book/1/author /store/book/2/author /store/book/3/author`},
+		{`$.store.*`, `/store/bicycle /store/book`},
+		{`$.store..price`, `/store/bicycle/price /store/book/0/price /store/book/1/price /store/book/2/price /store/book/3/price`},
+		{`$..book[2]`, `/store/book/2`},
+		{`$..book[-1]`, `/store/book/3`},
+		{`$..book[0,1]`, `/store/book/0 /store/book/1`},
+		{`$..book[:2]`, `/store/book/0 /store/book/1`},
+		{`$..book[?@.isbn]`, `/store/book/2 /store/book/3`},
+		{`$..book[?@.price<10]`, `/store/book/0 /store/book/2`},
+		{`$.store.book[?@.price < 10].title`, `/store/book/0/title /store/book/2/title`},
+		{`$..book[?@.price<10 && @.category=='fiction']`, `/store/book/2`},
+		{`$..book[?!(@.price<10) || @.author == "Nigel Rees"]`, `/store/book/0 /store/book/1 /store/book/3`},
+		{`$..book[?@.price > $.store.bicycle.price]`, ``},
+		{`$..book[?@.price >= 22.99]`, `/store/book/3`},
+		{`$..book[?@.price <= 8.95]`, `/store/book/0`},
+		{`$..book[?@.price != 8.95].price`, `/store/book/1/price /store/book/2/price /store/book/3/price`},
+		{`$..book[?length(@.title) > 20]`, `/store/book/0 /store/book/3`},
+		{`$.store[?count(@.*) == 2]`, `/store/bicycle`},
+		{`$..book[?match(@.author, 'J.*')]`, `/store/book/3`},
+		{`$..book[?search(@.author, "[Mm]el")]`, `/store/book/2`},
+		{`$..book[?match(@.author, 'Mel')]`, ``},
+		{`$..book[?value(@..isbn) == '0-553-21311-3']`, `/store/book/2`},
+		{`$..*[?@.color]`, `/store/bicycle`},
+		{`$["store"]['bicycle']`, `/store/bicycle`},
+		{`$[ 'store' ] .book [ 0 , -1 ] .price`, `/store/book/0/price /store/book/3/price`},
+		{`$.store.book[0].x`, ``},
+		{`$.store.bicycle[0]`, ``},
+	} {
+		got := pointers(t, test.path, store)
+		if got != test.expected {
+			t.Errorf("%s:\ngot      %s\nexpected %s", test.path, got, test.expected)
+		}
+	}
+}
+
+func TestQuerySelectors(t *testing.T) {
+	arr := decode(`["a","b","c","d","e","f","g"]`)
+	for _, test := range []struct {
+		path     string
+		expected string
+	}{
+		{`$[1:3]`, `/1 /2`},
+		{`$[5:]`, `/5 /6`},
+		{`$[1:5:2]`, `/1 /3`},
+		{`$[5:1:-2]`, `/5 /3`},
+		{`$[::-1]`, `/6 /5 /4 /3 /2 /1 /0`},
+		{`$[-2:]`, `/5 /6`},
+		{`$[0:100]`, `/0 /1 /2 /3 /4 /5 /6`},
+		{`$[::0]`, ``},
+		{`$[7]`, ``},
+		{`$[-8]`, ``},
+		{`$[0,0]`, `/0 /0`},
+		{`$[?@ > 'd']`, `/4 /5 /6`},
+	} {
+		if got := pointers(t, test.path, arr); got != test.expected {
+			t.Errorf("%s:\ngot      %s\nexpected %s", test.path, got, test.expected)
+		}
+	}
+
+	doc := decode(`{"o": {"j": 1, "k": 2}, "a": [5, 3, [{"j": 4}, {"k": 6}]],
+		"e": [], "n": null, "t": true, "x": {"a": [1]}, "y": {"a": [1.0]}, "~/": 0}`)
+	for _, test := range []struct {
+		path     string
+		expected string
+	}{
+		{`$..j`, `/a/2/0/j /o/j`},
+		{`$..[0]`, `/a/0 /a/2/0 /x/a/0 /y/a/0`},
+		{`$.a..*`, `/a/0 /a/1 /a/2 /a/2/0 /a/2/1 /a/2/0/j /a/2/1/k`},
+		{`$['~/']`, `/~0~1`},
+		{`$[?@ == null]`, `/n`},
+		{`$[?@ == true]`, `/t`},
+		{`$[?@ == $.t]`, `/t`},
+		{`$[?@.a == $.y.a]`, `/x /y`},
+		{`$[?@.j == @.missing]`, `/a /e /n /t /x /y /~0~1`},
+		{`$[?@.z == @.missing]`, `/a /e /n /o /t /x /y /~0~1`},
+		{`$[?length(@) == 3]`, `/a`},
+		{`$[?length(@) == 0]`, `/e`},
+		{`$.a[?@ < 4]`, `/a/1`},
+		{`$[?@.*]`, `/a /o /x /y`},
+		{`$[?(@.j || @[1]) && !@[2]]`, `/o`},
+	} {
+		if got := pointers(t, test.path, doc); got != test.expected {
+			t.Errorf("%s:\ngot      %s\nexpected %s", test.path, got, test.expected)
+		}
+	}
+}
+
+func TestQueryDataModel(t *testing.T) {
+	obj := jsonptr.NewObject()
+	obj.Set("z", 1)
+	obj.Set("a", map[string]interface{}{"n": 2})
+	nodes, err := jsonpath.MustParse(`$.*`).Query(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 2 || nodes[0].Ptr.String() != "/z" || nodes[1].Ptr.String() != "/a" {
+		t.Errorf("got %v", nodes)
+	}
+	if got := pointers(t, `$[?@.n == 2]`, obj); got != "/a" {
+		t.Errorf("got %s", got)
+	}
+
+	nodes, err = jsonpath.MustParse(`$..n`).Query(json.RawMessage(`{"a":{"n":"x"}}`))
+	if err != nil || len(nodes) != 1 || nodes[0].Value != "x" {
+		t.Errorf("got %v, %v", nodes, err)
+	}
+	if _, err = jsonpath.MustParse(`$`).Query(json.RawMessage(`{`)); err == nil {
+		t.Error("error expected")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, path := range []string{
+		``,
+		`a`,
+		` $`,
+		`$ `,
+		`$.`,
+		`$..`,
+		`$.1`,
+		`$.[0]`,
+		`$[`,
+		`$[]`,
+		`$[0`,
+		`$[01]`,
+		`$[-0]`,
+		`$[9007199254740992]`,
+		`$['a]`,
+		`$['a\"']`,
+		`$["\x"]`,
+		`$["\uD800"]`,
+		"$['\x01']",
+		`$[?@.a == ]`,
+		`$[?1]`,
+		`$[?@.* == 1]`,
+		`$[?@..a == 1]`,
+		`$[?!@.a == 1]`,
+		`$[?length(@.a)]`,
+		`$[?match(@.a, 'b') == true]`,
+		`$[?count(1) == 1]`,
+		`$[?unknown(@)]`,
+		`$[?(@.a]`,
+		`$[?@.a == 01]`,
+		`$[?@.a == 1.]`,
+		`$[?@.a = 1]`,
+		`$[?@ == []]`,
+		`$[?@ == {}]`,
+	} {
+		_, err := jsonpath.Parse(path)
+		if err == nil {
+			t.Errorf("%s: error expected", path)
+			continue
+		}
+		t.Log(err)
+		if e, ok := err.(*jsonpath.SyntaxError); !ok {
+			t.Errorf("%s: SyntaxError expected, got %T", path, err)
+		} else if e.Unwrap() != jsonpath.ErrSyntax {
+			t.Errorf("%s: unwraps to %v", path, e.Unwrap())
+		}
+	}
+}
+
+func TestNormalizedPath(t *testing.T) {
+	doc := decode(`{"a": [{"b'\\": 1, "0": {"\n\u0001": true}}]}`)
+	for _, test := range []struct {
+		ptr  jsonptr.Pointer
+		path string
+	}{
+		{jsonptr.Pointer{}, `$`},
+		{jsonptr.Pointer{"a"}, `$['a']`},
+		{jsonptr.Pointer{"a", "0"}, `$['a'][0]`},
+		{jsonptr.Pointer{"a", "0", `b'\`}, `$['a'][0]['b\'\\']`},
+		{jsonptr.Pointer{"a", "0", "0"}, `$['a'][0]['0']`},
+		{jsonptr.Pointer{"a", "0", "0", "\n\x01"}, `$['a'][0]['0']['\n\u0001']`},
+	} {
+		path, err := jsonpath.NormalizedPath(doc, test.ptr)
+		if err != nil || path != test.path {
+			t.Errorf("%q: got %s, %v", test.ptr, path, err)
+			continue
+		}
+		ptr, err := jsonpath.ParseNormalizedPath(path)
+		if err != nil || !reflect.DeepEqual(ptr, test.ptr) {
+			t.Errorf("%s: got %q, %v", path, ptr, err)
+		}
+		// A normalized path is also a query selecting the value
+		if got := pointers(t, path, doc); got != test.ptr.String() {
+			t.Errorf("%s: got %s", path, got)
+		}
+	}
+
+	if _, err := jsonpath.NormalizedPath(doc, jsonptr.Pointer{"b"}); err == nil {
+		t.Error("error expected")
+	}
+
+	for _, path := range []string{``, `$.a`, `$["a"]`, `$[-1]`, `$[01]`, `$['\/']`, `$['\u0041']`, `$['a']x`, `$[0`} {
+		if _, err := jsonpath.ParseNormalizedPath(path); err == nil {
+			t.Errorf("%s: error expected", path)
+		} else {
+			t.Log(err)
+		}
+	}
+}
+
+func Example() {
+	var doc interface{}
+	_ = json.Unmarshal([]byte(storeJSON), &doc)
+
+	nodes, _ := jsonpath.MustParse(`$.store.book[?@.price < 10]`).Query(doc)
+	for _, n := range nodes {
+		fmt.Println(n.Ptr, n.Value.(map[string]interface{})["title"])
+	}
+
+	// Pointers can be used to modify the document
+	ptrs, _ := jsonpath.MustParse(`$..book[?@.isbn].price`).Pointers(doc)
+	for _, ptr := range ptrs {
+		_ = ptr.Set(&doc, 0.0)
+	}
+	prices, _ := jsonpath.MustParse(`$..price`).Query(doc)
+	for _, n := range prices {
+		path, _ := jsonpath.NormalizedPath(doc, n.Ptr)
+		fmt.Println(path, n.Value)
+	}
+	// Output:
+	// /store/book/0 Sayings of the Century
+	// /store/book/2 Moby Dick
+	// $['store']['bicycle']['price'] 399
+	// $['store']['book'][0]['price'] 8.95
+	// $['store']['book'][1]['price'] 12.99
+	// $['store']['book'][2]['price'] 0
+	// $['store']['book'][3]['price'] 0
+}
@@ -0,0 +1,608 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonpath
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// maxInt is the range of indexes allowed by RFC 9535 (I-JSON exact integers).
+const maxInt = 1<<53 - 1
+
+// parser is a recursive descent parser following the ABNF of RFC 9535.
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{Path: p.src, Offset: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// peek returns the current byte, or 0 at the end.
+func (p *parser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+// consume skips c if it is the current byte.
+func (p *parser) consume(c byte) bool {
+	if p.peek() == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// consumeString skips s if it is at the current position.
+func (p *parser) consumeString(s string) bool {
+	if strings.HasPrefix(p.src[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+// skipSpace skips blank space (S in the ABNF).
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// parseQuery parses a query starting with '$' (or '@' in a filter).
+func (p *parser) parseQuery() (*query, error) {
+	q := &query{}
+	switch {
+	case p.consume('$'):
+	case p.consume('@'):
+		q.relative = true
+	default:
+		return nil, p.errorf("'$' expected")
+	}
+	for {
+		start := p.pos
+		p.skipSpace()
+		if c := p.peek(); c != '.' && c != '[' {
+			p.pos = start
+			return q, nil
+		}
+		seg, err := p.parseSegment()
+		if err != nil {
+			return nil, err
+		}
+		q.segments = append(q.segments, seg)
+	}
+}
+
+func (p *parser) parseSegment() (segment, error) {
+	var seg segment
+	if p.consumeString("..") {
+		seg.descendant = true
+	} else if p.consume('.') {
+	} else {
+		return p.parseBracketed(seg)
+	}
+	switch c := p.peek(); {
+	case c == '[' && seg.descendant:
+		return p.parseBracketed(seg)
+	case c == '*':
+		p.pos++
+		seg.selectors = []selector{wildcardSelector{}}
+	case isNameFirst(c):
+		start := p.pos
+		for p.pos < len(p.src) && (isNameFirst(p.src[p.pos]) || isDigit(p.src[p.pos])) {
+			p.pos++
+		}
+		name := p.src[start:p.pos]
+		if !utf8.ValidString(name) {
+			p.pos = start
+			return seg, p.errorf("invalid UTF-8")
+		}
+		seg.selectors = []selector{nameSelector(name)}
+	default:
+		return seg, p.errorf("member name expected")
+	}
+	return seg, nil
+}
+
+func isNameFirst(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= 0x80
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// parseBracketed parses a list of selectors in brackets.
+func (p *parser) parseBracketed(seg segment) (segment, error) {
+	if !p.consume('[') {
+		return seg, p.errorf("'[' expected")
+	}
+	for {
+		p.skipSpace()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return seg, err
+		}
+		seg.selectors = append(seg.selectors, sel)
+		p.skipSpace()
+		if p.consume(']') {
+			return seg, nil
+		}
+		if !p.consume(',') {
+			return seg, p.errorf("',' or ']' expected")
+		}
+	}
+}
+
+func (p *parser) parseSelector() (selector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		name, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return nameSelector(name), nil
+	case c == '*':
+		p.pos++
+		return wildcardSelector{}, nil
+	case c == '?':
+		p.pos++
+		p.skipSpace()
+		expr, err := p.parseLogical()
+		if err != nil {
+			return nil, err
+		}
+		return filterSelector{expr}, nil
+	case c == ':' || c == '-' || isDigit(c):
+		return p.parseIndexOrSlice()
+	default:
+		return nil, p.errorf("selector expected")
+	}
+}
+
+func (p *parser) parseIndexOrSlice() (selector, error) {
+	var s sliceSelector
+	var err error
+	if p.peek() != ':' {
+		if s.start, err = p.parseInt(); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.peek() != ':' {
+			return indexSelector(s.start), nil
+		}
+		s.hasStart = true
+	}
+	p.pos++ // ':'
+	p.skipSpace()
+	if c := p.peek(); c == '-' || isDigit(c) {
+		if s.end, err = p.parseInt(); err != nil {
+			return nil, err
+		}
+		s.hasEnd = true
+		p.skipSpace()
+	}
+	s.step = 1
+	if p.consume(':') {
+		p.skipSpace()
+		if c := p.peek(); c == '-' || isDigit(c) {
+			if s.step, err = p.parseInt(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
+// parseInt parses an integer in the range of I-JSON, without leading zeros.
+func (p *parser) parseInt() (int64, error) {
+	start := p.pos
+	p.consume('-')
+	digits := p.pos
+	for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+		p.pos++
+	}
+	s := p.src[start:p.pos]
+	switch {
+	case p.pos == digits:
+		return 0, p.errorf("integer expected")
+	case p.src[digits] == '0' && (p.pos > digits+1 || digits > start):
+		p.pos = start
+		return 0, p.errorf("invalid integer %q", s)
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n > maxInt || n < -maxInt {
+		p.pos = start
+		return 0, p.errorf("integer %s out of range", s)
+	}
+	return n, nil
+}
+
+// parseString parses a string literal, in single or double quotes.
+func (p *parser) parseString() (string, error) {
+	quote := p.src[p.pos]
+	p.pos++
+	var b []byte
+	for {
+		if p.pos >= len(p.src) {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.src[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			if !utf8.Valid(b) {
+				return "", p.errorf("invalid UTF-8")
+			}
+			return string(b), nil
+		case c < 0x20:
+			return "", p.errorf("control character in string")
+		case c != '\\':
+			b = append(b, c)
+			p.pos++
+			continue
+		}
+		// Escape sequence
+		p.pos++
+		switch c = p.peek(); c {
+		case 'b':
+			b = append(b, '\b')
+		case 'f':
+			b = append(b, '\f')
+		case 'n':
+			b = append(b, '\n')
+		case 'r':
+			b = append(b, '\r')
+		case 't':
+			b = append(b, '\t')
+		case '/', '\\':
+			b = append(b, c)
+		case '\'', '"':
+			if c != quote {
+				return "", p.errorf("invalid escape")
+			}
+			b = append(b, c)
+		case 'u':
+			r, err := p.parseHex4()
+			if err != nil {
+				return "", err
+			}
+			if utf16.IsSurrogate(r) {
+				if r >= 0xdc00 || !p.consumeString(`\u`) {
+					return "", p.errorf("invalid surrogate")
+				}
+				p.pos--
+				r2, err := p.parseHex4()
+				if err != nil {
+					return "", err
+				}
+				if r = utf16.DecodeRune(r, r2); r == utf8.RuneError {
+					return "", p.errorf("invalid surrogate")
+				}
+			}
+			b = append(b, string(r)...)
+			continue
+		default:
+			return "", p.errorf("invalid escape")
+		}
+		p.pos++
+	}
+}
+
+// parseHex4 parses the 4 hex digits following the 'u' at the current position.
+func (p *parser) parseHex4() (rune, error) {
+	if p.pos+5 > len(p.src) {
+		return 0, p.errorf("invalid escape")
+	}
+	n, err := strconv.ParseUint(p.src[p.pos+1:p.pos+5], 16, 16)
+	if err != nil {
+		return 0, p.errorf("invalid escape")
+	}
+	p.pos += 5
+	return rune(n), nil
+}
+
+// Filter expressions
+
+// parseLogical parses a logical-or-expr.
+func (p *parser) parseLogical() (logicalExpr, error) {
+	var or orExpr
+	for {
+		var and andExpr
+		for {
+			e, err := p.parseBasic()
+			if err != nil {
+				return nil, err
+			}
+			and = append(and, e)
+			start := p.pos
+			p.skipSpace()
+			if !p.consumeString("&&") {
+				p.pos = start
+				break
+			}
+			p.skipSpace()
+		}
+		if len(and) == 1 {
+			or = append(or, and[0])
+		} else {
+			or = append(or, and)
+		}
+		start := p.pos
+		p.skipSpace()
+		if !p.consumeString("||") {
+			p.pos = start
+			break
+		}
+		p.skipSpace()
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+// parseBasic parses a basic-expr: a parenthesized expression, a comparison
+// or a test.
+func (p *parser) parseBasic() (logicalExpr, error) {
+	if p.consume('!') {
+		p.skipSpace()
+		if p.peek() == '(' {
+			e, err := p.parseBasic()
+			if err != nil {
+				return nil, err
+			}
+			return notExpr{e}, nil
+		}
+		start := p.pos
+		e, err := p.parseBasic()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := e.(compareExpr); ok {
+			p.pos = start
+			return nil, p.errorf("comparison must be in parentheses to be negated")
+		}
+		return notExpr{e}, nil
+	}
+	if p.consume('(') {
+		p.skipSpace()
+		e, err := p.parseLogical()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.consume(')') {
+			return nil, p.errorf("')' expected")
+		}
+		return e, nil
+	}
+
+	start := p.pos
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	afterLeft := p.pos
+	p.skipSpace()
+	op := p.parseCompareOp()
+	if op == "" {
+		p.pos = afterLeft
+		switch left := left.(type) {
+		case *query:
+			return existExpr{left}, nil
+		case *funcExpr:
+			if left.fn.result == logicalType {
+				return left, nil
+			}
+		}
+		p.pos = start
+		return nil, p.errorf("test expression expected")
+	}
+	p.skipSpace()
+	if err := p.checkComparable(left, start); err != nil {
+		return nil, err
+	}
+	start = p.pos
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkComparable(right, start); err != nil {
+		return nil, err
+	}
+	return compareExpr{op: op, left: left.(valueExpr), right: right.(valueExpr)}, nil
+}
+
+func (p *parser) parseCompareOp() string {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consumeString(op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// checkComparable checks that the operand parsed at start is allowed in
+// a comparison: a literal, a singular query or a function returning a value.
+func (p *parser) checkComparable(e interface{}, start int) error {
+	switch e := e.(type) {
+	case *query:
+		if !e.singular() {
+			p.pos = start
+			return p.errorf("non-singular query in comparison")
+		}
+	case *funcExpr:
+		if e.fn.result != valueType {
+			p.pos = start
+			return p.errorf("function %s() has no value", e.name)
+		}
+	}
+	return nil
+}
+
+// parseOperand parses a literal, a query or a function call.
+func (p *parser) parseOperand() (interface{}, error) {
+	switch c := p.peek(); {
+	case c == '$' || c == '@':
+		return p.parseQuery()
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return literal{s}, nil
+	case c == '-' || isDigit(c):
+		return p.parseNumber()
+	case p.consumeString("true"):
+		return literal{true}, nil
+	case p.consumeString("false"):
+		return literal{false}, nil
+	case p.consumeString("null"):
+		return literal{nil}, nil
+	case c >= 'a' && c <= 'z':
+		return p.parseFunction()
+	default:
+		return nil, p.errorf("expression expected")
+	}
+}
+
+func (p *parser) parseNumber() (literal, error) {
+	start := p.pos
+	p.consume('-')
+	digits := p.pos
+	for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+		p.pos++
+	}
+	if p.pos == digits || p.src[digits] == '0' && p.pos > digits+1 {
+		p.pos = start
+		return literal{}, p.errorf("invalid number")
+	}
+	if p.consume('.') {
+		frac := p.pos
+		for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+			p.pos++
+		}
+		if p.pos == frac {
+			return literal{}, p.errorf("digit expected")
+		}
+	}
+	if p.consume('e') || p.consume('E') {
+		if !p.consume('-') {
+			p.consume('+')
+		}
+		exp := p.pos
+		for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+			p.pos++
+		}
+		if p.pos == exp {
+			return literal{}, p.errorf("digit expected")
+		}
+	}
+	f, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		return literal{}, p.errorf("invalid number")
+	}
+	return literal{f}, nil
+}
+
+func (p *parser) parseFunction() (*funcExpr, error) {
+	start := p.pos
+	for p.pos < len(p.src) && (p.src[p.pos] >= 'a' && p.src[p.pos] <= 'z' || p.src[p.pos] == '_' || isDigit(p.src[p.pos])) {
+		p.pos++
+	}
+	name := p.src[start:p.pos]
+	fn, ok := functions[name]
+	if !ok {
+		p.pos = start
+		return nil, p.errorf("unknown function %s()", name)
+	}
+	if !p.consume('(') {
+		return nil, p.errorf("'(' expected")
+	}
+	f := &funcExpr{name: name, fn: fn}
+	for i, param := range fn.params {
+		p.skipSpace()
+		if i > 0 && !p.consume(',') {
+			return nil, p.errorf("',' expected")
+		}
+		p.skipSpace()
+		argStart := p.pos
+		arg, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		switch param {
+		case valueType:
+			if err := p.checkComparable(arg, argStart); err != nil {
+				return nil, err
+			}
+		case nodesType:
+			if _, ok := arg.(*query); !ok {
+				p.pos = argStart
+				return nil, p.errorf("query expected as argument of %s()", name)
+			}
+		}
+		f.args = append(f.args, arg)
+	}
+	p.skipSpace()
+	if !p.consume(')') {
+		return nil, p.errorf("')' expected")
+	}
+	// Compile the regular expression once if it is a literal
+	if len(f.args) == 2 {
+		if re, ok := f.args[1].(literal); ok {
+			if s, ok := re.v.(string); ok {
+				f.re, _ = compileIRegexp(s, name == "match")
+			}
+		}
+	}
+	return f, nil
+}
+
+// compileIRegexp compiles an I-Regexp (RFC 9485) as a Go regular expression.
+// If full is true, the whole string must match.
+func compileIRegexp(re string, full bool) (*regexp.Regexp, error) {
+	// The only difference we handle is '.', which doesn't match \r in I-Regexp
+	var b strings.Builder
+	inClass := false
+	for i := 0; i < len(re); i++ {
+		switch c := re[i]; {
+		case c == '\\' && i+1 < len(re):
+			b.WriteString(re[i : i+2])
+			i++
+		case c == '[':
+			inClass = true
+			b.WriteByte(c)
+		case c == ']':
+			inClass = false
+			b.WriteByte(c)
+		case c == '.' && !inClass:
+			b.WriteString(`[^\n\r]`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	s := b.String()
+	if full {
+		s = `^(?:` + s + `)$`
+	}
+	return regexp.Compile(s)
+}
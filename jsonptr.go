@@ -13,6 +13,7 @@ package jsonptr
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"strconv"
 	"strings"
 )
@@ -63,11 +64,39 @@ type JSONDecoder interface {
 	Decode(interface{}) error
 }
 
-func getJSON(decoder JSONDecoder, ptr string) (interface{}, ptrError) {
+// skipValue reads the next value from decoder and drops it. The value is
+// read token by token, so containers are never buffered whole.
+func skipValue(decoder JSONDecoder) error {
+	depth := 0
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+func getJSON(decoder JSONDecoder, ptr string, opts *Options) (interface{}, ptrError) {
 	//log.Println("[", ptr, "]")
 
 	p := int(1)
 	cur := ptr[1:]
+	depth := 0
+	// With limits, values are read token by token to stop as soon as a
+	// limit is exceeded
+	limited := opts.maxDepth() > 0 || opts.maxBytes() > 0
 	for {
 		q := strings.IndexByte(cur, '/')
 		if q != -1 {
@@ -85,14 +114,26 @@ func getJSON(decoder JSONDecoder, ptr string) (interface{}, ptrError) {
 		if !ok {
 			return nil, docError(ptr[:p-q-1], tok)
 		}
+		depth++
+		if opts.depthExceeded(nil, depth) {
+			return nil, limitError(ptr[:p-q-1])
+		}
 		switch delim {
 		case '{':
 			key, err := UnescapeString(cur)
 			if err != nil {
-				return nil, &BadPointerError{ptr[:p], err}
+				return nil, tokenError(ptr, p, err)
+			}
+			// As json.Unmarshal keeps the last value in case of duplicate
+			// keys, we have to read the whole object to find the last one.
+			var seen map[string]bool
+			if opts.disallowDuplicateKeys() {
+				seen = make(map[string]bool)
 			}
+			var value json.RawMessage
+			var keys []string
 			found := false
-			for {
+			for decoder.More() {
 				tok, err := decoder.Token()
 				if err != nil {
 					return nil, jsonError(ptr[:p], err)
@@ -102,30 +143,61 @@ func getJSON(decoder JSONDecoder, ptr string) (interface{}, ptrError) {
 					// This should not happen
 					panic("unexpected key type")
 				}
-				if !decoder.More() {
-					panic("unexpected missing value in object")
+				if seen != nil {
+					if seen[k] {
+						return nil, duplicateKeyError(ptr[:p-q] + EscapeString(k))
+					}
+					seen[k] = true
 				}
-				if k == key {
-					found = true
-					break
+				var raw json.RawMessage
+				if limited {
+					var perr ptrError
+					if raw, perr = readLimited(decoder, ptr[:p-q]+EscapeString(k), depth, k == key, opts); perr != nil {
+						return nil, perr
+					}
+				} else if k != key {
+					err = skipValue(decoder)
+				} else {
+					err = decoder.Decode(&raw)
 				}
-				// skip value
-				var skip json.RawMessage
-				err = decoder.Decode(&skip)
 				if err != nil {
 					return nil, jsonError(ptr[:p], err)
 				}
+				if k == key {
+					found = true
+					value = raw
+				} else if !found {
+					keys = append(keys, k)
+				}
+			}
+			// Consume '}'
+			if _, err = decoder.Token(); err != nil {
+				return nil, jsonError(ptr[:p], err)
+			}
+			if opts.bytesExceeded(decoder) {
+				return nil, limitError(ptr[:p-q-1])
 			}
 			if !found {
-				return nil, propertyError(ptr[:p])
+				return nil, propertyError(ptr[:p], key, keys)
+			}
+			var v interface{}
+			var perr ptrError
+			if p >= len(ptr) {
+				v, perr = getLeaf(value, opts.nested(depth))
+			} else {
+				v, perr = getRaw(value, ptr[p:], opts.nested(depth))
+			}
+			if perr != nil {
+				perr.rebase(ptr[:p])
 			}
+			return v, perr
 		case '[':
 			n, err := arrayIndex(cur)
 			if err != nil {
-				return nil, &BadPointerError{ptr[:p], err}
+				return nil, tokenError(ptr, p, err)
 			}
 			if n < 0 {
-				return nil, indexError(ptr[:p])
+				return nil, indexError(ptr[:p], -1)
 			}
 			i := -1
 			for decoder.More() {
@@ -134,14 +206,18 @@ func getJSON(decoder JSONDecoder, ptr string) (interface{}, ptrError) {
 					// Continue deeper in the structure
 					break
 				}
-				var skip json.RawMessage
-				err = decoder.Decode(&skip)
-				if err != nil {
+				if limited {
+					if _, perr := readLimited(decoder, ptr[:p-q]+strconv.Itoa(i), depth, false, opts); perr != nil {
+						return nil, perr
+					}
+					continue
+				}
+				if err = skipValue(decoder); err != nil {
 					return nil, jsonError(ptr[:p], err)
 				}
 			}
 			if i < n {
-				return nil, indexError(ptr[:p])
+				return nil, indexError(ptr[:p], i+1)
 			}
 		}
 
@@ -152,63 +228,214 @@ func getJSON(decoder JSONDecoder, ptr string) (interface{}, ptrError) {
 		cur = ptr[p:]
 	}
 
-	var value interface{}
-	if err := decoder.Decode(&value); err != nil {
-		return nil, jsonError(ptr, err)
+	v, err := getLeaf(decoder, opts.nested(depth))
+	if err != nil {
+		err.rebase(ptr)
 	}
-	return value, nil
+	return v, err
 }
 
-func getRaw(doc json.RawMessage, ptr string) (interface{}, ptrError) {
-	/*
-		if len(ptr) == 0 {
-			var value interface{}
-			if err := json.Unmarshal(doc, &value); err != nil {
-				return nil, jsonError(ptr, err)
+// readLimited reads the next value from decoder token by token, checking
+// MaxDepth and MaxBytes after each token, so that reading stops as soon as
+// a limit is exceeded. If keep is true, the value is returned, encoded
+// again from its tokens. depth is the nesting level of the value and ptr
+// its location, for errors.
+func readLimited(decoder JSONDecoder, ptr string, depth int, keep bool, opts *Options) (json.RawMessage, ptrError) {
+	var raw json.RawMessage
+	var stack []bool // open containers: true for objects
+	key := false     // the next token is an object key
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
 			}
-			return value, nil
+			return nil, jsonError(ptr, err)
 		}
-		if ptr[0] != '/' {
-			return nil, syntaxError(ptr)
+		if opts.bytesExceeded(decoder) {
+			return nil, limitError(ptr)
 		}
-	*/
-
-	return getJSON(json.NewDecoder(bytes.NewReader(doc)), ptr)
+		if keep {
+			if n := len(raw); n > 0 && raw[n-1] != '{' && raw[n-1] != '[' && raw[n-1] != ':' &&
+				tok != json.Delim('}') && tok != json.Delim(']') {
+				raw = append(raw, ',')
+			}
+			if delim, ok := tok.(json.Delim); ok {
+				raw = append(raw, byte(delim))
+			} else {
+				b, _ := json.Marshal(tok)
+				raw = append(raw, b...)
+			}
+			if key && tok != json.Delim('}') {
+				raw = append(raw, ':')
+			}
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			stack = append(stack, tok == json.Delim('{'))
+			if opts.depthExceeded(nil, depth+len(stack)) {
+				return nil, limitError(ptr)
+			}
+			key = tok == json.Delim('{')
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			key = len(stack) > 0 && stack[len(stack)-1]
+		default:
+			key = !key && len(stack) > 0 && stack[len(stack)-1]
+		}
+		if len(stack) == 0 {
+			return raw, nil
+		}
+	}
 }
 
-func getLeaf(doc interface{}) (interface{}, ptrError) {
-	var err error
+func getRaw(doc json.RawMessage, ptr string, opts *Options) (interface{}, ptrError) {
+	truncated := false
+	if max := opts.maxBytes(); max > 0 && int64(len(doc)) > max {
+		// Anything beyond the limit will look like a syntax error
+		doc = doc[:max]
+		truncated = true
+	}
+	if opts.depthExceeded(doc, 0) {
+		return nil, limitError("")
+	}
+	start, end, err := locateRaw(doc, ptr, opts)
+	if err != nil {
+		return nil, err
+	}
+	if truncated && (start < 0 || end == len(doc)) {
+		return nil, limitError("")
+	}
+	if start < 0 {
+		// Malformed document: use the decoder to report the error
+		return getJSON(json.NewDecoder(bytes.NewReader(doc)), ptr, opts)
+	}
+	v, err := getLeaf(doc[start:end], opts)
+	if err != nil {
+		err.rebase(ptr)
+	}
+	return v, err
+}
 
+func getLeaf(doc interface{}, opts *Options) (interface{}, ptrError) {
 	switch raw := doc.(type) {
 	case json.RawMessage:
-		doc = nil
-		err = json.Unmarshal(raw, &doc)
+		if opts.depthExceeded(raw, 0) {
+			return nil, limitError("")
+		}
+		if opts.disallowDuplicateKeys() {
+			if err := checkDuplicateKeys(json.NewDecoder(bytes.NewReader(raw)), ""); err != nil {
+				return nil, err
+			}
+		}
+		return decodeLeaf(func(v interface{}) error { return json.Unmarshal(raw, v) })
 	case JSONDecoder:
-		doc = nil
-		err = raw.Decode(&doc)
+		if opts.maxDepth() > 0 || opts.maxBytes() > 0 {
+			value, err := readLimited(raw, "", 0, true, opts)
+			if err != nil {
+				return nil, err
+			}
+			return getLeaf(value, opts)
+		}
+		if opts.disallowDuplicateKeys() {
+			var value json.RawMessage
+			if err := raw.Decode(&value); err != nil {
+				return nil, jsonError("", err)
+			}
+			return getLeaf(value, opts)
+		}
+		return decodeLeaf(raw.Decode)
+	case JSONC:
+		return getLeaf(raw.clean(), opts)
+	case CBOR:
+		return getCBOR(raw, "", opts)
 	default:
 		return doc, nil
 	}
-	if err != nil {
+}
+
+// decodeLeaf decodes a value with decode. The variable decoded into escapes
+// to the heap: it is kept out of getLeaf so that values of the deserialized
+// data model are returned without allocation.
+func decodeLeaf(decode func(interface{}) error) (interface{}, ptrError) {
+	var v interface{}
+	if err := decode(&v); err != nil {
 		return nil, jsonError("", err)
 	}
-	return doc, nil
+	return v, nil
+}
+
+// checkDuplicateKeys reads the next value from decoder and reports the first
+// duplicate key found in an object.
+func checkDuplicateKeys(decoder JSONDecoder, ptr string) ptrError {
+	tok, err := decoder.Token()
+	if err != nil {
+		return jsonError(ptr, err)
+	}
+	switch tok {
+	case json.Delim('{'):
+		seen := make(map[string]bool)
+		for decoder.More() {
+			tok, err := decoder.Token()
+			if err != nil {
+				return jsonError(ptr, err)
+			}
+			k := tok.(string)
+			if seen[k] {
+				return duplicateKeyError(ptr + "/" + EscapeString(k))
+			}
+			seen[k] = true
+			if err := checkDuplicateKeys(decoder, ptr+"/"+EscapeString(k)); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		for i := 0; decoder.More(); i++ {
+			if err := checkDuplicateKeys(decoder, ptr+"/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+	default:
+		return nil
+	}
+	// Consume the closing delimiter
+	if _, err := decoder.Token(); err != nil {
+		return jsonError(ptr, err)
+	}
+	return nil
 }
 
 // Get extracts a value from a JSON-like data tree.
 //
 // doc may be:
-//   - a deserialized document made of []interface{}, map[string]interface{} or any terminal value
+//   - a deserialized document made of []interface{}, map[string]interface{}, *[Object] or any terminal value
+//   - containers of common concrete types, handled without reflection: []string, []bool, []int,
+//     []float64, []map[string]interface{}, map[string]string, map[string]bool, map[string]int,
+//     map[string]float64, map[string][]interface{}, map[string]map[string]interface{}
 //   - a [encoding/json.RawMessage]
 //   - a JSONDecoder (such as *[encoding/json.Decoder]) for streamed decoding
+//   - a [JSONC] document (JSON with comments)
+//   - a [CBOR] document
+//
+// In case of duplicate keys in a serialized object, the last value wins,
+// like with [encoding/json.Unmarshal]. See [Options] for a strict mode.
+//
+// With a JSONDecoder, the values out of the path of the pointer are skipped
+// token by token. But as a later duplicate key would replace it, the value
+// of a property on the path is buffered until the end of its object, and
+// the extracted value is decoded whole.
 //
 // In case of error a PtrError is returned.
 func Get(doc interface{}, ptr string) (interface{}, error) {
-	if len(ptr) == 0 {
-		return getLeaf(doc)
+	return get(doc, ptr, nil)
+}
+
+func get(doc interface{}, ptr string, opts *Options) (interface{}, error) {
+	if err := opts.checkPointer(ptr); err != nil {
+		return nil, err
 	}
-	if ptr[0] != '/' {
-		return nil, syntaxError(ptr)
+	if len(ptr) == 0 {
+		return getLeaf(doc, opts)
 	}
 	cur := ptr[1:]
 	p := int(1)
@@ -223,35 +450,77 @@ func Get(doc interface{}, ptr string) (interface{}, error) {
 		case map[string]interface{}:
 			key, err := UnescapeString(cur[:q])
 			if err != nil {
-				return nil, &BadPointerError{ptr[:p], err}
+				return nil, tokenError(ptr, p, err)
 			}
 			var ok bool
 			if doc, ok = here[key]; !ok {
-				return nil, propertyError(ptr[:p])
+				return nil, propertyError(ptr[:p], key, mapKeys(here))
+			}
+		case *Object:
+			key, err := UnescapeString(cur[:q])
+			if err != nil {
+				return nil, tokenError(ptr, p, err)
+			}
+			var ok bool
+			if doc, ok = here.Get(key); !ok {
+				return nil, propertyError(ptr[:p], key, here.keys)
 			}
 		case []interface{}:
 			n, err := arrayIndex(cur[:q])
 			if err != nil {
-				return nil, &BadPointerError{ptr[:p], err}
+				return nil, tokenError(ptr, p, err)
 			}
 			if n < 0 || n >= len(here) {
-				return nil, indexError(ptr[:p])
+				return nil, indexError(ptr[:p], len(here))
 			}
 			doc = here[n]
 		case JSONDecoder:
-			v, err := getJSON(here, ptr[p-q-1:])
-			if perr, ok := err.(*PtrError); ok {
-				perr.Ptr = ptr[:p-q-1+len(perr.Ptr)]
+			v, err := getJSON(here, ptr[p-q-1:], opts)
+			if err != nil {
+				err.rebase(ptr[:p-q-1])
 			}
 			return v, err
 		case json.RawMessage:
-			v, err := getRaw(here, ptr[p-q-1:])
-			if perr, ok := err.(*PtrError); ok {
-				perr.Ptr = ptr[:p-q-1+len(perr.Ptr)]
+			v, err := getRaw(here, ptr[p-q-1:], opts)
+			if err != nil {
+				err.rebase(ptr[:p-q-1])
+			}
+			return v, err
+		case JSONC:
+			v, err := getRaw(here.clean(), ptr[p-q-1:], opts)
+			if err != nil {
+				err.rebase(ptr[:p-q-1])
+			}
+			return v, err
+		case CBOR:
+			v, err := getCBOR(here, ptr[p-q-1:], opts)
+			if err != nil {
+				err.rebase(ptr[:p-q-1])
 			}
 			return v, err
 		default:
-			return nil, docError(ptr[:p], doc)
+			switch typedKind(here) {
+			case typedObject:
+				key, err := UnescapeString(cur[:q])
+				if err != nil {
+					return nil, tokenError(ptr, p, err)
+				}
+				var ok bool
+				if doc, ok = getTypedKey(here, key); !ok {
+					return nil, propertyError(ptr[:p], key, typedKeys(here))
+				}
+			case typedArray:
+				n, err := arrayIndex(cur[:q])
+				if err != nil {
+					return nil, tokenError(ptr, p, err)
+				}
+				var ok bool
+				if doc, ok = getTypedIndex(here, n); !ok {
+					return nil, indexError(ptr[:p], typedLen(here))
+				}
+			default:
+				return nil, docError(ptr[:p-q-1], doc)
+			}
 		}
 		if p >= len(ptr) {
 			break
@@ -260,7 +529,7 @@ func Get(doc interface{}, ptr string) (interface{}, error) {
 		cur = ptr[p:]
 	}
 
-	doc, err := getLeaf(doc)
+	doc, err := getLeaf(doc, opts)
 	if err != nil {
 		err.rebase(ptr)
 	}
@@ -269,8 +538,20 @@ func Get(doc interface{}, ptr string) (interface{}, error) {
 
 // Set modifies a JSON-like data tree.
 //
+// In a container of a concrete type (see [Get]), value must have the type of
+// the elements (nil is allowed for slices and maps), otherwise a
+// *DocumentError wrapping [ErrElemType] is returned. Arrays grow with zero
+// values.
+//
 // In case of error a PtrError is returned.
 func Set(doc *interface{}, ptr string, value interface{}) error {
+	if err := checkSyntax(ptr); err != nil {
+		return err
+	}
+	return set(doc, ptr, value, nil)
+}
+
+func set(doc *interface{}, ptr string, value interface{}, opts *Options) error {
 	if len(ptr) == 0 {
 		*doc = value
 		return nil
@@ -282,7 +563,7 @@ func Set(doc *interface{}, ptr string, value interface{}) error {
 	prop := ptr[p+1:]
 	parentPtr := ptr[:p]
 
-	parent, err := Get(*doc, parentPtr)
+	parent, err := get(*doc, parentPtr, opts)
 	if err != nil {
 		return err
 	}
@@ -294,21 +575,37 @@ func Set(doc *interface{}, ptr string, value interface{}) error {
 	case map[string]interface{}:
 		key, err := UnescapeString(prop)
 		if err != nil {
-			return &BadPointerError{ptr, err}
+			return tokenError(ptr, len(ptr), err)
 		}
 		if parent != nil {
 			parent[key] = value
 		} else {
-			return Set(doc, parentPtr, map[string]interface{}{key: value})
+			return set(doc, parentPtr, map[string]interface{}{key: value}, opts)
+		}
+	case *Object:
+		key, err := UnescapeString(prop)
+		if err != nil {
+			return tokenError(ptr, len(ptr), err)
+		}
+		if parent != nil {
+			parent.Set(key, value)
+		} else {
+			obj := NewObject()
+			obj.Set(key, value)
+			return set(doc, parentPtr, obj, opts)
 		}
 	case []interface{}:
 		n, err := arrayIndex(prop)
 		if err != nil {
-			return &BadPointerError{ptr, err}
+			return tokenError(ptr, len(ptr), err)
 		}
 		if n == -1 {
 			n = len(parent)
-		} else if n < len(parent) {
+		}
+		if max := opts.maxIndex(); max > 0 && n > max {
+			return limitPtrError(ptr)
+		}
+		if n < len(parent) {
 			parent[n] = value
 			return nil
 		}
@@ -325,9 +622,40 @@ func Set(doc *interface{}, ptr string, value interface{}) error {
 		// We appended beyond original len, so the slice changed so we have to
 		// store the new one at the old place
 		// No error can happen as we already parsed the pointer
-		_ = Set(doc, parentPtr, parent)
+		_ = set(doc, parentPtr, parent, opts)
 	default:
-		return docError(parentPtr, parent)
+		var replace interface{}
+		var ok bool
+		switch typedKind(parent) {
+		case typedObject:
+			key, err := UnescapeString(prop)
+			if err != nil {
+				return tokenError(ptr, len(ptr), err)
+			}
+			replace, ok = setTypedKey(parent, key, value)
+		case typedArray:
+			n, err := arrayIndex(prop)
+			if err != nil {
+				return tokenError(ptr, len(ptr), err)
+			}
+			if n == -1 {
+				n = typedLen(parent)
+			}
+			if max := opts.maxIndex(); max > 0 && n > max {
+				return limitPtrError(ptr)
+			}
+			replace, ok = setTypedIndex(parent, n, value)
+		default:
+			return docError(parentPtr, parent)
+		}
+		if !ok {
+			return elemTypeError(ptr, parent)
+		}
+		// A nil map was replaced, or the array has grown
+		if replace != nil {
+			// No error can happen as we already parsed the pointer
+			_ = set(doc, parentPtr, replace, opts)
+		}
 	}
 
 	return nil
@@ -336,8 +664,15 @@ func Set(doc *interface{}, ptr string, value interface{}) error {
 // Delete removes an object property or an array element (and shifts remaining ones).
 // It can't be applied on root.
 func Delete(pdoc *interface{}, ptr string) (interface{}, error) {
+	if err := checkSyntax(ptr); err != nil {
+		return nil, err
+	}
+	return remove(pdoc, ptr, nil)
+}
+
+func remove(pdoc *interface{}, ptr string, opts *Options) (interface{}, error) {
 	if len(ptr) == 0 {
-		return nil, &BadPointerError{ptr, ErrDeleteRoot}
+		return nil, &BadPointerError{BadPtr: ptr, Err: ErrDeleteRoot, Token: -1}
 	}
 
 	p := strings.LastIndexByte(ptr, '/')
@@ -347,7 +682,7 @@ func Delete(pdoc *interface{}, ptr string) (interface{}, error) {
 	prop := ptr[p+1:]
 	parentPtr := ptr[:p]
 
-	parent, err := Get(*pdoc, parentPtr)
+	parent, err := get(*pdoc, parentPtr, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -356,18 +691,28 @@ func Delete(pdoc *interface{}, ptr string) (interface{}, error) {
 	case map[string]interface{}:
 		key, err := UnescapeString(prop)
 		if err != nil {
-			return nil, &BadPointerError{ptr, err}
+			return nil, tokenError(ptr, len(ptr), err)
 		}
 		v, found := parent[key]
 		if !found {
-			return nil, propertyError(ptr)
+			return nil, propertyError(ptr, key, mapKeys(parent))
 		}
 		delete(parent, key)
 		return v, nil
+	case *Object:
+		key, err := UnescapeString(prop)
+		if err != nil {
+			return nil, tokenError(ptr, len(ptr), err)
+		}
+		v, found := parent.Delete(key)
+		if !found {
+			return nil, propertyError(ptr, key, parent.Keys())
+		}
+		return v, nil
 	case []interface{}:
 		n, err := arrayIndex(prop)
 		if err != nil {
-			return nil, &BadPointerError{ptr, err}
+			return nil, tokenError(ptr, len(ptr), err)
 		}
 		/*
 			// FIXME what should be the baviour for '-'?
@@ -376,14 +721,37 @@ func Delete(pdoc *interface{}, ptr string) (interface{}, error) {
 			}
 		*/
 		if n < 0 {
-			return nil, &BadPointerError{ptr, ErrIndex}
+			return nil, tokenError(ptr, len(ptr), ErrIndex)
 		} else if n >= len(parent) {
-			return nil, &BadPointerError{ptr, ErrIndex}
+			return nil, tokenError(ptr, len(ptr), ErrIndex)
 		}
 		v := parent[n]
 		copy(parent[n:], parent[n+1:])
-		return v, Set(pdoc, parentPtr, parent[:len(parent)-1])
+		return v, set(pdoc, parentPtr, parent[:len(parent)-1], opts)
 	default:
-		return nil, docError(parentPtr, parent)
+		switch typedKind(parent) {
+		case typedObject:
+			key, err := UnescapeString(prop)
+			if err != nil {
+				return nil, tokenError(ptr, len(ptr), err)
+			}
+			v, found := deleteTypedKey(parent, key)
+			if !found {
+				return nil, propertyError(ptr, key, typedKeys(parent))
+			}
+			return v, nil
+		case typedArray:
+			n, err := arrayIndex(prop)
+			if err != nil {
+				return nil, tokenError(ptr, len(ptr), err)
+			}
+			if n < 0 || n >= typedLen(parent) {
+				return nil, tokenError(ptr, len(ptr), ErrIndex)
+			}
+			v, rest := deleteTypedIndex(parent, n)
+			return v, set(pdoc, parentPtr, rest, opts)
+		default:
+			return nil, docError(parentPtr, parent)
+		}
 	}
 }
@@ -186,3 +186,118 @@ func TestSet(t *testing.T) {
 	checkSet(t, `{}`, `/ok`, true, `{"ok":true}`)
 	checkSet(t, `{"x":[]}`, `/x/-`, true, `{"x":[true]}`)
 }
+
+func TestGetDuplicateKeys(t *testing.T) {
+	for _, test := range []struct {
+		json     string
+		ptr      string
+		expected interface{}
+		// Pointer reported in strict mode
+		dupPtr string
+	}{
+		{`{"a":1,"a":2}`, `/a`, float64(2), `/a`},
+		{`{"a":1,"b":0,"a":2}`, `/b`, float64(0), `/a`},
+		{`{"a":{"b":1},"a":{"c":2}}`, `/a/c`, float64(2), `/a`},
+		{`[{"x":true,"x":false}]`, `/0/x`, false, `/0/x`},
+		{`{"a":{"b":{"c":1,"c":2}}}`, `/a/b`, map[string]interface{}{"c": float64(2)}, `/a/b/c`},
+		{`{"a":{"b":[{"c":1,"c":2}]}}`, `/a`, map[string]interface{}{"b": []interface{}{map[string]interface{}{"c": float64(2)}}}, `/a/b/0/c`},
+		{`{"a~b":1,"a~b":2}`, ``, map[string]interface{}{"a~b": float64(2)}, `/a~0b`},
+	} {
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(test.json), &decoded); err != nil {
+			t.Fatal(err)
+		}
+		expected, err := jsonptr.Get(decoded, test.ptr)
+		if err != nil {
+			t.Fatalf("%s %q: %v", test.json, test.ptr, err)
+		}
+		if !reflect.DeepEqual(expected, test.expected) {
+			t.Fatalf("%s %q: json.Unmarshal inconsistency: %#v", test.json, test.ptr, expected)
+		}
+
+		for _, doc := range []interface{}{
+			json.RawMessage(test.json),
+			json.NewDecoder(strings.NewReader(test.json)),
+		} {
+			got, err := jsonptr.Get(doc, test.ptr)
+			if err != nil {
+				t.Errorf("%s %q: %T: %v", test.json, test.ptr, doc, err)
+			} else if !reflect.DeepEqual(got, expected) {
+				t.Errorf("%s %q: %T: got %#v, expected %#v", test.json, test.ptr, doc, got, expected)
+			}
+		}
+
+		strict := jsonptr.Options{DisallowDuplicateKeys: true}
+		for _, doc := range []interface{}{
+			json.RawMessage(test.json),
+			json.NewDecoder(strings.NewReader(test.json)),
+		} {
+			_, err := strict.Get(doc, test.ptr)
+			if e, ok := err.(*jsonptr.PtrError); !ok || e.Err != jsonptr.ErrDuplicateKey || e.Ptr != test.dupPtr {
+				t.Errorf("%s %q: %T: strict: got %#v", test.json, test.ptr, doc, err)
+			}
+		}
+		// No duplicates in a decoded tree
+		if _, err := strict.Get(decoded, test.ptr); err != nil {
+			t.Errorf("%s %q: strict: %v", test.json, test.ptr, err)
+		}
+	}
+}
+
+// decodeRecorder is a JSONDecoder recording the values read with Decode.
+type decodeRecorder struct {
+	*json.Decoder
+	decoded []string
+}
+
+func (d *decodeRecorder) Decode(v interface{}) error {
+	var raw json.RawMessage
+	if err := d.Decoder.Decode(&raw); err != nil {
+		return err
+	}
+	d.decoded = append(d.decoded, string(raw))
+	return json.Unmarshal(raw, v)
+}
+
+func TestGetDecoderBuffering(t *testing.T) {
+	const doc = `{"a":{"big":[1,2,3]},"b":[[1],{"x":[2]},{"c":3}],"d":[4,5],"b":[[6],{"c":7}]}`
+	for _, test := range []struct {
+		ptr      string
+		expected interface{}
+		decoded  []string
+	}{
+		// Only the values of the properties on the path are buffered
+		{"/b/1/c", 7.0, []string{`[[1],{"x":[2]},{"c":3}]`, `[[6],{"c":7}]`}},
+		{"/d/1", 5.0, []string{`[4,5]`}},
+	} {
+		d := &decodeRecorder{Decoder: json.NewDecoder(strings.NewReader(doc))}
+		v, err := jsonptr.Get(d, test.ptr)
+		if err != nil || v != test.expected {
+			t.Errorf("%q: got %v, %v", test.ptr, v, err)
+		}
+		if !reflect.DeepEqual(d.decoded, test.decoded) {
+			t.Errorf("%q: decoded %q", test.ptr, d.decoded)
+		}
+	}
+
+	// Array elements before the index are skipped
+	d := &decodeRecorder{Decoder: json.NewDecoder(strings.NewReader(`[{"a":[1]},[2],3]`))}
+	if v, err := jsonptr.Get(d, "/2"); err != nil || v != 3.0 {
+		t.Errorf("got %v, %v", v, err)
+	}
+	if !reflect.DeepEqual(d.decoded, []string{"3"}) {
+		t.Errorf("decoded %q", d.decoded)
+	}
+}
+
+func TestGetRawPropertyNotFound(t *testing.T) {
+	for _, doc := range []interface{}{
+		json.RawMessage(`{"a":{"b":1}}`),
+		json.NewDecoder(strings.NewReader(`{"a":{"b":1}}`)),
+	} {
+		_, err := jsonptr.Get(doc, "/a/c")
+		if e, ok := err.(*jsonptr.PtrError); !ok || e.Err != jsonptr.ErrProperty || e.Ptr != "/a/c" {
+			t.Errorf("%T: got %#v", doc, err)
+		}
+	}
+}
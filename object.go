@@ -0,0 +1,216 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// Object is a JSON object that preserves the order of its properties.
+//
+// It can be used in a document tree as an alternative to map[string]interface{}
+// when the document must be written back with properties in their original order.
+// All functions of this package support *Object natively.
+//
+// See [UnmarshalOrdered] and [DecodeOrdered] to build such a document tree.
+type Object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewObject returns an empty *Object.
+func NewObject() *Object {
+	return &Object{values: make(map[string]interface{})}
+}
+
+// Len returns the number of properties.
+func (obj *Object) Len() int {
+	if obj == nil {
+		return 0
+	}
+	return len(obj.keys)
+}
+
+// Keys returns the property names, in order.
+func (obj *Object) Keys() []string {
+	if obj == nil {
+		return nil
+	}
+	return append([]string(nil), obj.keys...)
+}
+
+// Get returns the value of a property.
+func (obj *Object) Get(key string) (interface{}, bool) {
+	if obj == nil {
+		return nil, false
+	}
+	v, ok := obj.values[key]
+	return v, ok
+}
+
+// Set changes the value of a property.
+// A new property is appended after the existing ones.
+func (obj *Object) Set(key string, value interface{}) {
+	if obj.values == nil {
+		obj.values = make(map[string]interface{})
+	}
+	if _, exists := obj.values[key]; !exists {
+		obj.keys = append(obj.keys, key)
+	}
+	obj.values[key] = value
+}
+
+// Delete removes a property and returns its value.
+// The order of the remaining properties is preserved.
+func (obj *Object) Delete(key string) (interface{}, bool) {
+	if obj == nil {
+		return nil, false
+	}
+	v, ok := obj.values[key]
+	if !ok {
+		return nil, false
+	}
+	delete(obj.values, key)
+	for i, k := range obj.keys {
+		if k == key {
+			copy(obj.keys[i:], obj.keys[i+1:])
+			obj.keys[len(obj.keys)-1] = ""
+			obj.keys = obj.keys[:len(obj.keys)-1]
+			break
+		}
+	}
+	return v, true
+}
+
+// insert adds a property at position i.
+func (obj *Object) insert(i int, key string, value interface{}) {
+	obj.Set(key, value)
+	copy(obj.keys[i+1:], obj.keys[i:len(obj.keys)-1])
+	obj.keys[i] = key
+}
+
+// MarshalJSON implements [encoding/json.Marshaler].
+func (obj *Object) MarshalJSON() ([]byte, error) {
+	if obj == nil {
+		return []byte("null"), nil
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range obj.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		b, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+		buf.WriteByte(':')
+		if b, err = json.Marshal(obj.values[k]); err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements [encoding/json.Unmarshaler].
+//
+// Nested objects are decoded as *Object.
+func (obj *Object) UnmarshalJSON(data []byte) error {
+	v, err := UnmarshalOrdered(data)
+	if err != nil {
+		return err
+	}
+	o, ok := v.(*Object)
+	if !ok {
+		return errors.New("jsonptr: JSON object expected")
+	}
+	*obj = *o
+	return nil
+}
+
+// UnmarshalOrdered decodes a JSON document like [encoding/json.Unmarshal] into an
+// interface{}, except that objects are decoded as *Object instead of
+// map[string]interface{}.
+func UnmarshalOrdered(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	v, err := DecodeOrdered(decoder)
+	if err != nil {
+		return nil, err
+	}
+	// Reject trailing garbage, like json.Unmarshal does
+	switch _, err := decoder.Token(); err {
+	case io.EOF:
+		return v, nil
+	case nil:
+		return nil, errors.New("jsonptr: invalid data after top-level value")
+	default:
+		return nil, err
+	}
+}
+
+// DecodeOrdered reads the next JSON value from decoder like
+// [encoding/json.Decoder.Decode] into an interface{}, except that objects
+// are decoded as *Object instead of map[string]interface{}.
+//
+// In case of duplicate keys, the last value wins, but at the position of the
+// first occurrence.
+//
+// A value nested in more than 10000 arrays and objects is reported as a
+// *DocumentError wrapping [ErrLimit].
+func DecodeOrdered(decoder JSONDecoder) (interface{}, error) {
+	return decodeOrdered(decoder, 0)
+}
+
+func decodeOrdered(decoder JSONDecoder, depth int) (interface{}, error) {
+	tok, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+	if depth++; depth > defaultMaxDepth {
+		return nil, limitError("")
+	}
+	switch delim {
+	case '{':
+		obj := NewObject()
+		for decoder.More() {
+			tok, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeOrdered(decoder, depth)
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(tok.(string), v)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	default: // '['
+		arr := []interface{}{}
+		for decoder.More() {
+			v, err := decodeOrdered(decoder, depth)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return arr, nil
+	}
+}
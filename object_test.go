@@ -0,0 +1,166 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+func mustMarshal(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
+
+func TestObject(t *testing.T) {
+	const in = `{"z":1,"a":{"y":[true,{"c":null,"b":"x"}],"x":2},"m":[]}`
+	doc, err := jsonptr.UnmarshalOrdered([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mustMarshal(doc); got != in {
+		t.Fatalf("round trip: got %s", got)
+	}
+
+	for _, test := range []struct {
+		ptr      string
+		expected interface{}
+	}{
+		{"/z", float64(1)},
+		{"/a/x", float64(2)},
+		{"/a/y/0", true},
+		{"/a/y/1/b", "x"},
+		{"/m", []interface{}{}},
+	} {
+		got, err := jsonptr.Get(doc, test.ptr)
+		if err != nil {
+			t.Errorf("Get %q: %v", test.ptr, err)
+		} else if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("Get %q: got %#v", test.ptr, got)
+		}
+		got, err = jsonptr.MustParse(test.ptr).In(doc)
+		if err != nil {
+			t.Errorf("In %q: %v", test.ptr, err)
+		} else if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("In %q: got %#v", test.ptr, got)
+		}
+	}
+
+	if _, err := jsonptr.Get(doc, "/a/w"); err == nil {
+		t.Error("error expected for missing property")
+	}
+
+	for _, step := range []struct {
+		ptr   string
+		value interface{}
+	}{
+		{"/a/x", 3},
+		{"/b", "new"},
+		{"/a/y/1/a", false},
+	} {
+		if err := jsonptr.Set(&doc, step.ptr, step.value); err != nil {
+			t.Fatalf("Set %q: %v", step.ptr, err)
+		}
+	}
+	const afterSet = `{"z":1,"a":{"y":[true,{"c":null,"b":"x","a":false}],"x":3},"m":[],"b":"new"}`
+	if got := mustMarshal(doc); got != afterSet {
+		t.Fatalf("after Set: got %s", got)
+	}
+
+	v, err := jsonptr.Delete(&doc, "/a/y/1/c")
+	if err != nil || v != nil {
+		t.Fatalf("Delete: got %v, %v", v, err)
+	}
+	v, err = jsonptr.Delete(&doc, "/z")
+	if err != nil || v != float64(1) {
+		t.Fatalf("Delete: got %v, %v", v, err)
+	}
+	if _, err = jsonptr.Delete(&doc, "/z"); err == nil {
+		t.Fatal("Delete of missing property: error expected")
+	}
+	const afterDelete = `{"a":{"y":[true,{"b":"x","a":false}],"x":3},"m":[],"b":"new"}`
+	if got := mustMarshal(doc); got != afterDelete {
+		t.Fatalf("after Delete: got %s", got)
+	}
+}
+
+func TestObjectSetNil(t *testing.T) {
+	var doc interface{} = (*jsonptr.Object)(nil)
+	if err := jsonptr.Set(&doc, "/a", 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustMarshal(doc); got != `{"a":1}` {
+		t.Fatalf("got %s", got)
+	}
+}
+
+func TestUnmarshalOrderedErrors(t *testing.T) {
+	for _, in := range []string{
+		``,
+		`{`,
+		`{"a":1,}`,
+		`[1,2`,
+		`{} x`,
+		`{} {}`,
+	} {
+		if _, err := jsonptr.UnmarshalOrdered([]byte(in)); err == nil {
+			t.Errorf("%q: error expected", in)
+		}
+	}
+
+	var obj jsonptr.Object
+	if err := json.Unmarshal([]byte(`[]`), &obj); err == nil {
+		t.Error("error expected when unmarshaling an array into Object")
+	}
+	if err := json.Unmarshal([]byte(`{"b":1,"a":2,"b":3}`), &obj); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustMarshal(&obj); got != `{"b":3,"a":2}` {
+		t.Errorf("duplicate keys: got %s", got)
+	}
+}
+
+// nestedDecoder is a JSONDecoder of an endless nesting of arrays.
+type nestedDecoder struct{}
+
+func (nestedDecoder) Token() (json.Token, error) { return json.Delim('['), nil }
+func (nestedDecoder) More() bool                 { return true }
+func (nestedDecoder) Decode(interface{}) error   { return fmt.Errorf("Decode called") }
+
+func TestDecodeOrderedDeep(t *testing.T) {
+	_, err := jsonptr.DecodeOrdered(nestedDecoder{})
+	if e, ok := err.(*jsonptr.DocumentError); !ok || e.Err != jsonptr.ErrLimit {
+		t.Errorf("got %v", err)
+	}
+
+	deep := strings.Repeat("[", 10000) + strings.Repeat("]", 10000)
+	if _, err := jsonptr.UnmarshalOrdered([]byte(deep)); err != nil {
+		t.Errorf("10000 levels: %v", err)
+	}
+	if _, err := jsonptr.UnmarshalOrdered([]byte("[" + deep + "]")); err == nil {
+		t.Error("10001 levels: error expected")
+	}
+}
+
+func ExampleObject() {
+	doc, _ := jsonptr.UnmarshalOrdered([]byte(`{"name":"jsonptr","version":1,"tags":["json"]}`))
+
+	_ = jsonptr.Set(&doc, "/version", 2)
+	_ = jsonptr.Set(&doc, "/license", "Apache-2.0")
+	_, _ = jsonptr.Delete(&doc, "/tags")
+
+	out, _ := json.Marshal(doc)
+	fmt.Println(string(out))
+	// Output:
+	// {"name":"jsonptr","version":2,"license":"Apache-2.0"}
+}
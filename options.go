@@ -0,0 +1,152 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+import "strings"
+
+// Options allows to tune the behaviour of the functions of this package.
+//
+// The zero value (as well as a nil *Options) gives the behaviour of the
+// package-level functions.
+type Options struct {
+	// DisallowDuplicateKeys makes Get fail with a PtrError wrapping
+	// ErrDuplicateKey when a serialized object crossed by the pointer, or
+	// the extracted value itself, has duplicate keys.
+	//
+	// By default the last value wins, like with [encoding/json.Unmarshal].
+	DisallowDuplicateKeys bool
+
+	// The following limits protect against untrusted input. A zero value
+	// means no limit.
+	//
+	// A pointer exceeding MaxPointerLength or MaxTokens is reported as a
+	// PtrError wrapping ErrLimit. A serialized document exceeding MaxDepth
+	// or MaxBytes is reported as a DocumentError wrapping ErrLimit.
+
+	// MaxPointerLength is the maximum length of a pointer, in bytes.
+	MaxPointerLength int
+	// MaxTokens is the maximum number of reference tokens in a pointer.
+	MaxTokens int
+	// MaxDepth is the maximum nesting of arrays and objects in serialized
+	// documents. Without limit, values are still decoded with a maximum
+	// nesting of 10000, like with encoding/json.
+	MaxDepth int
+	// MaxBytes is the maximum number of bytes read from a serialized
+	// document. A JSONDecoder is read token by token to enforce MaxDepth
+	// and MaxBytes: a single string or number is still read whole before
+	// being checked. MaxBytes requires a JSONDecoder with an InputOffset
+	// method (such as *[encoding/json.Decoder] since Go 1.14): with any
+	// other JSONDecoder, Get fails with ErrLimit. With ExtractRecords,
+	// MaxBytes is the maximum size of each record.
+	MaxBytes int64
+	// MaxIndex is the maximum array index accepted by Set, to avoid
+	// padding an array with billions of nulls.
+	MaxIndex int
+
+	// depth is the nesting level of the current value, for MaxDepth.
+	depth int
+}
+
+// defaultMaxDepth is the maximum nesting of the values decoded by recursive
+// functions (cborDecode, DecodeOrdered) if Options.MaxDepth is not set. This
+// is the limit of encoding/json.
+const defaultMaxDepth = 10000
+
+func (opts *Options) disallowDuplicateKeys() bool {
+	return opts != nil && opts.DisallowDuplicateKeys
+}
+
+func (opts *Options) maxDepth() int {
+	if opts == nil {
+		return 0
+	}
+	return opts.MaxDepth
+}
+
+func (opts *Options) maxBytes() int64 {
+	if opts == nil {
+		return 0
+	}
+	return opts.MaxBytes
+}
+
+func (opts *Options) maxIndex() int {
+	if opts == nil {
+		return 0
+	}
+	return opts.MaxIndex
+}
+
+// checkPointer checks the syntax of ptr and checks it against
+// MaxPointerLength and MaxTokens.
+func (opts *Options) checkPointer(ptr string) ptrError {
+	if opts != nil {
+		if opts.MaxPointerLength > 0 && len(ptr) > opts.MaxPointerLength {
+			return limitPtrError(ptr)
+		}
+		if opts.MaxTokens > 0 && strings.Count(ptr, "/") > opts.MaxTokens {
+			return limitPtrError(ptr)
+		}
+	}
+	if err := checkSyntax(ptr); err != nil {
+		return err
+	}
+	return nil
+}
+
+// nested returns the options that apply to a value found at the given depth.
+func (opts *Options) nested(depth int) *Options {
+	if opts.maxDepth() <= 0 || depth == 0 {
+		return opts
+	}
+	o := *opts
+	o.depth += depth
+	return &o
+}
+
+// depthExceeded reports if the value raw, found at the given depth, makes
+// the document nested deeper than MaxDepth.
+func (opts *Options) depthExceeded(raw []byte, depth int) bool {
+	if opts.maxDepth() <= 0 {
+		return false
+	}
+	return rawDepthExceeds(raw, opts.MaxDepth-opts.depth-depth)
+}
+
+type inputOffsetter interface {
+	InputOffset() int64
+}
+
+// bytesExceeded reports if more than MaxBytes have been read from decoder.
+// Without InputOffset, the bytes read are unknown: the limit is reported as
+// exceeded.
+func (opts *Options) bytesExceeded(decoder JSONDecoder) bool {
+	if opts.maxBytes() <= 0 {
+		return false
+	}
+	d, ok := decoder.(inputOffsetter)
+	return !ok || d.InputOffset() > opts.MaxBytes
+}
+
+// Get is like the [Get] function, with options.
+func (opts *Options) Get(doc interface{}, ptr string) (interface{}, error) {
+	return get(doc, ptr, opts)
+}
+
+// Set is like the [Set] function, with options.
+func (opts *Options) Set(doc *interface{}, ptr string, value interface{}) error {
+	if err := opts.checkPointer(ptr); err != nil {
+		return err
+	}
+	return set(doc, ptr, value, opts)
+}
+
+// Delete is like the [Delete] function, with options.
+func (opts *Options) Delete(pdoc *interface{}, ptr string) (interface{}, error) {
+	if err := opts.checkPointer(ptr); err != nil {
+		return nil, err
+	}
+	return remove(pdoc, ptr, opts)
+}
@@ -0,0 +1,180 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+func isLimitError(err error) bool {
+	switch err := err.(type) {
+	case *jsonptr.PtrError:
+		return err.Err == jsonptr.ErrLimit
+	case *jsonptr.DocumentError:
+		return err.Err == jsonptr.ErrLimit
+	}
+	return false
+}
+
+func TestOptionsLimits(t *testing.T) {
+	const doc = `{"a":{"b":{"c":[1,[2,[3]]]}},"d":"` + "0123456789" + `","e":true}`
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(doc), &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		opts    jsonptr.Options
+		ptr     string
+		limited bool
+	}{
+		{jsonptr.Options{MaxPointerLength: 6}, "/a/b/c", false},
+		{jsonptr.Options{MaxPointerLength: 5}, "/a/b/c", true},
+		{jsonptr.Options{MaxTokens: 3}, "/a/b/c", false},
+		{jsonptr.Options{MaxTokens: 2}, "/a/b/c", true},
+		{jsonptr.Options{MaxDepth: 6}, "/e", false},
+		{jsonptr.Options{MaxDepth: 5}, "/e", true},
+		{jsonptr.Options{MaxDepth: 5}, "/d", true},
+		{jsonptr.Options{MaxDepth: 3}, "/a/b", true},
+		{jsonptr.Options{MaxBytes: int64(len(doc))}, "/e", false},
+		{jsonptr.Options{MaxBytes: int64(len(doc)) - 1}, "/e", true},
+		{jsonptr.Options{MaxBytes: 10}, "/a/b/c/0", true},
+	} {
+		for _, in := range []interface{}{
+			json.RawMessage(doc),
+			json.NewDecoder(strings.NewReader(doc)),
+		} {
+			_, err := test.opts.Get(in, test.ptr)
+			if test.limited != isLimitError(err) {
+				t.Errorf("%+v %q %T: got %v", test.opts, test.ptr, in, err)
+			}
+		}
+	}
+
+	// Arrays don't need to be read until the end
+	opts := jsonptr.Options{MaxBytes: 5}
+	for _, in := range []interface{}{
+		json.RawMessage(`[1,2,3,4,5]`),
+		json.NewDecoder(strings.NewReader(`[1,2,3,4,5]`)),
+	} {
+		if _, err := opts.Get(in, "/1"); err != nil {
+			t.Errorf("%T: %v", in, err)
+		}
+	}
+
+	// Pointer limits also apply to decoded documents
+	opts = jsonptr.Options{MaxTokens: 2}
+	if _, err := opts.Get(decoded, "/a/b/c"); !isLimitError(err) {
+		t.Errorf("MaxTokens on decoded document: got %v", err)
+	}
+	if err := opts.Set(&decoded, "/a/b/c", 1); !isLimitError(err) {
+		t.Errorf("MaxTokens on Set: got %v", err)
+	}
+	if _, err := opts.Delete(&decoded, "/a/b/c"); !isLimitError(err) {
+		t.Errorf("MaxTokens on Delete: got %v", err)
+	}
+}
+
+// endlessReader repeats its content forever, after a prefix.
+type endlessReader struct {
+	prefix string
+	repeat string
+	n      int64 // bytes read
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		if r.n < int64(len(r.prefix)) {
+			p[i] = r.prefix[r.n]
+		} else {
+			p[i] = r.repeat[(r.n-int64(len(r.prefix)))%int64(len(r.repeat))]
+		}
+		r.n++
+	}
+	return len(p), nil
+}
+
+// noOffsetDecoder is a JSONDecoder without InputOffset.
+type noOffsetDecoder struct {
+	d *json.Decoder
+}
+
+func (d noOffsetDecoder) Token() (json.Token, error) { return d.d.Token() }
+func (d noOffsetDecoder) More() bool                 { return d.d.More() }
+func (d noOffsetDecoder) Decode(v interface{}) error { return d.d.Decode(v) }
+
+func TestOptionsLimitsDecoder(t *testing.T) {
+	// Limits are checked while reading
+	for _, test := range []struct {
+		opts   jsonptr.Options
+		prefix string
+		repeat string
+		ptr    string
+	}{
+		{jsonptr.Options{MaxBytes: 1000}, `{"a":[`, `1,`, "/b"},
+		{jsonptr.Options{MaxBytes: 1000}, `[`, `{"x":[true]},`, "/1000000"},
+		{jsonptr.Options{MaxBytes: 1000}, `{"a":`, `[`, ""},
+		{jsonptr.Options{MaxDepth: 10}, `{"a":`, `[`, "/b"},
+		{jsonptr.Options{MaxDepth: 10}, `[`, `{"a":`, "/0/a"},
+		{jsonptr.Options{MaxDepth: 10}, `[`, `[`, ""},
+	} {
+		r := &endlessReader{prefix: test.prefix, repeat: test.repeat}
+		_, err := test.opts.Get(json.NewDecoder(r), test.ptr)
+		if !isLimitError(err) {
+			t.Errorf("%+v %s%s... %q: got %v", test.opts, test.prefix, test.repeat, test.ptr, err)
+		}
+		if r.n > 1<<20 {
+			t.Errorf("%+v %s%s... %q: %d bytes read", test.opts, test.prefix, test.repeat, test.ptr, r.n)
+		}
+	}
+
+	// Values are the same as without limits
+	const doc = `{"a":{"x":[1,-2.5e-3,"\"é\u00e9\n",{},[]],"y":{"z":null,"t":[true,false]}},"a":[{"k":{"l":[[1],[2,{"m":3}]]}}],"b":12345678901234567890}`
+	for _, ptr := range []string{"", "/a", "/a/0", "/a/0/k/l/1", "/b"} {
+		expected, err := jsonptr.Get(json.NewDecoder(strings.NewReader(doc)), ptr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts := jsonptr.Options{MaxDepth: 10, MaxBytes: 1000}
+		got, err := opts.Get(json.NewDecoder(strings.NewReader(doc)), ptr)
+		if err != nil || !reflect.DeepEqual(got, expected) {
+			t.Errorf("%q: got %#v, %v, expected %#v", ptr, got, err, expected)
+		}
+	}
+
+	// MaxBytes can't be checked without InputOffset
+	opts := jsonptr.Options{MaxBytes: 1000}
+	if _, err := opts.Get(noOffsetDecoder{json.NewDecoder(strings.NewReader(`[1]`))}, "/0"); !isLimitError(err) {
+		t.Errorf("got %v", err)
+	}
+	opts = jsonptr.Options{MaxDepth: 10}
+	if v, err := opts.Get(noOffsetDecoder{json.NewDecoder(strings.NewReader(`[1]`))}, "/0"); err != nil || v != 1.0 {
+		t.Errorf("got %v, %v", v, err)
+	}
+}
+
+func TestOptionsMaxIndex(t *testing.T) {
+	opts := jsonptr.Options{MaxIndex: 3}
+	var doc interface{} = []interface{}{}
+	for _, ptr := range []string{"/-", "/2", "/-"} {
+		if err := opts.Set(&doc, ptr, true); err != nil {
+			t.Fatalf("%q: %v", ptr, err)
+		}
+	}
+	for _, ptr := range []string{"/-", "/4", "/1000000000"} {
+		err := opts.Set(&doc, ptr, true)
+		if e, ok := err.(*jsonptr.PtrError); !ok || e.Err != jsonptr.ErrLimit || e.Ptr != ptr {
+			t.Errorf("%q: got %#v", ptr, err)
+		}
+	}
+	if got := len(doc.([]interface{})); got != 4 {
+		t.Errorf("got %d elements", got)
+	}
+}
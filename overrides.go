@@ -0,0 +1,102 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Override is the assignment of a value at a location of a document.
+type Override struct {
+	Ptr   string
+	Value interface{}
+}
+
+// Overrides collects assignments of values to apply to a document, such as
+// configuration overrides given on the command line. It implements
+// [flag.Value]:
+//
+//	var overrides jsonptr.Overrides
+//	flag.Var(&overrides, "set", "override a setting: `pointer=value`")
+type Overrides []Override
+
+// String implements [flag.Value].
+func (o Overrides) String() string {
+	parts := make([]string, len(o))
+	for i, ov := range o {
+		b, _ := json.Marshal(ov.Value)
+		parts[i] = ov.Ptr + "=" + string(b)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Set implements [flag.Value]. It parses an assignment pointer=value and
+// appends it. The pointer ends at the first '='. The value is parsed as
+// JSON; if it is not valid JSON it is taken as a string.
+//
+// An invalid pointer is reported as a *BadPointerError.
+func (o *Overrides) Set(assignment string) error {
+	p := strings.IndexByte(assignment, '=')
+	if p < 0 {
+		return errors.New("jsonptr: " + strconv.Quote(assignment) + ": missing '=' in pointer=value")
+	}
+	ptr := assignment[:p]
+	if err := checkSyntax(ptr); err != nil {
+		return err
+	}
+	*o = append(*o, Override{Ptr: ptr, Value: overrideValue(assignment[p+1:])})
+	return nil
+}
+
+// overrideValue parses s as JSON, with fallback to a string.
+func overrideValue(s string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+	return v
+}
+
+// Apply sets the values in doc with [Set], in order. All the assignments
+// are applied: the failures are reported in an [Errors].
+func (o Overrides) Apply(doc *interface{}) error {
+	var errs Errors
+	for _, ov := range o {
+		errs.Append(Set(doc, ov.Ptr, ov.Value))
+	}
+	return errs.Err()
+}
+
+// EnvOverrides returns the overrides given by the environment variables
+// (from environ, in the format of [os.Environ]) with names starting with
+// prefix followed by "__". The rest of the name is the location, with "__"
+// separating reference tokens: APP__server__port=8080 sets /server/port if
+// prefix is "APP". Values are parsed as in [Overrides.Set].
+//
+// The overrides are sorted by location, so that parents are set before
+// their children.
+func EnvOverrides(prefix string, environ []string) Overrides {
+	prefix += "__"
+	var o Overrides
+	for _, kv := range environ {
+		if !strings.HasPrefix(kv, prefix) {
+			continue
+		}
+		p := strings.IndexByte(kv, '=')
+		if p <= len(prefix) {
+			continue
+		}
+		ptr := Pointer(strings.Split(kv[len(prefix):p], "__"))
+		o = append(o, Override{Ptr: ptr.String(), Value: overrideValue(kv[p+1:])})
+	}
+	sort.SliceStable(o, func(i, j int) bool {
+		return o[i].Ptr < o[j].Ptr
+	})
+	return o
+}
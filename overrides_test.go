@@ -0,0 +1,125 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr_test
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+func TestOverridesFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	var overrides jsonptr.Overrides
+	fs.Var(&overrides, "set", "override")
+	err := fs.Parse([]string{
+		"-set", "/server/port=8080",
+		"-set", "/server/host=example.com",
+		"-set=/server/tls=true",
+		"-set", "/server/name=\"8080\"",
+		"-set", "/server/tags=[\"a\",\"b\"]",
+		"-set", "/server/expr=a=b",
+		"-set", "/server/empty=",
+		"-set", "/a~1b=null",
+		"-set", "=[]",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := jsonptr.Overrides{
+		{"/server/port", 8080.0},
+		{"/server/host", "example.com"},
+		{"/server/tls", true},
+		{"/server/name", "8080"},
+		{"/server/tags", []interface{}{"a", "b"}},
+		{"/server/expr", "a=b"},
+		{"/server/empty", ""},
+		{"/a~1b", nil},
+		{"", []interface{}{}},
+	}
+	if !reflect.DeepEqual(overrides, expected) {
+		t.Errorf("got %#v", overrides)
+	}
+	if s := overrides[:3].String(); s != `/server/port=8080 /server/host="example.com" /server/tls=true` {
+		t.Errorf("String: got %s", s)
+	}
+
+	for _, arg := range []string{"server/port=1", "/a~2=1", "/a"} {
+		var o jsonptr.Overrides
+		err := o.Set(arg)
+		if err == nil {
+			t.Errorf("%q: error expected", arg)
+			continue
+		}
+		t.Logf("%q: %v", arg, err)
+		if _, isBadPtr := err.(*jsonptr.BadPointerError); isBadPtr != (arg != "/a") {
+			t.Errorf("%q: got %T", arg, err)
+		}
+	}
+}
+
+func TestOverridesApply(t *testing.T) {
+	var doc interface{}
+	_ = json.Unmarshal([]byte(`{"server":{"port":80},"users":["a"]}`), &doc)
+	o := jsonptr.EnvOverrides("APP", []string{
+		"HOME=/root",
+		"APP__users__1=b",
+		"APP__server__port=8080",
+		"APP__server=notanobject",
+		"APP__server__port__x=1",
+		"APP_server=x",
+		"APP__=x",
+		"APP__a~b__c/d={}",
+		"APPLICATION__x=1",
+	})
+	expected := jsonptr.Overrides{
+		{"/a~0b/c~1d", map[string]interface{}{}},
+		{"/server", "notanobject"},
+		{"/server/port", 8080.0},
+		{"/server/port/x", 1.0},
+		{"/users/1", "b"},
+	}
+	if !reflect.DeepEqual(o, expected) {
+		t.Errorf("got %#v", o)
+	}
+
+	err := o.Apply(&doc)
+	errs, ok := err.(jsonptr.Errors)
+	if !ok || len(errs) != 3 {
+		t.Fatalf("got %v", err)
+	}
+	for _, e := range errs {
+		t.Log(e)
+	}
+	if b, _ := json.Marshal(doc); string(b) != `{"server":"notanobject","users":["a","b"]}` {
+		t.Errorf("got %s", b)
+	}
+}
+
+func ExampleOverrides() {
+	var overrides jsonptr.Overrides
+	fs := flag.NewFlagSet("service", flag.ExitOnError)
+	fs.Var(&overrides, "set", "override a setting: `pointer=value`")
+	_ = fs.Parse([]string{"-set", "/server/port=8080", "-set", "/server/host=example.com"})
+
+	// Environment variables are applied after the command line
+	overrides = append(overrides, jsonptr.EnvOverrides("APP", []string{"APP__debug=true"})...)
+
+	var config interface{}
+	_ = json.Unmarshal([]byte(`{"server":{"host":"localhost","port":80},"debug":false}`), &config)
+	if err := overrides.Apply(&config); err != nil {
+		fmt.Println(err)
+	}
+	b, _ := json.Marshal(config)
+	fmt.Printf("%s\n", b)
+	// Output:
+	// {"debug":true,"server":{"host":"example.com","port":8080}}
+}
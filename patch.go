@@ -0,0 +1,342 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrPatch is wrapped in the error of ApplyPatch for an invalid
+	// operation.
+	ErrPatch = errors.New("invalid patch operation")
+
+	// ErrTest is wrapped in the error of ApplyPatch for a failed "test"
+	// operation.
+	ErrTest = errors.New("test operation failed")
+)
+
+// Operation is a JSON Patch (RFC 6902) operation.
+type Operation struct {
+	// Op is "add", "remove", "replace", "move", "copy" or "test".
+	Op   string `json:"op"`
+	Path string `json:"path"`
+	// From is the source of "move" and "copy".
+	From string `json:"from,omitempty"`
+	// Value is the value of "add", "replace" and "test".
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON implements [encoding/json.Marshaler]. Only the members used
+// by the operation are written.
+func (op Operation) MarshalJSON() ([]byte, error) {
+	switch op.Op {
+	case "remove":
+		return json.Marshal(&struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{op.Op, op.Path})
+	case "move", "copy":
+		return json.Marshal(&struct {
+			Op   string `json:"op"`
+			From string `json:"from"`
+			Path string `json:"path"`
+		}{op.Op, op.From, op.Path})
+	}
+	return json.Marshal(&struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		Value interface{} `json:"value"`
+	}{op.Op, op.Path, op.Value})
+}
+
+// ApplyPatch applies a JSON Patch (RFC 6902) to the deserialized document
+// at *doc. The patch is atomic: if an operation fails, the document is
+// restored and the error is returned.
+//
+// Unlike [Set], "add" inserts into arrays (following elements are shifted)
+// and rejects an index beyond the end of the array.
+//
+// Errors:
+//   - *BadPointerError for an invalid path or from
+//   - *PtrError for a missing location, or wrapping ErrPatch for an
+//     unknown op or a "move" into a child of from
+//   - *DocumentError for a location in a value which is not a container, or
+//     wrapping ErrTest for a failed "test"
+func ApplyPatch(doc *interface{}, patch []Operation) error {
+	_, err := applyPatch(doc, patch)
+	return err
+}
+
+// Patch is like [ApplyPatch]. If the patch fails, the changes made by its
+// previous operations are reverted, but not the previous changes of the
+// transaction.
+func (tx *Tx) Patch(patch []Operation) error {
+	// All the containers modified by the patch are copied, so that the
+	// document before the patch is kept in case of error
+	root, owned, n := *tx.doc, tx.owned, len(tx.changes)
+	tx.owned = nil
+	for i := range patch {
+		op := &patch[i]
+		tx.own(op.Path)
+		if op.Op == "move" {
+			tx.own(op.From)
+		}
+		changes, err := applyOperation(tx.doc, op, nil)
+		for _, c := range changes {
+			tx.changed(c)
+		}
+		if err != nil {
+			*tx.doc, tx.owned, tx.changes = root, owned, tx.changes[:n]
+			return err
+		}
+	}
+	return nil
+}
+
+// applyPatch applies patch to doc and returns the changes. In case of error
+// the changes are reverted.
+func applyPatch(doc *interface{}, patch []Operation) ([]*change, error) {
+	var changes []*change
+	for i := range patch {
+		var err error
+		if changes, err = applyOperation(doc, &patch[i], changes); err != nil {
+			for j := len(changes) - 1; j >= 0; j-- {
+				changes[j].revert(doc)
+			}
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+func applyOperation(doc *interface{}, op *Operation, changes []*change) ([]*change, error) {
+	if err := checkSyntax(op.Path); err != nil {
+		return changes, err
+	}
+	var c *change
+	var err error
+	switch op.Op {
+	case "add":
+		c, err = insertChange(doc, op.Path, op.Value)
+	case "remove":
+		c, err = removeChange(doc, op.Path, nil)
+	case "replace":
+		if _, err = get(*doc, op.Path, nil); err == nil {
+			c, err = setChange(doc, op.Path, op.Value, nil)
+		}
+	case "move", "copy":
+		if err := checkSyntax(op.From); err != nil {
+			return changes, err
+		}
+		var v interface{}
+		if v, err = get(*doc, op.From, nil); err != nil {
+			return changes, err
+		}
+		if op.Op == "copy" {
+			v = copyValue(v)
+		} else {
+			if op.Path == op.From {
+				return changes, nil
+			}
+			if strings.HasPrefix(op.Path, op.From+"/") {
+				return changes, &PtrError{Ptr: op.Path, Err: ErrPatch}
+			}
+			if c, err = removeChange(doc, op.From, nil); err != nil {
+				return changes, err
+			}
+			changes = append(changes, c)
+		}
+		c, err = insertChange(doc, op.Path, v)
+	case "test":
+		var v interface{}
+		if v, err = get(*doc, op.Path, nil); err == nil && !equal(v, op.Value) {
+			e := docError(op.Path, v)
+			e.Err = ErrTest
+			err = e
+		}
+		return changes, err
+	default:
+		return changes, &PtrError{Ptr: op.Path, Err: ErrPatch}
+	}
+	if err != nil {
+		return changes, err
+	}
+	return append(changes, c), nil
+}
+
+// copyValue returns a deep copy of a value of the data model.
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		m := make(map[string]interface{}, len(v))
+		for k, x := range v {
+			m[k] = copyValue(x)
+		}
+		return m
+	case *Object:
+		if v == nil {
+			return v
+		}
+		obj := NewObject()
+		for _, k := range v.keys {
+			obj.Set(k, copyValue(v.values[k]))
+		}
+		return obj
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		a := make([]interface{}, len(v))
+		for i, x := range v {
+			a[i] = copyValue(x)
+		}
+		return a
+	}
+	// Typed containers
+	c, _ := copyTyped(v)
+	switch c := c.(type) {
+	case []map[string]interface{}:
+		for i, x := range c {
+			c[i], _ = copyValue(x).(map[string]interface{})
+		}
+	case map[string][]interface{}:
+		for k, x := range c {
+			c[k], _ = copyValue(x).([]interface{})
+		}
+	case map[string]map[string]interface{}:
+		for k, x := range c {
+			c[k], _ = copyValue(x).(map[string]interface{})
+		}
+	}
+	return c
+}
+
+// equal reports if two values of the data model are equal, as JSON values:
+// numbers are compared by value and objects without order.
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case bool:
+		b, ok := b.(bool)
+		return ok && a == b
+	case string:
+		b, ok := b.(string)
+		return ok && a == b
+	}
+	if n, at := elements(a); at != nil {
+		bn, bat := elements(b)
+		if bat == nil || n != bn {
+			return false
+		}
+		for i := 0; i < n; i++ {
+			if !equal(at(i), bat(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	if keys, get := members(a); get != nil {
+		bkeys, bget := members(b)
+		if bget == nil || len(keys) != len(bkeys) {
+			return false
+		}
+		for _, k := range keys {
+			bv, ok := bget(k)
+			if !ok {
+				return false
+			}
+			v, _ := get(k)
+			if !equal(v, bv) {
+				return false
+			}
+		}
+		return true
+	}
+	x, ok := number(a)
+	if !ok {
+		return false
+	}
+	y, ok := number(b)
+	return ok && x == y
+}
+
+// members returns the keys and a getter of an object (nil if v is not an
+// object).
+func members(v interface{}) ([]string, func(string) (interface{}, bool)) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return mapKeys(v), func(k string) (interface{}, bool) {
+			x, ok := v[k]
+			return x, ok
+		}
+	case *Object:
+		return v.Keys(), v.Get
+	}
+	if typedKind(v) == typedObject {
+		return typedKeys(v), func(k string) (interface{}, bool) {
+			return getTypedKey(v, k)
+		}
+	}
+	return nil, nil
+}
+
+// elements returns the length and a getter of an array (nil if v is not an
+// array).
+func elements(v interface{}) (int, func(int) interface{}) {
+	if a, ok := v.([]interface{}); ok {
+		return len(a), func(i int) interface{} {
+			return a[i]
+		}
+	}
+	if typedKind(v) == typedArray {
+		return typedLen(v), func(i int) interface{} {
+			x, _ := getTypedIndex(v, i)
+			return x
+		}
+	}
+	return 0, nil
+}
+
+// number converts a number of the data model to float64.
+func number(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case json.Number:
+		f, err := strconv.ParseFloat(string(v), 64)
+		return f, err == nil
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,196 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+func TestApplyPatch(t *testing.T) {
+	// Examples from RFC 6902 appendix A
+	for _, test := range []struct {
+		doc, patch, expected string
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"foo":"bar","baz":"qux"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{
+			`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"foo":null}`, `[{"op":"test","path":"/foo","value":null}]`, `{"foo":null}`},
+		{`{"foo":"bar"}`, `[{"op":"move","from":"/foo","path":"/foo"}]`, `{"foo":"bar"}`},
+		{`{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`},
+		{`{"foo":{"a":1,"b":[1.0,{}]}}`, `[{"op":"test","path":"/foo","value":{"b":[1,{}],"a":1}}]`, `{"foo":{"a":1,"b":[1,{}]}}`},
+		// Others
+		{`[1]`, `[{"op":"copy","from":"","path":"/-"},{"op":"add","path":"/1/-","value":2}]`, `[1,[1,2]]`},
+		{`{"a":1}`, `[{"op":"add","path":"","value":[]},{"op":"add","path":"/0","value":0}]`, `[0]`},
+		{`{"a":1}`, `[{"op":"replace","path":"","value":2}]`, `2`},
+		{`{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a~1b"}]`, `{"a/b":{"b":1}}`},
+	} {
+		var patch []jsonptr.Operation
+		if err := json.Unmarshal([]byte(test.patch), &patch); err != nil {
+			t.Fatal(err)
+		}
+		doc, _ := jsonptr.UnmarshalOrdered([]byte(test.doc))
+		if err := jsonptr.ApplyPatch(&doc, patch); err != nil {
+			t.Errorf("%s %s: %v", test.doc, test.patch, err)
+			continue
+		}
+		if b, _ := json.Marshal(doc); string(b) != test.expected {
+			t.Errorf("%s %s: got %s, expected %s", test.doc, test.patch, b, test.expected)
+		}
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	const original = `{"foo":{"bar":"baz"},"arr":[1,2],"s":"x"}`
+	for _, test := range []struct {
+		patch string
+		err   string
+	}{
+		{`[{"op":"add","path":"/baz/bat","value":"qux"}]`, `"/baz": property not found`},
+		{`[{"op":"add","path":"/arr/3","value":3}]`, `"/arr/3": invalid array index`},
+		{`[{"op":"add","path":"/s/a","value":3}]`, `"/s": not an object or array but string`},
+		{`[{"op":"add","path":"/arr/x","value":3}]`, `"/arr/x": invalid JSON pointer`},
+		{`[{"op":"remove","path":"/x"}]`, `"/x": property not found`},
+		{`[{"op":"replace","path":"/x","value":1}]`, `"/x": property not found`},
+		{`[{"op":"replace","path":"/arr/-","value":1}]`, `"/arr/-": invalid array index`},
+		{`[{"op":"move","from":"/foo","path":"/foo/bar"}]`, `"/foo/bar": invalid patch operation`},
+		{`[{"op":"copy","from":"/x","path":"/y"}]`, `"/x": property not found`},
+		{`[{"op":"copy","from":"x","path":"/y"}]`, `"x": invalid JSON pointer`},
+		{`[{"op":"test","path":"/arr","value":[1,2,3]}]`, `"/arr": test operation failed`},
+		{`[{"op":"test","path":"/s","value":null}]`, `"/s": test operation failed`},
+		{`[{"op":"foo","path":"/s"}]`, `"/s": invalid patch operation`},
+		{`[{"op":"add","path":"s","value":1}]`, `"s": invalid JSON pointer`},
+		// Atomicity
+		{`[
+			{"op":"add","path":"/arr/0","value":0},
+			{"op":"remove","path":"/foo/bar"},
+			{"op":"move","from":"/arr/2","path":"/foo/x"},
+			{"op":"copy","from":"/arr","path":"/arr/-"},
+			{"op":"replace","path":"/s","value":"y"},
+			{"op":"test","path":"/s","value":"x"}
+		]`, `"/s": test operation failed`},
+	} {
+		var patch []jsonptr.Operation
+		if err := json.Unmarshal([]byte(test.patch), &patch); err != nil {
+			t.Fatal(err)
+		}
+		doc, _ := jsonptr.UnmarshalOrdered([]byte(original))
+		err := jsonptr.ApplyPatch(&doc, patch)
+		if err == nil {
+			t.Errorf("%s: error expected", test.patch)
+			continue
+		}
+		if err.Error() != test.err {
+			t.Errorf("%s: got %q, expected %q", test.patch, err, test.err)
+		}
+		if b, _ := json.Marshal(doc); string(b) != original {
+			t.Errorf("%s: not restored: %s", test.patch, b)
+		}
+	}
+}
+
+func TestApplyPatchTyped(t *testing.T) {
+	typed := func() interface{} {
+		return map[string]interface{}{
+			"tags":   []string{"a", "b"},
+			"labels": map[string]string{"env": "prod"},
+		}
+	}
+	doc := typed()
+	err := jsonptr.ApplyPatch(&doc, []jsonptr.Operation{
+		{Op: "test", Path: "/tags", Value: []interface{}{"a", "b"}},
+		{Op: "test", Path: "/labels", Value: map[string]interface{}{"env": "prod"}},
+		{Op: "replace", Path: "/tags/0", Value: "z"},
+		{Op: "remove", Path: "/tags/1"},
+		{Op: "add", Path: "/labels/x", Value: "y"},
+		{Op: "test", Path: "/tags", Value: []string{"z"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := json.Marshal(doc); string(b) != `{"labels":{"env":"prod","x":"y"},"tags":["z"]}` {
+		t.Errorf("got %s", b)
+	}
+
+	// Rollback
+	doc = typed()
+	err = jsonptr.ApplyPatch(&doc, []jsonptr.Operation{
+		{Op: "replace", Path: "/tags/0", Value: "z"},
+		{Op: "remove", Path: "/tags/1"},
+		{Op: "remove", Path: "/labels/env"},
+		{Op: "test", Path: "/tags", Value: []interface{}{"a"}},
+	})
+	if err == nil {
+		t.Error("error expected")
+	}
+	if !reflect.DeepEqual(doc, typed()) {
+		t.Errorf("not restored: %#v", doc)
+	}
+}
+
+func TestStorePatch(t *testing.T) {
+	s := jsonptr.NewStore(map[string]interface{}{"a": []interface{}{"x", "y"}})
+	var events []string
+	s.Watch(jsonptr.Pointer{"a", "1"}, func(ev jsonptr.Event) {
+		events = append(events, fmt.Sprint(ev.Op, " ", ev.Ptr, " ", ev.New))
+	})
+	err := s.Update(func(tx *jsonptr.Tx) error {
+		return tx.Patch([]jsonptr.Operation{
+			{Op: "add", Path: "/a/0", Value: "w"},
+			{Op: "test", Path: "/a/1", Value: "x"},
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := s.Get("/a/1"); v != "x" {
+		t.Errorf("got %v", v)
+	}
+	if len(events) != 1 || events[0] != "add /a/0 w" {
+		t.Errorf("got %q", events)
+	}
+}
+
+func ExampleApplyPatch() {
+	doc, _ := jsonptr.UnmarshalOrdered([]byte(`{"name":"app","tags":["a","c"]}`))
+	var patch []jsonptr.Operation
+	_ = json.Unmarshal([]byte(`[
+		{"op":"test","path":"/name","value":"app"},
+		{"op":"add","path":"/tags/1","value":"b"},
+		{"op":"copy","from":"/name","path":"/title"}
+	]`), &patch)
+	if err := jsonptr.ApplyPatch(&doc, patch); err != nil {
+		fmt.Println(err)
+	}
+	b, _ := json.Marshal(doc)
+	fmt.Printf("%s\n", b)
+
+	err := jsonptr.ApplyPatch(&doc, []jsonptr.Operation{
+		{Op: "remove", Path: "/tags"},
+		{Op: "test", Path: "/name", Value: "other"},
+	})
+	fmt.Println(err)
+	b, _ = json.Marshal(doc)
+	fmt.Printf("%s\n", b)
+	// Output:
+	// {"name":"app","tags":["a","b","c"],"title":"app"}
+	// "/name": test operation failed
+	// {"name":"app","tags":["a","b","c"],"title":"app"}
+}
@@ -7,7 +7,6 @@ package jsonptr
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"strconv"
 	"strings"
 )
@@ -19,12 +18,14 @@ import (
 type Pointer []string
 
 // Parse parses a JSON pointer from its text representation.
+//
+// In case of error a *BadPointerError is returned.
 func Parse(pointer string) (Pointer, error) {
 	if pointer == "" {
 		return nil, nil
 	}
-	if pointer[0] != '/' {
-		return nil, ErrSyntax
+	if err := checkSyntax(pointer); err != nil {
+		return nil, err
 	}
 	ptr := strings.Split(pointer[1:], "/")
 	// Optimize for the common case
@@ -32,34 +33,37 @@ func Parse(pointer string) (Pointer, error) {
 		return ptr, nil
 	}
 	for i, part := range ptr {
-		var err error
-		if ptr[i], err = UnescapeString(part); err != nil {
-			// TODO return the full prefix
-			return nil, err
-		}
+		// No error as the syntax has been checked
+		ptr[i], _ = UnescapeString(part)
 	}
 	return ptr, nil
 }
 
 // MustParse wraps Parse and panics in case of error.
+// The panic value is the *BadPointerError returned by Parse.
 func MustParse(pointer string) Pointer {
 	ptr, err := Parse(pointer)
 	if err != nil {
-		panic(fmt.Errorf("%q: %v", pointer, err))
+		panic(err)
 	}
 	return ptr
 }
 
-// MarshalText implements [encoding.TextUnmarshaler].
+// UnmarshalText implements [encoding.TextUnmarshaler].
+//
+// In case of error a *BadPointerError is returned.
 func (ptr *Pointer) UnmarshalText(text []byte) error {
 	if len(text) == 0 {
 		*ptr = nil
 		return nil
 	}
-	if text[0] != '/' {
-		return ErrSyntax
+	if text[0] != '/' || bytes.IndexByte(text, '~') >= 0 {
+		if err := checkSyntax(string(text)); err != nil {
+			return err
+		}
 	}
 
+	// No unescaping error can happen as the syntax has been checked
 	var p Pointer
 	t := text[1:]
 	for {
@@ -67,17 +71,11 @@ func (ptr *Pointer) UnmarshalText(text []byte) error {
 		if i < 0 {
 			break
 		}
-		part, err := Unescape(t[:i])
-		if err != nil {
-			return syntaxError(string(text[:len(text)-len(t)-i-1]))
-		}
+		part, _ := Unescape(t[:i])
 		p = append(p, string(part))
 		t = t[i+1:]
 	}
-	part, err := Unescape(t)
-	if err != nil {
-		return syntaxError(string(text))
-	}
+	part, _ := Unescape(t)
 	*ptr = append(p, string(part))
 	return nil
 }
@@ -183,40 +181,71 @@ func (ptr Pointer) LeafIndex() (int, error) {
 
 // In returns the value from doc pointed by ptr.
 //
-// doc may be a deserialized document, or a [encoding/json.RawMessage].
+// doc may be a deserialized document, a [encoding/json.RawMessage], a [JSONC]
+// or a [CBOR] document.
 func (ptr Pointer) In(doc interface{}) (interface{}, error) {
 	for i, key := range ptr {
 		switch here := (doc).(type) {
 		case map[string]interface{}:
 			var ok bool
 			if doc, ok = here[key]; !ok {
-				return nil, propertyError(ptr[:i+1].String())
+				return nil, propertyError(ptr[:i+1].String(), key, mapKeys(here))
+			}
+		case *Object:
+			var ok bool
+			if doc, ok = here.Get(key); !ok {
+				return nil, propertyError(ptr[:i+1].String(), key, here.keys)
 			}
 		case []interface{}:
 			n, err := arrayIndex(key)
 			if err != nil || n < 0 || n >= len(here) {
-				return nil, indexError(ptr[:i+1].String())
+				return nil, indexError(ptr[:i+1].String(), len(here))
 			}
 			doc = here[n]
 		case JSONDecoder:
-			v, err := getJSON(here, ptr[i:].String())
+			v, err := getJSON(here, ptr[i:].String(), nil)
 			if err != nil {
 				err.rebase(ptr[:i].String())
 			}
 			return v, err
 		case json.RawMessage:
-			v, err := getRaw(here, ptr[i:].String())
+			v, err := getRaw(here, ptr[i:].String(), nil)
+			if err != nil {
+				err.rebase(ptr[:i].String())
+			}
+			return v, err
+		case JSONC:
+			v, err := getRaw(here.clean(), ptr[i:].String(), nil)
+			if err != nil {
+				err.rebase(ptr[:i].String())
+			}
+			return v, err
+		case CBOR:
+			v, err := getCBOR(here, ptr[i:].String(), nil)
 			if err != nil {
 				err.rebase(ptr[:i].String())
 			}
 			return v, err
 		default:
-			// We report the error at the upper level
-			return nil, docError(ptr[:i-1].String(), doc)
+			var ok bool
+			switch typedKind(here) {
+			case typedObject:
+				if doc, ok = getTypedKey(here, key); !ok {
+					return nil, propertyError(ptr[:i+1].String(), key, typedKeys(here))
+				}
+			case typedArray:
+				n, err := arrayIndex(key)
+				if doc, ok = getTypedIndex(here, n); err != nil || !ok {
+					return nil, indexError(ptr[:i+1].String(), typedLen(here))
+				}
+			default:
+				// We report the error at the upper level
+				return nil, docError(ptr[:i].String(), doc)
+			}
 		}
 	}
 
-	doc, err := getLeaf(doc)
+	doc, err := getLeaf(doc, nil)
 	if err != nil {
 		err.rebase(ptr.String())
 	}
@@ -5,6 +5,7 @@
 package jsonptr_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
@@ -15,25 +16,36 @@ import (
 
 var _ fmt.Stringer = jsonptr.Pointer{}
 
+func badPointer(badPtr, input string, offset, token int) *jsonptr.BadPointerError {
+	return &jsonptr.BadPointerError{
+		BadPtr: badPtr,
+		Err:    jsonptr.ErrSyntax,
+		Input:  input,
+		Offset: offset,
+		Token:  token,
+	}
+}
+
 var parseTests = [...]struct {
 	in  string
 	out jsonptr.Pointer
 	err error
 }{
 	{"", nil, nil},
-	{"a", nil, jsonptr.ErrSyntax},
-	{"~", nil, jsonptr.ErrSyntax},
+	{"a", nil, badPointer("a", "a", 0, -1)},
+	{"~", nil, badPointer("~", "~", 0, -1)},
 	{"/", jsonptr.Pointer{""}, nil},
 	{"////", jsonptr.Pointer{"", "", "", ""}, nil},
 	{"/a", jsonptr.Pointer{"a"}, nil},
-	{"/~", nil, &jsonptr.BadPointerError{"/~", jsonptr.ErrSyntax}},
-	{"/~x", nil, &jsonptr.BadPointerError{"/~x", jsonptr.ErrSyntax}},
-	{"/a~", nil, &jsonptr.BadPointerError{"/a~", jsonptr.ErrSyntax}},
-	{"/a~x", nil, &jsonptr.BadPointerError{"/a~x", jsonptr.ErrSyntax}},
-	{"/abc/~", nil, &jsonptr.BadPointerError{"/abc/~", jsonptr.ErrSyntax}},
-	{"/abc/~/b", nil, &jsonptr.BadPointerError{"/abc/~", jsonptr.ErrSyntax}},
-	{"/abc/~x", nil, &jsonptr.BadPointerError{"/abc/~x", jsonptr.ErrSyntax}},
-	{"/abc/a~", nil, &jsonptr.BadPointerError{"/abc/a~", jsonptr.ErrSyntax}},
+	{"/~", nil, badPointer("/~", "/~", 1, 0)},
+	{"/~x", nil, badPointer("/~x", "/~x", 1, 0)},
+	{"/a~", nil, badPointer("/a~", "/a~", 2, 0)},
+	{"/a~x", nil, badPointer("/a~x", "/a~x", 2, 0)},
+	{"/abc/~", nil, badPointer("/abc/~", "/abc/~", 5, 1)},
+	{"/abc/~/b", nil, badPointer("/abc/~", "/abc/~/b", 5, 1)},
+	{"/abc/~x", nil, badPointer("/abc/~x", "/abc/~x", 5, 1)},
+	{"/abc/a~", nil, badPointer("/abc/a~", "/abc/a~", 6, 1)},
+	{"/abc/~0~2/x", nil, badPointer("/abc/~0~2", "/abc/~0~2/x", 7, 1)},
 	{"/~0", jsonptr.Pointer{"~"}, nil},
 	{"/~1", jsonptr.Pointer{"/"}, nil},
 	{"/~0~0", jsonptr.Pointer{"~~"}, nil},
@@ -98,11 +110,7 @@ func TestPointerParse(t *testing.T) {
 					t.Errorf("roundtrip failure: got %q != %q", ptr, test.in)
 				}
 			} else if !reflect.DeepEqual(err, test.err) {
-				// TODO fix jsonptr.Parse to match UnmarshalText
-				t.Logf("error mismatch: want %T %q, got %T %q",
-					test.err, test.err,
-					err, err,
-				)
+				t.Errorf("error mismatch: want %#v, got %#v", test.err, err)
 			}
 		}
 	}
@@ -349,3 +357,43 @@ func TestPointerIn(t *testing.T) {
 		},
 	}).runTest()
 }
+
+// TestBadPointerConsistency checks that all entry points report the same
+// error for the same malformed pointer.
+func TestBadPointerConsistency(t *testing.T) {
+	for _, test := range parseTests {
+		if test.err == nil {
+			continue
+		}
+		t.Logf("%q", test.in)
+
+		var errs []error
+		_, err := jsonptr.Parse(test.in)
+		errs = append(errs, err)
+		var p jsonptr.Pointer
+		errs = append(errs, p.UnmarshalText([]byte(test.in)))
+		errs = append(errs, func() (err error) {
+			defer func() { err = recover().(error) }()
+			jsonptr.MustParse(test.in)
+			return nil
+		}())
+		for _, doc := range []interface{}{
+			map[string]interface{}{"abc": []interface{}{}},
+			json.RawMessage(`{"abc":[]}`),
+			json.NewDecoder(strings.NewReader(`{"abc":[]}`)),
+		} {
+			_, err = jsonptr.Get(doc, test.in)
+			errs = append(errs, err)
+		}
+		var doc interface{} = map[string]interface{}{"abc": []interface{}{}}
+		errs = append(errs, jsonptr.Set(&doc, test.in, nil))
+		_, err = jsonptr.Delete(&doc, test.in)
+		errs = append(errs, err)
+
+		for i, err := range errs {
+			if !reflect.DeepEqual(err, test.err) {
+				t.Errorf("%d: got %#v", i, err)
+			}
+		}
+	}
+}
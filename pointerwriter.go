@@ -0,0 +1,191 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// PointerWriter writes a JSON document from a sequence of values, each with
+// its location, in document order. The document is never built in memory:
+// objects and arrays are opened and closed as the location changes.
+//
+// Containers are created as needed. A container is an array if the token of
+// its first member is "0" (or "-"), an object otherwise. To write an empty
+// container, or an object whose first key is "0", write an empty
+// map[string]interface{}, []interface{} or *Object: the container is left
+// open for the values that follow at locations below it.
+//
+// Close must be called to terminate the document.
+type PointerWriter struct {
+	w      *bufio.Writer
+	levels []writerLevel
+	// path is the location of the open containers: path[i] is the token
+	// of levels[i+1] in levels[i]
+	path    Pointer
+	started bool
+	done    bool
+}
+
+type writerLevel struct {
+	array bool
+	// n is the number of members written
+	n int
+	// keys of an object
+	keys map[string]bool
+}
+
+// NewPointerWriter returns a PointerWriter writing to w.
+func NewPointerWriter(w io.Writer) *PointerWriter {
+	return &PointerWriter{w: bufio.NewWriter(w)}
+}
+
+// Write writes value at location ptr. value is serialized with
+// [encoding/json.Marshal].
+//
+// ptr must come after the previous locations in document order: members of an
+// object may come in any order but only once, elements of an array must come
+// in sequence (from index 0, without gaps). Otherwise a *PtrError is returned
+// (Err is ErrOrder, or ErrIndex with Len set to the expected index) and
+// nothing is written.
+func (pw *PointerWriter) Write(ptr Pointer, value interface{}) error {
+	if pw.done {
+		return &PtrError{Ptr: ptr.String(), Err: ErrOrder}
+	}
+	// m is the level of the container which will receive the new member
+	m := 0
+	for m < len(pw.path) && m < len(ptr) && ptr[m] == pw.path[m] {
+		m++
+	}
+	if pw.started && m == len(ptr) {
+		// The location is already written
+		return &PtrError{Ptr: ptr.String(), Err: ErrOrder}
+	}
+	if m < len(pw.levels) {
+		level := &pw.levels[m]
+		tok := ptr[m]
+		if level.array {
+			if tok != "-" {
+				if n, err := arrayIndex(tok); err != nil || n != level.n {
+					return indexError(ptr[:m+1].String(), level.n)
+				}
+			}
+		} else if level.keys[tok] {
+			return &PtrError{Ptr: ptr[:m+1].String(), Err: ErrOrder}
+		}
+	}
+
+	var data []byte
+	if !isEmptyContainer(value) {
+		var err error
+		if data, err = json.Marshal(value); err != nil {
+			return err
+		}
+	}
+
+	// Close the containers below the new member
+	for len(pw.levels) > m+1 {
+		pw.close()
+	}
+	// Open the intermediate containers
+	for i := m; i < len(ptr); i++ {
+		if i == len(pw.levels) {
+			pw.open(ptr[i])
+		}
+		pw.member(ptr[i])
+		if i+1 < len(ptr) {
+			pw.path = append(pw.path, ptr[i])
+		}
+	}
+	pw.started = true
+
+	if data == nil {
+		// Empty container: left open
+		if len(ptr) > 0 {
+			pw.path = append(pw.path, ptr[len(ptr)-1])
+		}
+		switch value.(type) {
+		case []interface{}:
+			pw.open("0")
+		default:
+			pw.open("")
+		}
+	} else {
+		_, _ = pw.w.Write(data)
+		if len(ptr) == 0 {
+			pw.done = true
+		}
+	}
+	// bufio.Writer errors are sticky
+	_, err := pw.w.Write(nil)
+	return err
+}
+
+// Close closes the open containers and flushes the output.
+// It does not close the underlying writer.
+func (pw *PointerWriter) Close() error {
+	for len(pw.levels) > 0 {
+		pw.close()
+	}
+	pw.done = true
+	return pw.w.Flush()
+}
+
+// open opens a container, whose first member is tok.
+func (pw *PointerWriter) open(tok string) {
+	array := tok == "0" || tok == "-"
+	if array {
+		_ = pw.w.WriteByte('[')
+		pw.levels = append(pw.levels, writerLevel{array: true})
+	} else {
+		_ = pw.w.WriteByte('{')
+		pw.levels = append(pw.levels, writerLevel{keys: make(map[string]bool)})
+	}
+}
+
+// member starts a new member of the innermost open container.
+func (pw *PointerWriter) member(tok string) {
+	level := &pw.levels[len(pw.levels)-1]
+	if level.n > 0 {
+		_ = pw.w.WriteByte(',')
+	}
+	level.n++
+	if !level.array {
+		level.keys[tok] = true
+		key, _ := json.Marshal(tok)
+		_, _ = pw.w.Write(key)
+		_ = pw.w.WriteByte(':')
+	}
+}
+
+// close closes the innermost open container.
+func (pw *PointerWriter) close() {
+	last := len(pw.levels) - 1
+	if pw.levels[last].array {
+		_ = pw.w.WriteByte(']')
+	} else {
+		_ = pw.w.WriteByte('}')
+	}
+	pw.levels = pw.levels[:last]
+	if last > 0 {
+		pw.path = pw.path[:last-1]
+	} else {
+		pw.done = true
+	}
+}
+
+func isEmptyContainer(v interface{}) bool {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	case *Object:
+		return v.Len() == 0
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+type pointerValue struct {
+	ptr   string
+	value interface{}
+}
+
+func TestPointerWriter(t *testing.T) {
+	for _, test := range []struct {
+		values   []pointerValue
+		expected string
+	}{
+		{nil, ``},
+		{[]pointerValue{{``, 1}}, `1`},
+		{[]pointerValue{{``, map[string]interface{}{"a": 1}}}, `{"a":1}`},
+		{[]pointerValue{{``, map[string]interface{}{}}}, `{}`},
+		{[]pointerValue{{``, []interface{}{}}}, `[]`},
+		{[]pointerValue{{`/a`, 1}, {`/b`, 2}}, `{"a":1,"b":2}`},
+		{[]pointerValue{{`/0`, 1}, {`/1`, 2}}, `[1,2]`},
+		{[]pointerValue{{`/-`, 1}, {`/-`, 2}, {`/2`, 3}}, `[1,2,3]`},
+		{[]pointerValue{{`/a/b/c`, 1}, {`/a/b/d`, 2}, {`/a/e/0`, 3}, {`/a/e/1/x`, 4}, {`/f`, nil}}, `{"a":{"b":{"c":1,"d":2},"e":[3,{"x":4}]},"f":null}`},
+		{[]pointerValue{{`/a`, []interface{}{}}, {`/b`, map[string]interface{}{}}}, `{"a":[],"b":{}}`},
+		{[]pointerValue{{`/a`, map[string]interface{}{}}, {`/a/0`, 1}}, `{"a":{"0":1}}`},
+		{[]pointerValue{{`/a`, []interface{}{}}, {`/a/0`, 1}}, `{"a":[1]}`},
+		{[]pointerValue{{``, jsonptr.NewObject()}, {`/0`, true}}, `{"0":true}`},
+		{[]pointerValue{{`/a~1b/c~0d`, "<"}}, `{"a/b":{"c~d":"\u003c"}}`},
+		{[]pointerValue{{`/1`, 1}}, `{"1":1}`},
+	} {
+		var out bytes.Buffer
+		pw := jsonptr.NewPointerWriter(&out)
+		for _, v := range test.values {
+			if err := pw.Write(jsonptr.MustParse(v.ptr), v.value); err != nil {
+				t.Errorf("%v: %q: %v", test.values, v.ptr, err)
+			}
+		}
+		if err := pw.Close(); err != nil {
+			t.Errorf("%v: %v", test.values, err)
+		}
+		if out.String() != test.expected {
+			t.Errorf("%v:\ngot      %s\nexpected %s", test.values, out.String(), test.expected)
+		}
+	}
+}
+
+func TestPointerWriterErrors(t *testing.T) {
+	for _, test := range []struct {
+		values   []pointerValue
+		errPtr   string
+		err      error
+		expected string
+	}{
+		{[]pointerValue{{``, 1}, {``, 2}}, ``, jsonptr.ErrOrder, `1`},
+		{[]pointerValue{{``, 1}, {`/a`, 2}}, `/a`, jsonptr.ErrOrder, `1`},
+		{[]pointerValue{{`/a`, 1}, {``, 2}}, ``, jsonptr.ErrOrder, `{"a":1}`},
+		{[]pointerValue{{`/a`, 1}, {`/a`, 2}}, `/a`, jsonptr.ErrOrder, `{"a":1}`},
+		{[]pointerValue{{`/a/b`, 1}, {`/c`, 2}, {`/a/d`, 3}}, `/a`, jsonptr.ErrOrder, `{"a":{"b":1},"c":2}`},
+		{[]pointerValue{{`/a`, 1}, {`/a/b`, 2}}, `/a`, jsonptr.ErrOrder, `{"a":1}`},
+		{[]pointerValue{{`/0`, 1}, {`/2`, 2}}, `/2`, jsonptr.ErrIndex, `[1]`},
+		{[]pointerValue{{`/0`, 1}, {`/0`, 2}}, `/0`, jsonptr.ErrIndex, `[1]`},
+		{[]pointerValue{{`/0/a`, 1}, {`/x`, 2}}, `/x`, jsonptr.ErrIndex, `[{"a":1}]`},
+		{[]pointerValue{{`/a`, []interface{}{}}, {`/a/1`, 2}}, `/a/1`, jsonptr.ErrIndex, `{"a":[]}`},
+	} {
+		var out bytes.Buffer
+		pw := jsonptr.NewPointerWriter(&out)
+		var err error
+		for _, v := range test.values {
+			if err = pw.Write(jsonptr.MustParse(v.ptr), v.value); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			t.Errorf("%v: error expected", test.values)
+			continue
+		}
+		t.Log(err)
+		if e, ok := err.(*jsonptr.PtrError); !ok {
+			t.Errorf("%v: PtrError expected, got %T", test.values, err)
+		} else if e.Ptr != test.errPtr || e.Err != test.err {
+			t.Errorf("%v: got %q %v, expected %q %v", test.values, e.Ptr, e.Err, test.errPtr, test.err)
+		}
+		if err = pw.Close(); err != nil {
+			t.Error(err)
+		}
+		if out.String() != test.expected {
+			t.Errorf("%v:\ngot      %s\nexpected %s", test.values, out.String(), test.expected)
+		}
+	}
+}
+
+func ExamplePointerWriter() {
+	pw := jsonptr.NewPointerWriter(os.Stdout)
+	_ = pw.Write(jsonptr.Pointer{"name"}, "jsonptr")
+	_ = pw.Write(jsonptr.Pointer{"tags", "0"}, "json")
+	_ = pw.Write(jsonptr.Pointer{"tags", "1"}, "rfc6901")
+	_ = pw.Write(jsonptr.Pointer{"owner", "login"}, "dolmen")
+	_ = pw.Close()
+	// Output:
+	// {"name":"jsonptr","tags":["json","rfc6901"],"owner":{"login":"dolmen"}}
+}
@@ -0,0 +1,550 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// This file implements navigation in serialized JSON documents
+// ([encoding/json.RawMessage]) by scanning bytes in place.
+//
+// Skipped values are validated like encoding/json does (literals, numbers,
+// escapes, separators), so that a malformed document is rejected whatever
+// the way it is given. The value extracted is fully decoded with
+// json.Unmarshal.
+
+// rawSkipSpace returns the index of the first non-whitespace byte at or after i.
+func rawSkipSpace(doc []byte, i int) int {
+	for i < len(doc) {
+		switch doc[i] {
+		case ' ', '\t', '\n', '\r':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// rawSkipString returns the index following the string starting at doc[i] (a '"').
+// ok is false if the string is not terminated, or if it has an invalid
+// escape sequence or a control character: the index is then still the end
+// of the string, for callers interested only in the structure.
+func rawSkipString(doc []byte, i int) (end int, ok bool) {
+	ok = true
+	for i++; i < len(doc); i++ {
+		switch c := doc[i]; {
+		case c == '"':
+			return i + 1, ok
+		case c == '\\':
+			i++
+			if i >= len(doc) {
+				return i, false
+			}
+			switch doc[i] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			case 'u':
+				if i+4 >= len(doc) {
+					return len(doc), false
+				}
+				for _, h := range doc[i+1 : i+5] {
+					if !('0' <= h && h <= '9' || 'a' <= h && h <= 'f' || 'A' <= h && h <= 'F') {
+						ok = false
+					}
+				}
+			default:
+				ok = false
+			}
+		case c < 0x20:
+			ok = false
+		}
+	}
+	return i, false
+}
+
+// rawSkipNumber returns the index following the number starting at doc[i].
+func rawSkipNumber(doc []byte, i int) (int, bool) {
+	if doc[i] == '-' {
+		i++
+	}
+	if i < len(doc) && doc[i] == '0' {
+		i++
+	} else if j := rawSkipDigits(doc, i); j > i {
+		i = j
+	} else {
+		return i, false
+	}
+	if i < len(doc) && doc[i] == '.' {
+		j := rawSkipDigits(doc, i+1)
+		if j == i+1 {
+			return j, false
+		}
+		i = j
+	}
+	if i < len(doc) && (doc[i] == 'e' || doc[i] == 'E') {
+		i++
+		if i < len(doc) && (doc[i] == '+' || doc[i] == '-') {
+			i++
+		}
+		j := rawSkipDigits(doc, i)
+		if j == i {
+			return j, false
+		}
+		i = j
+	}
+	return i, true
+}
+
+// rawSkipDigits returns the index of the first non-digit at or after i.
+func rawSkipDigits(doc []byte, i int) int {
+	for i < len(doc) && '0' <= doc[i] && doc[i] <= '9' {
+		i++
+	}
+	return i
+}
+
+// rawSkipScalar returns the index following the string, number, true, false
+// or null starting at doc[i].
+func rawSkipScalar(doc []byte, i int) (end int, ok bool) {
+	switch doc[i] {
+	case '"':
+		return rawSkipString(doc, i)
+	case 't':
+		end, ok = i+4, bytes.HasPrefix(doc[i:], []byte("true"))
+	case 'f':
+		end, ok = i+5, bytes.HasPrefix(doc[i:], []byte("false"))
+	case 'n':
+		end, ok = i+4, bytes.HasPrefix(doc[i:], []byte("null"))
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		end, ok = rawSkipNumber(doc, i)
+	default:
+		return i, false
+	}
+	if !ok {
+		return i, false
+	}
+	// A literal or a number must be followed by a delimiter
+	if end < len(doc) {
+		switch doc[end] {
+		case ',', '}', ']', ' ', '\t', '\n', '\r':
+		default:
+			return end, false
+		}
+	}
+	return end, true
+}
+
+// rawSkipKey returns the index of the value following the key starting at
+// doc[i], and the colon.
+func rawSkipKey(doc []byte, i int) (int, bool) {
+	if i >= len(doc) || doc[i] != '"' {
+		return i, false
+	}
+	i, ok := rawSkipString(doc, i)
+	if !ok {
+		return i, false
+	}
+	i = rawSkipSpace(doc, i)
+	if i >= len(doc) || doc[i] != ':' {
+		return i, false
+	}
+	return rawSkipSpace(doc, i+1), true
+}
+
+// rawSkipValue returns the index following the value starting at doc[i],
+// which is validated. Nesting is handled without recursion.
+//
+// If the value is malformed, ok is false and the index is where the error
+// was found: len(doc) if the value is truncated.
+func rawSkipValue(doc []byte, i int) (end int, ok bool) {
+	// The open containers: '{' or '['
+	var stackBuf [32]byte
+	stack := stackBuf[:0]
+	for {
+		if i >= len(doc) {
+			return i, false
+		}
+		// At the start of a value
+		switch c := doc[i]; c {
+		case '{', '[':
+			i = rawSkipSpace(doc, i+1)
+			if i < len(doc) && doc[i] == c+2 { // '}' or ']'
+				i++
+				break
+			}
+			stack = append(stack, c)
+			if c == '{' {
+				if i, ok = rawSkipKey(doc, i); !ok {
+					return i, false
+				}
+			}
+			continue
+		default:
+			if i, ok = rawSkipScalar(doc, i); !ok {
+				return i, false
+			}
+		}
+		// After a value: next member or element, or end of containers
+		for {
+			if len(stack) == 0 {
+				return i, true
+			}
+			i = rawSkipSpace(doc, i)
+			if i >= len(doc) {
+				return i, false
+			}
+			top := stack[len(stack)-1]
+			if doc[i] == top+2 {
+				stack = stack[:len(stack)-1]
+				i++
+				continue
+			}
+			if doc[i] != ',' {
+				return i, false
+			}
+			i = rawSkipSpace(doc, i+1)
+			if top == '{' {
+				if i, ok = rawSkipKey(doc, i); !ok {
+					return i, false
+				}
+			}
+			break
+		}
+	}
+}
+
+// rawDepthExceeds reports if arrays and objects are nested in doc deeper than max.
+func rawDepthExceeds(doc []byte, max int) bool {
+	if max < 0 {
+		return true
+	}
+	depth := 0
+	for i := 0; i < len(doc); i++ {
+		switch doc[i] {
+		case '"':
+			end, _ := rawSkipString(doc, i)
+			i = end - 1
+		case '{', '[':
+			depth++
+			if depth > max {
+				return true
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return false
+}
+
+// rawKeyEqual compares the content of a serialized JSON string (without the quotes)
+// with an unescaped property name.
+func rawKeyEqual(rawKey []byte, key string) bool {
+	if bytes.IndexByte(rawKey, '\\') < 0 && utf8.Valid(rawKey) {
+		return string(rawKey) == key
+	}
+	return rawKeyString(rawKey) == key
+}
+
+// rawKeyString decodes the content of a serialized JSON string (without the quotes).
+func rawKeyString(rawKey []byte) string {
+	if bytes.IndexByte(rawKey, '\\') < 0 && utf8.Valid(rawKey) {
+		return string(rawKey)
+	}
+	quoted := make([]byte, 0, len(rawKey)+2)
+	quoted = append(append(append(quoted, '"'), rawKey...), '"')
+	var s string
+	// An invalid escape will be reported when the value is decoded
+	_ = json.Unmarshal(quoted, &s)
+	return s
+}
+
+// rawObjectKeys returns the keys of the well-formed object starting at doc[i].
+func rawObjectKeys(doc []byte, i int) []string {
+	var keys []string
+	for {
+		i = rawSkipSpace(doc, i+1)
+		if doc[i] != '"' {
+			return keys
+		}
+		end, _ := rawSkipString(doc, i)
+		keys = append(keys, rawKeyString(doc[i+1:end-1]))
+		i = rawSkipSpace(doc, end)
+		i, _ = rawSkipValue(doc, rawSkipSpace(doc, i+1))
+		i = rawSkipSpace(doc, i)
+		if doc[i] != ',' {
+			return keys
+		}
+	}
+}
+
+// locateRaw returns the bounds of the value pointed by ptr in doc.
+//
+// If doc is not well-formed JSON, start is -1 and err is nil: the caller
+// should use the json.Decoder based implementation to report the error.
+func locateRaw(doc []byte, ptr string, opts *Options) (start int, end int, err ptrError) {
+	i := rawSkipSpace(doc, 0)
+	if len(ptr) == 0 {
+		end, ok := rawSkipValue(doc, i)
+		if !ok {
+			return -1, -1, nil
+		}
+		return i, end, nil
+	}
+
+	p := int(1)
+	cur := ptr[1:]
+	for {
+		q := strings.IndexByte(cur, '/')
+		if q != -1 {
+			cur = cur[:q]
+		} else {
+			q = len(cur)
+		}
+		p += q
+
+		if i >= len(doc) {
+			return -1, -1, nil
+		}
+		switch doc[i] {
+		case '{':
+			key, err := UnescapeString(cur)
+			if err != nil {
+				return -1, -1, tokenError(ptr, p, err)
+			}
+			var seen map[string]bool
+			if opts.disallowDuplicateKeys() {
+				seen = make(map[string]bool)
+			}
+			// As json.Unmarshal keeps the last value in case of duplicate
+			// keys, we have to scan the whole object to find the last one.
+			found := -1
+			obj := i
+			i = rawSkipSpace(doc, i+1)
+			if i < len(doc) && doc[i] == '}' {
+				return -1, -1, propertyError(ptr[:p], key, nil)
+			}
+			for {
+				if i >= len(doc) || doc[i] != '"' {
+					return -1, -1, nil
+				}
+				keyEnd, ok := rawSkipString(doc, i)
+				if !ok {
+					return -1, -1, nil
+				}
+				rawKey := doc[i+1 : keyEnd-1]
+				if seen != nil {
+					k := rawKeyString(rawKey)
+					if seen[k] {
+						return -1, -1, duplicateKeyError(ptr[:p-q] + EscapeString(k))
+					}
+					seen[k] = true
+				}
+				i = rawSkipSpace(doc, keyEnd)
+				if i >= len(doc) || doc[i] != ':' {
+					return -1, -1, nil
+				}
+				i = rawSkipSpace(doc, i+1)
+				if rawKeyEqual(rawKey, key) {
+					found = i
+				}
+				if i, ok = rawSkipValue(doc, i); !ok {
+					return -1, -1, nil
+				}
+				i = rawSkipSpace(doc, i)
+				if i >= len(doc) {
+					return -1, -1, nil
+				}
+				if doc[i] == '}' {
+					break
+				}
+				if doc[i] != ',' {
+					return -1, -1, nil
+				}
+				i = rawSkipSpace(doc, i+1)
+			}
+			if found < 0 {
+				return -1, -1, propertyError(ptr[:p], key, rawObjectKeys(doc, obj))
+			}
+			i = found
+		case '[':
+			n, err := arrayIndex(cur)
+			if err != nil {
+				return -1, -1, tokenError(ptr, p, err)
+			}
+			if n < 0 {
+				return -1, -1, indexError(ptr[:p], -1)
+			}
+			i = rawSkipSpace(doc, i+1)
+			if i < len(doc) && doc[i] == ']' {
+				return -1, -1, indexError(ptr[:p], 0)
+			}
+			for j := 0; j < n; j++ {
+				var ok bool
+				if i, ok = rawSkipValue(doc, i); !ok {
+					return -1, -1, nil
+				}
+				i = rawSkipSpace(doc, i)
+				if i >= len(doc) {
+					return -1, -1, nil
+				}
+				if doc[i] == ']' {
+					return -1, -1, indexError(ptr[:p], j+1)
+				}
+				if doc[i] != ',' {
+					return -1, -1, nil
+				}
+				i = rawSkipSpace(doc, i+1)
+			}
+		default:
+			end, ok := rawSkipValue(doc, i)
+			if !ok {
+				return -1, -1, nil
+			}
+			var v interface{}
+			if err := json.Unmarshal(doc[i:end], &v); err != nil {
+				return -1, -1, nil
+			}
+			return -1, -1, docError(ptr[:p-q-1], v)
+		}
+
+		p++
+		if p > len(ptr) {
+			break
+		}
+		cur = ptr[p:]
+	}
+
+	end, ok := rawSkipValue(doc, i)
+	if !ok {
+		return -1, -1, nil
+	}
+	return i, end, nil
+}
+
+// Locate returns the bounds of the value pointed by ptr in the serialized
+// JSON document doc: the value is doc[start:end].
+//
+// In case of error a BadPointerError, a PtrError or a DocumentError is returned.
+func Locate(doc []byte, ptr string) (start int, end int, err error) {
+	if err := checkSyntax(ptr); err != nil {
+		return -1, -1, err
+	}
+	return locate(doc, ptr)
+}
+
+// GetRaw returns the serialized value pointed by ptr in the serialized JSON
+// document doc, without decoding it. The result is a slice of doc.
+//
+// In case of error a BadPointerError, a PtrError or a DocumentError is returned.
+func GetRaw(doc []byte, ptr string) (json.RawMessage, error) {
+	start, end, err := Locate(doc, ptr)
+	if err != nil {
+		return nil, err
+	}
+	return doc[start:end], nil
+}
+
+// locate is like Locate, with a pointer already checked.
+func locate(doc []byte, ptr string) (int, int, error) {
+	// The whole document is validated as locateRaw doesn't scan what
+	// follows the value found
+	if !json.Valid(doc) {
+		var v interface{}
+		return -1, -1, jsonError("", json.Unmarshal(doc, &v))
+	}
+	start, end, err := locateRaw(doc, ptr, nil)
+	if err != nil {
+		return -1, -1, err
+	}
+	return start, end, nil
+}
+
+// GetRawAll is like [GetRaw] for several pointers, scanning doc only once.
+//
+// values[i] is the value pointed by ptrs[i], or nil if there is none: err is
+// then an [Errors] with the reason for each missing value, in the order of
+// ptrs. Other errors are a *BadPointerError for an invalid pointer and a
+// *DocumentError for a malformed document (values is then nil).
+func GetRawAll(doc []byte, ptrs []string) (values []json.RawMessage, err error) {
+	for _, ptr := range ptrs {
+		if err := checkSyntax(ptr); err != nil {
+			return nil, err
+		}
+	}
+	if !json.Valid(doc) {
+		var v interface{}
+		return nil, jsonError("", json.Unmarshal(doc, &v))
+	}
+	values = make([]json.RawMessage, len(ptrs))
+	want := make([]int, len(ptrs))
+	for i := range want {
+		want[i] = i
+	}
+	rawScan(doc, rawSkipSpace(doc, 0), "", ptrs, want, values)
+
+	var errs Errors
+	for i, v := range values {
+		if v == nil {
+			_, _, err := locateRaw(doc, ptrs[i], nil)
+			errs.Append(err)
+		}
+	}
+	return values, errs.Err()
+}
+
+// rawScan stores in values the values pointed by ptrs[want[...]] in the
+// valid JSON value at doc[i], located at path. It returns the index
+// following the value.
+func rawScan(doc []byte, i int, path string, ptrs []string, want []int, values []json.RawMessage) int {
+	end, _ := rawSkipValue(doc, i)
+	var deeper []int
+	for _, w := range want {
+		if len(ptrs[w]) == len(path) {
+			values[w] = doc[i:end:end]
+		} else {
+			deeper = append(deeper, w)
+		}
+	}
+	if len(deeper) == 0 || (doc[i] != '{' && doc[i] != '[') {
+		return end
+	}
+	isObject := doc[i] == '{'
+	j := rawSkipSpace(doc, i+1)
+	for n := 0; doc[j] != '}' && doc[j] != ']'; n++ {
+		var tok string
+		if isObject {
+			keyEnd, _ := rawSkipString(doc, j)
+			tok = EscapeString(rawKeyString(doc[j+1 : keyEnd-1]))
+			j = rawSkipSpace(doc, rawSkipSpace(doc, keyEnd)+1)
+		} else {
+			tok = strconv.Itoa(n)
+		}
+		child := path + "/" + tok
+		var sub []int
+		for _, w := range deeper {
+			if p := ptrs[w]; strings.HasPrefix(p, child) && (len(p) == len(child) || p[len(child)] == '/') {
+				sub = append(sub, w)
+			}
+		}
+		if len(sub) > 0 {
+			// As json.Unmarshal, the last of duplicate keys wins
+			for _, w := range sub {
+				values[w] = nil
+			}
+			j = rawScan(doc, j, child, ptrs, sub, values)
+		} else {
+			j, _ = rawSkipValue(doc, j)
+		}
+		if j = rawSkipSpace(doc, j); doc[j] == ',' {
+			j = rawSkipSpace(doc, j+1)
+		}
+	}
+	return end
+}
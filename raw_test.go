@@ -0,0 +1,282 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetRawScanner(t *testing.T) {
+	for _, test := range []struct {
+		json     string
+		ptr      string
+		expected interface{}
+	}{
+		{` "x" `, ``, "x"},
+		{`{"a" : [ 1 , "]}" , {"b":"\"{["} ] }`, `/a/2/b`, `"{[`},
+		{`{"a\"b":1,"a":2}`, `/a"b`, float64(1)},
+		{`{"a":1}`, `/a`, float64(1)},
+		{`{"\/":1}`, `/~1`, float64(1)},
+		{`{"é":1,"é":2}`, `/é`, float64(2)},
+		{`{"a":{"x":[]},"b":[[],{}],"c":null}`, `/c`, nil},
+		{`{"a":{"x":[]},"b":[[],{}],"c":null}`, `/b/1`, map[string]interface{}{}},
+		{"[\n\t1,\r\n2\n]", `/1`, float64(2)},
+		{`[-1.5e3,true,false,null]`, `/0`, float64(-1500)},
+	} {
+		got, err := Get(json.RawMessage(test.json), test.ptr)
+		if err != nil {
+			t.Errorf("%s %q: %v", test.json, test.ptr, err)
+		} else if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s %q: got %#v", test.json, test.ptr, got)
+		}
+	}
+}
+
+func TestGetRawScannerErrors(t *testing.T) {
+	for _, test := range []struct {
+		json string
+		ptr  string
+	}{
+		{`{"a":1`, `/a`},
+		{`{"a" 1}`, `/a`},
+		{`{"a":1 "b":2}`, `/b`},
+		{`{a:1}`, `/a`},
+		{`{"a":"1}`, `/a`},
+		{`[1 2]`, `/1`},
+		{`[1,`, `/1`},
+		{`{"a":[1,2}`, `/a`},
+		{`{"a":x}`, `/a`},
+		{``, `/a`},
+		{``, ``},
+		{`"a`, ``},
+		// Skipped values are validated
+		{`{"a":tru,"b":1}`, `/b`},
+		{`{"b":1,"a":"\x"}`, `/b`},
+		{`{"a":"\u12G4","b":1}`, `/b`},
+		{"{\"a\":\"\x01\",\"b\":1}", `/b`},
+		{`{"a":01,"b":1}`, `/b`},
+		{`{"a":1.,"b":1}`, `/b`},
+		{`{"a":-,"b":1}`, `/b`},
+		{`{"a":1e+,"b":1}`, `/b`},
+		{`{"a":truex,"b":1}`, `/b`},
+		{`{"a":1,,"b":1}`, `/b`},
+		{`{"a":1,"b":1,}`, `/b`},
+		{`{"a":[1,2},"b":1}`, `/b`},
+		{`{"a":{"x"},"b":1}`, `/b`},
+		{`{"a":{1:2},"b":1}`, `/b`},
+		{`[[1,],1]`, `/1`},
+		{`[{"a":1,},1]`, `/1`},
+	} {
+		v, err := Get(json.RawMessage(test.json), test.ptr)
+		if err == nil {
+			t.Errorf("%s %q: error expected, got %#v", test.json, test.ptr, v)
+			continue
+		}
+		t.Logf("%s %q: %v", test.json, test.ptr, err)
+		if _, ok := err.(*DocumentError); !ok {
+			t.Errorf("%s %q: DocumentError expected, got %T", test.json, test.ptr, err)
+		}
+		// Same result as the decoder
+		if v, err := Get(json.NewDecoder(strings.NewReader(test.json)), test.ptr); err == nil {
+			t.Errorf("%s %q: decoder: error expected, got %#v", test.json, test.ptr, v)
+		}
+	}
+
+	// Valid values are accepted
+	for _, doc := range []string{
+		`{"a":true,"b":1}`, `{"a":"\u12aF\n\/","b":1}`, `{"a":-0.5e+10,"b":1}`, `{"a":[ {} , [ ] ],"b":1}`, `{"a":"é","b":1 }`,
+	} {
+		if v, err := Get(json.RawMessage(doc), "/b"); err != nil || v != 1.0 {
+			t.Errorf("%s: got %v, %v", doc, v, err)
+		}
+	}
+}
+
+func TestLocateRawAllocs(t *testing.T) {
+	doc := []byte(`{"a":[0,{"b":"x","c":{"d":[true,false]}}],"e":{}}`)
+	allocs := testing.AllocsPerRun(100, func() {
+		start, end, err := locateRaw(doc, "/a/1/c/d/1", nil)
+		if err != nil || string(doc[start:end]) != "false" {
+			panic("unexpected result")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocations", allocs)
+	}
+}
+
+// largeDocument builds a JSON document with n elements in an array under "/items".
+func largeDocument(n int) json.RawMessage {
+	var buf bytes.Buffer
+	buf.WriteString(`{"meta":{"count":`)
+	fmt.Fprint(&buf, n)
+	buf.WriteString(`},"items":[`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, `{"id":%d,"name":"item \"%d\"","tags":["a","b","c"],"attrs":{"x":%d.5,"y":null,"z":[[],{}]}}`, i, i, i)
+	}
+	buf.WriteString(`],"last":true}`)
+	return buf.Bytes()
+}
+
+// getRawBaseline is the implementation of Get for json.RawMessage used
+// before the scanner, for comparison in BenchmarkGetRaw: navigation with
+// json.Decoder, stopping at the first matching key.
+func getRawBaseline(doc json.RawMessage, ptr string) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	for _, key := range strings.Split(ptr[1:], "/") {
+		tok, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch tok {
+		case json.Delim('{'):
+			key, _ = UnescapeString(key)
+			for {
+				tok, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				if tok == key {
+					break
+				}
+				var skip json.RawMessage
+				if err = decoder.Decode(&skip); err != nil {
+					return nil, err
+				}
+			}
+		case json.Delim('['):
+			n, _ := arrayIndex(key)
+			for i := 0; i < n; i++ {
+				var skip json.RawMessage
+				if err = decoder.Decode(&skip); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	var value interface{}
+	err := decoder.Decode(&value)
+	return value, err
+}
+
+func BenchmarkGetRaw(b *testing.B) {
+	for _, n := range []int{10, 1000, 100000} {
+		doc := largeDocument(n)
+		for _, ptr := range []string{
+			"/last",
+			fmt.Sprintf("/items/%d/attrs/x", n-1),
+		} {
+			b.Run(fmt.Sprintf("%d/%s/scanner", n, strings.Replace(ptr, "/", "_", -1)), func(b *testing.B) {
+				b.SetBytes(int64(len(doc)))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := Get(doc, ptr); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run(fmt.Sprintf("%d/%s/baseline", n, strings.Replace(ptr, "/", "_", -1)), func(b *testing.B) {
+				b.SetBytes(int64(len(doc)))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := getRawBaseline(doc, ptr); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestLocate(t *testing.T) {
+	doc := []byte(`{"a": [1, {"b" : "x"}], "c": null }`)
+	for _, test := range []struct {
+		ptr      string
+		expected string
+	}{
+		{``, `{"a": [1, {"b" : "x"}], "c": null }`},
+		{`/a`, `[1, {"b" : "x"}]`},
+		{`/a/1`, `{"b" : "x"}`},
+		{`/a/1/b`, `"x"`},
+		{`/c`, `null`},
+	} {
+		start, end, err := Locate(doc, test.ptr)
+		if err != nil {
+			t.Errorf("%q: %v", test.ptr, err)
+			continue
+		}
+		if string(doc[start:end]) != test.expected {
+			t.Errorf("%q: got %s", test.ptr, doc[start:end])
+		}
+		raw, err := GetRaw(doc, test.ptr)
+		if err != nil || string(raw) != test.expected {
+			t.Errorf("%q: GetRaw: got %s, %v", test.ptr, raw, err)
+		}
+	}
+
+	for _, test := range []struct {
+		doc string
+		ptr string
+		err interface{}
+	}{
+		{`{"a":1}`, `a`, &BadPointerError{}},
+		{`{"a":1}`, `/b`, &PtrError{}},
+		{`{"a":[1]}`, `/a/1`, &PtrError{}},
+		{`{"a":1}`, `/a/b`, &DocumentError{}},
+		{`{"a":1,"b":[x]}`, `/a`, &DocumentError{}},
+		{`{"a":1} 2`, ``, &DocumentError{}},
+	} {
+		_, _, err := Locate([]byte(test.doc), test.ptr)
+		t.Logf("%s %q: %v", test.doc, test.ptr, err)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.err) {
+			t.Errorf("%s %q: %T expected, got %T", test.doc, test.ptr, test.err, err)
+		}
+	}
+}
+
+func TestGetRawAll(t *testing.T) {
+	doc := []byte(`{"a": [1, {"b" : "x"}], "c": null, "d/e": {"f": 1, "f": [2]}, "g": {"h": 1}, "g": 3 }`)
+	ptrs := []string{``, `/a/1/b`, `/a`, `/c`, `/d~1e/f/0`, `/a/2`, `/x`, `/a/1/b`, `/g/h`, `/g`, `/a/-`}
+	expected := []string{string(doc), `"x"`, `[1, {"b" : "x"}]`, `null`, `2`, ``, ``, `"x"`, ``, `3`, ``}
+	values, err := GetRawAll(doc, ptrs)
+	for i, v := range values {
+		if string(v) != expected[i] {
+			t.Errorf("%q: got %s, expected %s", ptrs[i], v, expected[i])
+		}
+	}
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("got %T %v", err, err)
+	}
+	// Same errors as Locate
+	var expectedErrs []string
+	for _, ptr := range []string{`/a/2`, `/x`, `/g/h`, `/a/-`} {
+		_, _, err := Locate(doc, ptr)
+		expectedErrs = append(expectedErrs, err.Error())
+	}
+	if len(errs) != len(expectedErrs) {
+		t.Fatalf("got %v", errs)
+	}
+	for i, e := range errs {
+		if e.Error() != expectedErrs[i] {
+			t.Errorf("got %q, expected %q", e, expectedErrs[i])
+		}
+	}
+
+	if _, err := GetRawAll(doc, []string{"/a", "b"}); err == nil {
+		t.Error("error expected")
+	}
+	if _, err := GetRawAll([]byte(`{"a":1`), []string{"/a"}); err == nil {
+		t.Error("error expected")
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// Record is a JSON value read from a stream by [ExtractRecords].
+type Record struct {
+	// Line is the line number (starting at 1) where the record starts.
+	Line int
+	// Offset is the byte offset where the record starts.
+	Offset int64
+	// Raw is the record. It is only valid during the call to the callback
+	// of ExtractRecords.
+	Raw json.RawMessage
+	// Values holds the values extracted, one for each pointer
+	// (nil if the value could not be extracted).
+	Values []interface{}
+	// Err is a *DocumentError if the record is malformed (Raw and Values are
+	// then nil), or an [Errors] with an error for each pointer that could
+	// not be extracted.
+	Err error
+}
+
+// defaultMaxRecordSize is the maximum size of a record read by
+// ExtractRecords if Options.MaxBytes is not set.
+const defaultMaxRecordSize = 16 << 20
+
+func (opts *Options) maxRecordSize() int64 {
+	if n := opts.maxBytes(); n > 0 {
+		return n
+	}
+	return defaultMaxRecordSize
+}
+
+// ExtractRecords reads a stream of JSON values, such as JSON Lines (NDJSON)
+// or concatenated JSON, and calls fn with the values extracted from each
+// record at the locations given by ptrs (with [Get] on a [JSONDecoder]).
+//
+// A malformed record does not stop the stream: it is reported to fn with
+// Record.Err set, and reading resumes at the next line. A record is
+// malformed as soon as it can't be valid JSON, so an unterminated string or
+// container does not swallow the rest of the stream. A record larger than
+// 16 MiB is reported as a *DocumentError wrapping [ErrLimit].
+//
+// The iteration stops at the end of r, at the first error from fn (returned)
+// or at the first error reading r (returned).
+func ExtractRecords(r io.Reader, ptrs []string, fn func(rec *Record) error) error {
+	return (*Options)(nil).ExtractRecords(r, ptrs, fn)
+}
+
+// ExtractRecords is like the [ExtractRecords] function, with options.
+// MaxBytes is the maximum size of a record (16 MiB if not set), and the
+// other options apply to the extraction of values from each record.
+func (opts *Options) ExtractRecords(r io.Reader, ptrs []string, fn func(rec *Record) error) error {
+	for _, ptr := range ptrs {
+		if err := opts.checkPointer(ptr); err != nil {
+			return err
+		}
+	}
+	maxSize := opts.maxRecordSize()
+
+	br := bufio.NewReader(r)
+	var (
+		buf    []byte
+		pos    int   // start of the unprocessed bytes in buf
+		line   = 1   // line number at pos
+		offset int64 // offset of pos in r
+		eof    bool
+	)
+	// advance moves pos to end, tracking lines
+	advance := func(end int) {
+		line += bytes.Count(buf[pos:end], []byte{'\n'})
+		offset += int64(end - pos)
+		pos = end
+	}
+	// readChunk appends the next line of r to buf, or its next chunk if
+	// the line is longer than the buffer of br
+	readChunk := func() error {
+		// Drop the processed bytes
+		if pos > 0 {
+			buf = buf[:copy(buf, buf[pos:])]
+			pos = 0
+		}
+		chunk, err := br.ReadSlice('\n')
+		buf = append(buf, chunk...)
+		switch err {
+		case nil, bufio.ErrBufferFull:
+			return nil
+		case io.EOF:
+			eof = true
+			return nil
+		default:
+			return err
+		}
+	}
+	// skipLine moves pos to the end of the line of buf[i], discarding the
+	// input not yet read up to the delimiter
+	skipLine := func(i int) error {
+		if eol := bytes.IndexByte(buf[i:], '\n'); eol >= 0 {
+			advance(i + eol)
+			return nil
+		}
+		advance(len(buf))
+		for !eof {
+			chunk, err := br.ReadSlice('\n')
+			offset += int64(len(chunk))
+			switch err {
+			case nil:
+				line++
+				return nil
+			case bufio.ErrBufferFull:
+			case io.EOF:
+				eof = true
+			default:
+				return err
+			}
+		}
+		return nil
+	}
+
+	var scanner valueScanner
+	for {
+		i := rawSkipSpace(buf, pos)
+		if i == len(buf) {
+			advance(i)
+			if eof {
+				return nil
+			}
+			if err := readChunk(); err != nil {
+				return err
+			}
+			continue
+		}
+		advance(i)
+		// A record followed by other bytes in buf is complete, else it is
+		// scanned again with the scanner, which resumes at each read
+		end, ok := rawSkipValue(buf, i)
+		status := scanDone
+		if !ok || end == len(buf) {
+			scanner.reset()
+			end, status = scanner.scan(buf, i)
+		}
+		for status == scanMore && !eof && int64(end-pos) <= maxSize {
+			// Incomplete: the record spans multiple lines or chunks
+			if err := readChunk(); err != nil {
+				return err
+			}
+			// The scan resumes where it stopped
+			end, status = scanner.scan(buf, end)
+		}
+		i = pos
+		if status == scanMore && eof {
+			status = scanner.finish()
+		}
+
+		rec := Record{Line: line, Offset: offset}
+		if status == scanDone && int64(end-i) <= maxSize {
+			rec.Raw = buf[i:end]
+			rec.Values = make([]interface{}, len(ptrs))
+			var errs Errors
+			for j, ptr := range ptrs {
+				var err error
+				rec.Values[j], err = opts.Get(json.NewDecoder(bytes.NewReader(rec.Raw)), ptr)
+				errs.Append(err)
+			}
+			rec.Err = errs.Err()
+			advance(end)
+		} else {
+			// Malformed: report the error and skip the rest of the line
+			if status == scanDone || status == scanMore && !eof {
+				rec.Err = limitError("")
+			} else {
+				if end < len(buf) {
+					end++
+				}
+				var v interface{}
+				rec.Err = jsonError("", json.Unmarshal(buf[i:end], &v))
+			}
+			if err := skipLine(i); err != nil {
+				return err
+			}
+		}
+		if err := fn(&rec); err != nil {
+			return err
+		}
+	}
+}
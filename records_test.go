@@ -0,0 +1,208 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+func TestExtractRecords(t *testing.T) {
+	const input = `{"level":"info","msg":"start","ctx":{"id":1}}
+{"level":"warn","msg":"slow"}
+{"level":"error","msg":
+
+{"level":"info","msg":"multi",
+ "ctx":{"id":4}}{"level":"debug","ctx":{"id":5}} [] "x"
+not json
+{"level":"info","ctx":3}
+{"level":"info","msg":"truncated"`
+
+	type result struct {
+		line   int
+		offset int64
+		values []interface{}
+		errPtr []string
+	}
+	var got []result
+	err := jsonptr.ExtractRecords(strings.NewReader(input), []string{"/level", "/ctx/id"}, func(rec *jsonptr.Record) error {
+		r := result{line: rec.Line, offset: rec.Offset, values: rec.Values}
+		switch e := rec.Err.(type) {
+		case nil:
+		case jsonptr.Errors:
+			for _, e := range e {
+				r.errPtr = append(r.errPtr, e.Ptr.String())
+			}
+		case *jsonptr.DocumentError:
+			t.Logf("line %d: %v", rec.Line, e)
+			if rec.Values != nil || rec.Raw != nil {
+				t.Errorf("line %d: unexpected values", rec.Line)
+			}
+			r.errPtr = []string{"malformed"}
+		default:
+			t.Errorf("line %d: unexpected error %T", rec.Line, rec.Err)
+		}
+		got = append(got, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []result{
+		{1, 0, []interface{}{"info", float64(1)}, nil},
+		{2, 46, []interface{}{"warn", nil}, []string{"/ctx"}},
+		{3, 76, nil, []string{"malformed"}},
+		{5, 101, []interface{}{"info", float64(4)}, nil},
+		{6, 148, []interface{}{"debug", float64(5)}, nil},
+		{6, 181, []interface{}{nil, nil}, []string{"/level", "/ctx"}},
+		{6, 184, []interface{}{nil, nil}, []string{"", ""}},
+		{7, 188, nil, []string{"malformed"}},
+		{8, 197, []interface{}{"info", nil}, []string{"/ctx"}},
+		{9, 222, nil, []string{"malformed"}},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got:\n%v\nexpected:\n%v", got, expected)
+	}
+}
+
+func TestExtractRecordsErrors(t *testing.T) {
+	err := jsonptr.ExtractRecords(strings.NewReader(`1`), []string{"a"}, func(*jsonptr.Record) error {
+		t.Error("unexpected call")
+		return nil
+	})
+	if _, ok := err.(*jsonptr.BadPointerError); !ok {
+		t.Errorf("BadPointerError expected, got %v", err)
+	}
+
+	stop := errors.New("stop")
+	n := 0
+	err = jsonptr.ExtractRecords(strings.NewReader("1\n2\n3\n"), nil, func(*jsonptr.Record) error {
+		n++
+		if n == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || n != 2 {
+		t.Errorf("got %v after %d records", err, n)
+	}
+}
+
+func TestExtractRecordsLarge(t *testing.T) {
+	const n = 50000
+	count := func(input string) (records []*jsonptr.Record) {
+		err := jsonptr.ExtractRecords(strings.NewReader(input), []string{"/a"}, func(rec *jsonptr.Record) error {
+			rec.Raw = nil
+			records = append(records, rec)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	// A record on many lines is scanned once
+	records := count("[" + strings.Repeat("1,\n", n) + "1]\n")
+	if len(records) != 1 || records[0].Values == nil {
+		t.Fatalf("got %d records", len(records))
+	}
+
+	// An incomplete record doesn't swallow the following lines
+	for _, prefix := range []string{`{"a":`, `["x",`, `"abc`, `{"a":[1,2`, `{`} {
+		records := count(prefix + "\n" + strings.Repeat("{\"a\":1}\n", n))
+		if len(records) != n+1 {
+			t.Errorf("%s: got %d records", prefix, len(records))
+			continue
+		}
+		if _, ok := records[0].Err.(*jsonptr.DocumentError); !ok {
+			t.Errorf("%s: got %v", prefix, records[0].Err)
+		}
+		if last := records[n]; last.Line != n+1 || last.Err != nil || last.Values[0] != 1.0 {
+			t.Errorf("%s: got %#v", prefix, last)
+		}
+	}
+
+	// Records are limited to 16 MiB
+	records = count(`"` + strings.Repeat("a", 17<<20) + "\"\n{\"a\":2}\n")
+	if len(records) != 2 {
+		t.Fatalf("got %d records", len(records))
+	}
+	if e, ok := records[0].Err.(*jsonptr.DocumentError); !ok || e.Err != jsonptr.ErrLimit {
+		t.Errorf("got %v", records[0].Err)
+	}
+	if records[1].Line != 2 || records[1].Values[0] != 2.0 {
+		t.Errorf("got %#v", records[1])
+	}
+}
+
+func TestOptionsExtractRecords(t *testing.T) {
+	opts := jsonptr.Options{MaxBytes: 100}
+	// The rest of a line is discarded after a record too large, even
+	// if it contains other values
+	long := "[" + strings.Repeat("1,", 1<<20) + "1] [1] {\"a\":1}"
+	input := "{\"a\":0}\n" + long + "\n" + long + "\n{\"a\":2}\n" + long
+	var records []*jsonptr.Record
+	err := opts.ExtractRecords(strings.NewReader(input), []string{"/a"}, func(rec *jsonptr.Record) error {
+		rec.Raw = nil
+		records = append(records, rec)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("got %d records", len(records))
+	}
+	for i, rec := range records {
+		if i == 0 || i == 3 {
+			if rec.Err != nil || rec.Values[0] != float64(i*2/3) {
+				t.Errorf("%d: got %#v", i, rec)
+			}
+		} else if e, ok := rec.Err.(*jsonptr.DocumentError); !ok || e.Err != jsonptr.ErrLimit {
+			t.Errorf("%d: got %v", i, rec.Err)
+		}
+	}
+	if rec := records[3]; rec.Line != 4 || rec.Offset != int64(8+2*len(long)+2) {
+		t.Errorf("got line %d, offset %d", rec.Line, rec.Offset)
+	}
+
+	ignore := func(*jsonptr.Record) error { return nil }
+	err = opts.ExtractRecords(strings.NewReader("1"), []string{strings.Repeat("/a", 10)}, ignore)
+	if err != nil {
+		t.Errorf("got %v", err)
+	}
+	opts.MaxTokens = 5
+	err = opts.ExtractRecords(strings.NewReader("1"), []string{strings.Repeat("/a", 10)}, ignore)
+	if e, ok := err.(*jsonptr.PtrError); !ok || e.Err != jsonptr.ErrLimit {
+		t.Errorf("got %v", err)
+	}
+}
+
+func ExampleExtractRecords() {
+	logs := strings.NewReader(`{"level":"info","msg":"started"}
+{"level":"error","msg":"failed","err":{"code":42}}
+{"level":
+{"level":"info"}
+`)
+	_ = jsonptr.ExtractRecords(logs, []string{"/level", "/err/code"}, func(rec *jsonptr.Record) error {
+		if _, malformed := rec.Err.(*jsonptr.DocumentError); malformed {
+			fmt.Printf("line %d: malformed\n", rec.Line)
+			return nil
+		}
+		fmt.Printf("line %d: %v %v\n", rec.Line, rec.Values[0], rec.Values[1])
+		return nil
+	})
+	// Output:
+	// line 1: info <nil>
+	// line 2: error 42
+	// line 3: malformed
+	// line 4: info <nil>
+}
@@ -0,0 +1,502 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Action is the processing applied by a [Rewriter] to a value.
+type Action int
+
+const (
+	// Pass copies the value, processing its children.
+	Pass Action = iota
+	// Replace replaces the value.
+	Replace
+	// Remove removes the value (the object member or the array element).
+	Remove
+)
+
+// RewriteFunc is called by a [Rewriter] for a value at ptr to choose the
+// action to apply. With Replace, value is the replacement, serialized with
+// [encoding/json.Marshal].
+//
+// ptr is only valid during the call: use [Pointer.Copy] to retain it.
+type RewriteFunc func(ptr Pointer) (action Action, value interface{}, err error)
+
+// Rewriter copies JSON documents from a reader to a writer, replacing or
+// removing values at given locations on the way.
+//
+// The input is never fully loaded in memory. Bytes of the values that are not
+// modified (including whitespace) are copied unchanged. Locations refer to
+// the input document: array indexes are not shifted by removals.
+type Rewriter struct {
+	// Func, if not nil, is called for each value not matched by a
+	// location registered with Replace or Remove.
+	Func RewriteFunc
+
+	rules map[string]rewriteRule
+	// prefixes holds the locations of the ancestors of the rules
+	prefixes map[string]bool
+}
+
+type rewriteRule struct {
+	action Action
+	value  []byte
+}
+
+func (rw *Rewriter) add(ptr string, rule rewriteRule) error {
+	if err := checkSyntax(ptr); err != nil {
+		return err
+	}
+	if rw.rules == nil {
+		rw.rules = make(map[string]rewriteRule)
+		rw.prefixes = make(map[string]bool)
+	}
+	rw.rules[ptr] = rule
+	for i := len(ptr) - 1; i >= 0; i-- {
+		if ptr[i] == '/' {
+			rw.prefixes[ptr[:i]] = true
+		}
+	}
+	return nil
+}
+
+// Replace registers the replacement of the value at ptr with value,
+// serialized with [encoding/json.Marshal].
+func (rw *Rewriter) Replace(ptr string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return rw.add(ptr, rewriteRule{action: Replace, value: data})
+}
+
+// Remove registers the removal of the value at ptr.
+// The root can't be removed.
+func (rw *Rewriter) Remove(ptr string) error {
+	if ptr == "" {
+		return &BadPointerError{BadPtr: ptr, Err: ErrDeleteRoot, Token: -1}
+	}
+	return rw.add(ptr, rewriteRule{action: Remove})
+}
+
+// Rewrite copies JSON from r to w, applying the registered rules and Func.
+//
+// r may contain a stream of JSON values (such as JSON Lines): each value is
+// processed as a document.
+//
+// Malformed input is reported as a *DocumentError.
+func (rw *Rewriter) Rewrite(w io.Writer, r io.Reader) error {
+	s := rewriteState{
+		Rewriter: rw,
+		r:        bufio.NewReader(r),
+		w:        bufio.NewWriter(w),
+	}
+	err := s.run()
+	if ferr := s.w.Flush(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+type rewriteState struct {
+	*Rewriter
+	r *bufio.Reader
+	w *bufio.Writer
+	// offset is the number of bytes read
+	offset int64
+	// path is the current location (as a string) and ptr the same location
+	// as a Pointer (maintained only for Func)
+	path []byte
+	ptr  Pointer
+	// buf is a reusable buffer for object member prefixes
+	buf []byte
+	// scanner checks the values copied whole which don't fit in the buffer
+	// of r
+	scanner valueScanner
+}
+
+func (s *rewriteState) run() error {
+	for {
+		// Whitespace between values is preserved
+		if err := s.space(s.w); err != nil {
+			return err
+		}
+		if _, err := s.r.Peek(1); err == io.EOF {
+			return nil
+		}
+		action, value, err := s.action()
+		if err != nil {
+			return err
+		}
+		if action == Remove {
+			return &BadPointerError{BadPtr: "", Err: ErrDeleteRoot, Token: -1}
+		}
+		if err = s.value(action, value); err != nil {
+			return err
+		}
+	}
+}
+
+// action returns the action for the value at the current location.
+func (s *rewriteState) action() (Action, []byte, error) {
+	if rule, ok := s.rules[string(s.path)]; ok {
+		return rule.action, rule.value, nil
+	}
+	if s.Func == nil {
+		return Pass, nil, nil
+	}
+	action, value, err := s.Func(s.ptr)
+	if err != nil || action != Replace {
+		return action, nil, err
+	}
+	data, err := json.Marshal(value)
+	return action, data, err
+}
+
+func (s *rewriteState) syntaxError(c byte) error {
+	return &DocumentError{
+		Ptr: string(s.path),
+		Err: fmt.Errorf("invalid character %q at offset %d", c, s.offset-1),
+	}
+}
+
+func (s *rewriteState) readError(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return jsonError(string(s.path), err)
+}
+
+func (s *rewriteState) readByte() (byte, error) {
+	c, err := s.r.ReadByte()
+	if err != nil {
+		return 0, s.readError(err)
+	}
+	s.offset++
+	return c, nil
+}
+
+func (s *rewriteState) unreadByte() {
+	_ = s.r.UnreadByte()
+	s.offset--
+}
+
+// space copies whitespace to w (if not nil). io.EOF is not an error.
+func (s *rewriteState) space(w io.ByteWriter) error {
+	for {
+		c, err := s.r.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return s.readError(err)
+		}
+		s.offset++
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			if w != nil {
+				_ = w.WriteByte(c)
+			}
+		default:
+			s.unreadByte()
+			return nil
+		}
+	}
+}
+
+// spaceTo is like space, but appends whitespace to buf.
+func (s *rewriteState) spaceTo(buf []byte) ([]byte, error) {
+	for {
+		c, err := s.readByte()
+		if err != nil {
+			return buf, err
+		}
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			buf = append(buf, c)
+		default:
+			s.unreadByte()
+			return buf, nil
+		}
+	}
+}
+
+// value processes a value at the current location.
+func (s *rewriteState) value(action Action, replacement []byte) error {
+	if action == Replace {
+		if _, err := s.w.Write(replacement); err != nil {
+			return err
+		}
+		return s.copyValue(nil)
+	}
+	// Descend only where rules or Func may apply
+	if s.Func == nil && !s.prefixes[string(s.path)] {
+		return s.copyValue(s.w)
+	}
+	c, err := s.readByte()
+	if err != nil {
+		return err
+	}
+	switch c {
+	case '{':
+		_ = s.w.WriteByte(c)
+		return s.object()
+	case '[':
+		_ = s.w.WriteByte(c)
+		return s.array()
+	}
+	s.unreadByte()
+	return s.copyValue(s.w)
+}
+
+// enter moves the current location to a child.
+func (s *rewriteState) enter(token string) int {
+	mark := len(s.path)
+	s.path = AppendEscape(append(s.path, '/'), token)
+	if s.Func != nil {
+		s.ptr = append(s.ptr, token)
+	}
+	return mark
+}
+
+// leave moves the current location back to the parent.
+func (s *rewriteState) leave(mark int) {
+	s.path = s.path[:mark]
+	if s.Func != nil {
+		s.ptr = s.ptr[:len(s.ptr)-1]
+	}
+}
+
+func (s *rewriteState) object() error {
+	kept := 0
+	for i := 0; ; i++ {
+		// Whitespace and key are buffered until the action is known
+		buf, err := s.spaceTo(s.buf[:0])
+		if err != nil {
+			return err
+		}
+		c, err := s.readByte()
+		if err != nil {
+			return err
+		}
+		if c == '}' && i == 0 {
+			_, _ = s.w.Write(buf)
+			return s.w.WriteByte(c)
+		}
+		if c != '"' {
+			return s.syntaxError(c)
+		}
+		keyStart := len(buf)
+		if buf, err = s.str(append(buf, c)); err != nil {
+			return err
+		}
+		var key string
+		if raw := buf[keyStart+1 : len(buf)-1]; bytes.IndexByte(raw, '\\') < 0 {
+			key = string(raw)
+		} else if err = json.Unmarshal(buf[keyStart:], &key); err != nil {
+			return jsonError(string(s.path), err)
+		}
+		if buf, err = s.spaceTo(buf); err != nil {
+			return err
+		}
+		if c, err = s.readByte(); err != nil {
+			return err
+		}
+		if c != ':' {
+			return s.syntaxError(c)
+		}
+		if buf, err = s.spaceTo(append(buf, c)); err != nil {
+			return err
+		}
+		s.buf = buf
+
+		mark := s.enter(key)
+		action, value, err := s.action()
+		if err != nil {
+			return err
+		}
+		if action == Remove {
+			err = s.copyValue(nil)
+		} else {
+			if kept > 0 {
+				_ = s.w.WriteByte(',')
+			}
+			kept++
+			if _, err = s.w.Write(s.buf); err == nil {
+				err = s.value(action, value)
+			}
+		}
+		s.leave(mark)
+		if err != nil {
+			return err
+		}
+
+		if c, err = s.next(action); err != nil {
+			return err
+		}
+		switch c {
+		case ',':
+		case '}':
+			return s.w.WriteByte(c)
+		default:
+			return s.syntaxError(c)
+		}
+	}
+}
+
+func (s *rewriteState) array() error {
+	kept := 0
+	for i := 0; ; i++ {
+		s.buf = s.buf[:0]
+		buf, err := s.spaceTo(s.buf)
+		if err != nil {
+			return err
+		}
+		s.buf = buf
+		c, err := s.readByte()
+		if err != nil {
+			return err
+		}
+		if c == ']' && i == 0 {
+			_, _ = s.w.Write(buf)
+			return s.w.WriteByte(c)
+		}
+		s.unreadByte()
+
+		mark := s.enter(strconv.Itoa(i))
+		action, value, err := s.action()
+		if err != nil {
+			return err
+		}
+		if action == Remove {
+			err = s.copyValue(nil)
+		} else {
+			if kept > 0 {
+				_ = s.w.WriteByte(',')
+			}
+			kept++
+			if _, err = s.w.Write(s.buf); err == nil {
+				err = s.value(action, value)
+			}
+		}
+		s.leave(mark)
+		if err != nil {
+			return err
+		}
+
+		if c, err = s.next(action); err != nil {
+			return err
+		}
+		switch c {
+		case ',':
+		case ']':
+			return s.w.WriteByte(c)
+		default:
+			return s.syntaxError(c)
+		}
+	}
+}
+
+// next reads the delimiter following a member or element.
+// The whitespace before it is copied, except after a removed value where it
+// is only kept before the end of the container.
+func (s *rewriteState) next(action Action) (byte, error) {
+	if action != Remove {
+		if err := s.space(s.w); err != nil {
+			return 0, err
+		}
+		return s.readByte()
+	}
+	buf, err := s.spaceTo(s.buf[:0])
+	s.buf = buf
+	if err != nil {
+		return 0, err
+	}
+	c, err := s.readByte()
+	if err == nil && (c == '}' || c == ']') {
+		_, err = s.w.Write(buf)
+	}
+	return c, err
+}
+
+// str reads the rest of a string (after the opening quote), appending it to buf.
+func (s *rewriteState) str(buf []byte) ([]byte, error) {
+	for {
+		c, err := s.readByte()
+		if err != nil {
+			return buf, err
+		}
+		buf = append(buf, c)
+		switch {
+		case c == '"':
+			return buf, nil
+		case c == '\\':
+			if c, err = s.readByte(); err != nil {
+				return buf, err
+			}
+			buf = append(buf, c)
+		case c < ' ':
+			return buf, s.syntaxError(c)
+		}
+	}
+}
+
+// copyValue copies a value to w, or skips it if w is nil, checking its
+// syntax.
+//
+// The bytes buffered by s.r are checked at once with rawSkipValue. A value
+// which doesn't fit in the buffer is checked with s.scanner, which resumes
+// at each refill.
+func (s *rewriteState) copyValue(w *bufio.Writer) error {
+	s.scanner.reset()
+	for first := true; ; first = false {
+		if _, err := s.r.Peek(1); err != nil {
+			if err == io.EOF && s.scanner.finish() == scanDone {
+				// A number at the end of the input
+				return nil
+			}
+			return s.readError(err)
+		}
+		buffered, _ := s.r.Peek(s.r.Buffered())
+		if first {
+			// A value followed by other bytes in the buffer is complete
+			if end, ok := rawSkipValue(buffered, 0); ok && end < len(buffered) {
+				return s.copyBytes(w, buffered[:end])
+			}
+		}
+		end, status := s.scanner.scan(buffered, 0)
+		switch status {
+		case scanError:
+			c := buffered[end]
+			_ = s.copyBytes(nil, buffered[:end+1])
+			return s.syntaxError(c)
+		case scanDone:
+			return s.copyBytes(w, buffered[:end])
+		}
+		if err := s.copyBytes(w, buffered); err != nil {
+			return err
+		}
+	}
+}
+
+// copyBytes copies to w (if not nil) the bytes b peeked from s.r, and
+// consumes them.
+func (s *rewriteState) copyBytes(w *bufio.Writer, b []byte) error {
+	var err error
+	if w != nil {
+		_, err = w.Write(b)
+	}
+	n, _ := s.r.Discard(len(b))
+	s.offset += int64(n)
+	return err
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+func TestRewriter(t *testing.T) {
+	for _, test := range []struct {
+		in       string
+		replace  map[string]interface{}
+		remove   []string
+		expected string
+	}{
+		{`{"a":1}`, nil, nil, `{"a":1}`},
+		{` { "a" : [ 1 , "x\"y" ] } `, nil, nil, ` { "a" : [ 1 , "x\"y" ] } `},
+		{`{"a":1,"b":{"c":2}}`, map[string]interface{}{"/b/c": "x"}, nil, `{"a":1,"b":{"c":"x"}}`},
+		{`{"a":1,"b":{"c":2}}`, map[string]interface{}{"/b": nil}, nil, `{"a":1,"b":null}`},
+		{`{"a":1}`, map[string]interface{}{"": []int{1}}, nil, `[1]`},
+		{`{"a":1,"b":2,"c":3}`, nil, []string{"/a"}, `{"b":2,"c":3}`},
+		{`{"a":1,"b":2,"c":3}`, nil, []string{"/b"}, `{"a":1,"c":3}`},
+		{`{"a":1,"b":2,"c":3}`, nil, []string{"/c"}, `{"a":1,"b":2}`},
+		{`{"a":1,"b":2,"c":3}`, nil, []string{"/a", "/b", "/c"}, `{}`},
+		{"{\n  \"a\": 1,\n  \"b\": 2\n}", nil, []string{"/b"}, "{\n  \"a\": 1\n}"},
+		{"{\n  \"a\": 1,\n  \"b\": 2\n}", nil, []string{"/a"}, "{\n  \"b\": 2\n}"},
+		{`[0,1,2,3]`, nil, []string{"/0", "/2"}, `[1,3]`},
+		{`[[0,1],[2,3]]`, map[string]interface{}{"/1/1": true}, []string{"/0/0"}, `[[1],[2,true]]`},
+		{`{"a~b":{"c/d":1,"\u0065":2}}`, nil, []string{"/a~0b/c~1d", "/a~0b/e"}, `{"a~b":{}}`},
+		{`{"a":1}`, map[string]interface{}{"/b": 2, "/a/x": 3}, nil, `{"a":1}`},
+		{"{\"a\":1}\n{\"a\":2}\n[]\n", map[string]interface{}{"/a": 0}, nil, "{\"a\":0}\n{\"a\":0}\n[]\n"},
+		{`{"a":{"b":[{"c":1}]},"b":{"c":2}}`, map[string]interface{}{"/b/c": "x"}, nil, `{"a":{"b":[{"c":1}]},"b":{"c":"x"}}`},
+		{`{"a":[ ],"b":{ }}`, map[string]interface{}{"/a/0": 1, "/b/x": 2}, nil, `{"a":[ ],"b":{ }}`},
+	} {
+		var rw jsonptr.Rewriter
+		for ptr, value := range test.replace {
+			if err := rw.Replace(ptr, value); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, ptr := range test.remove {
+			if err := rw.Remove(ptr); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var out bytes.Buffer
+		if err := rw.Rewrite(&out, strings.NewReader(test.in)); err != nil {
+			t.Errorf("%s: %v", test.in, err)
+		} else if out.String() != test.expected {
+			t.Errorf("%s:\ngot      %s\nexpected %s", test.in, out.String(), test.expected)
+		}
+	}
+}
+
+func TestRewriterFunc(t *testing.T) {
+	var visited []string
+	rw := jsonptr.Rewriter{
+		Func: func(ptr jsonptr.Pointer) (jsonptr.Action, interface{}, error) {
+			visited = append(visited, ptr.String())
+			if len(ptr) > 0 {
+				switch ptr.LeafName() {
+				case "password":
+					return jsonptr.Replace, "***", nil
+				case "token":
+					return jsonptr.Remove, nil, nil
+				}
+			}
+			return jsonptr.Pass, nil, nil
+		},
+	}
+	if err := rw.Remove("/users/0"); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	err := rw.Rewrite(&out, strings.NewReader(`{"users":[{"name":"a","password":"x"},{"name":"b","password":"y","token":"z"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"users":[{"name":"b","password":"***"}]}`; out.String() != expected {
+		t.Errorf("got %s", out.String())
+	}
+	if got := strings.Join(visited, " "); got != " /users /users/1 /users/1/name /users/1/password /users/1/token" {
+		t.Errorf("visited: %s", got)
+	}
+}
+
+func TestRewriterErrors(t *testing.T) {
+	var rw jsonptr.Rewriter
+	if err := rw.Remove(""); err == nil {
+		t.Error("error expected for removal of root")
+	}
+	if err := rw.Replace("a", 1); err == nil {
+		t.Error("error expected for bad pointer")
+	} else if _, ok := err.(*jsonptr.BadPointerError); !ok {
+		t.Errorf("BadPointerError expected, got %T", err)
+	}
+	if err := rw.Replace("/x/y", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, in := range []string{
+		`{"x":{"y":1`,
+		`{"x" 1}`,
+		`{"x":1 "y":2}`,
+		`{x:1}`,
+		`{"x":[1 2]}`,
+		`{"x":tru}`,
+		`{"x":"a`,
+		`{"x":{"y":1,}}`,
+		`]`,
+		`[1,2]]`,
+		`{"a":-}`,
+		// Values copied whole are checked
+		`{"a":[1,,2]}`,
+		`{"a":[1,]}`,
+		`{"a":{"b":1,}}`,
+		`{"a":[1}`,
+		`{"a":{"b":1]}`,
+		`{"a":"\q"}`,
+		`{"a":"\u12G4"}`,
+		`{"a":{"b" 1}}`,
+		`{"a":[1 2]}`,
+		`{"a":{1:2}}`,
+		`{"a":[01]}`,
+		`{"a":[1.]}`,
+		`{"a":[truex]}`,
+		`{"a":{"b":1}:2}`,
+		`[1,[2,,3]]`,
+		`1 2,`,
+		`"a\x"`,
+		`[1`,
+		// Replaced values too
+		`{"x":{"y":[1,]}}`,
+		`{"x":{"y":{"z",1}}}`,
+		// Larger than the read buffer
+		`{"a":[` + strings.Repeat(`"abc",`, 1000) + `]}`,
+		`{"a":"` + strings.Repeat(`\u00e9`, 1000) + `\u00g9"}`,
+	} {
+		var out bytes.Buffer
+		err := rw.Rewrite(&out, strings.NewReader(in))
+		if err == nil {
+			t.Errorf("%.40s: error expected, got %s", in, out.String())
+			continue
+		}
+		t.Logf("%.40s: %v", in, err)
+		if _, ok := err.(*jsonptr.DocumentError); !ok {
+			t.Errorf("%.40s: DocumentError expected, got %T", in, err)
+		}
+		// Values split across reads are checked the same way
+		err1 := rw.Rewrite(ioutil.Discard, iotest.OneByteReader(strings.NewReader(in)))
+		if err1 == nil || err1.Error() != err.Error() {
+			t.Errorf("%.40s: got %v, expected %v", in, err1, err)
+		}
+	}
+
+	// Valid values are copied unchanged ("/x/y" is replaced by the same value)
+	for _, in := range []string{
+		`{"a":[1,-0.5e+10,0,true,false,null,"\u12aF\n\/é",{},[ ],{ "b" : [ 1 , 2 ] }]}`,
+		"1 2\n3.5e1\ttrue null\r\n\"x\"",
+		`{"x":{"y":1},"z":-12}`,
+		`{"a":[` + strings.Repeat(`"\u00e9",1e5,{"b":[]},`, 1000) + `1]} 2`,
+	} {
+		for _, r := range []io.Reader{strings.NewReader(in), iotest.OneByteReader(strings.NewReader(in))} {
+			var out bytes.Buffer
+			if err := rw.Rewrite(&out, r); err != nil {
+				t.Errorf("%.40s: %v", in, err)
+			} else if out.String() != in {
+				t.Errorf("%.40s: got %.40s", in, out.String())
+			}
+		}
+	}
+}
+
+func ExampleRewriter() {
+	var rw jsonptr.Rewriter
+	_ = rw.Replace("/user/email", "redacted")
+	_ = rw.Remove("/user/ssn")
+	in := strings.NewReader(`{"user": {"name": "Alice", "email": "alice@example.com", "ssn": "123"}, "items": [1, 2]}`)
+	if err := rw.Rewrite(os.Stdout, in); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// {"user": {"name": "Alice", "email": "redacted"}, "items": [1, 2]}
+}
+
+func BenchmarkRewriter(b *testing.B) {
+	var doc bytes.Buffer
+	doc.WriteString(`{"x":{"y":1},"data":[`)
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(&doc, `{"id":%d,"name":"item é %d","tags":["a","b"],"score":%d.5},`, i, i, i)
+	}
+	doc.WriteString(`null]}`)
+	var rw jsonptr.Rewriter
+	_ = rw.Replace("/x/y", 2)
+	b.SetBytes(int64(doc.Len()))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := rw.Rewrite(ioutil.Discard, bytes.NewReader(doc.Bytes())); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+// valueScanner validates a JSON value incrementally, like the scanner of
+// encoding/json: a value read in several parts is scanned only once.
+//
+// It complements rawSkipValue, which is faster but needs the whole value in
+// memory: readers of streams (ExtractRecords, Rewriter) use rawSkipValue for
+// the values found whole in their buffer, and valueScanner for the others.
+type valueScanner struct {
+	stack []byte // open containers: '{' or '['
+	state scanState
+	key   bool   // the string being scanned is an object key
+	lit   string // rest of the literal being scanned
+	hex   int    // number of hex digits expected in a \u escape
+}
+
+type scanState uint8
+
+const (
+	scanValue      scanState = iota // expecting a value
+	scanValueOrEnd                  // after '[': a value or ']'
+	scanKey                         // after ',' in an object
+	scanKeyOrEnd                    // after '{': a key or '}'
+	scanColon                       // after a key
+	scanNext                        // after a value in a container: ',' or the closing delimiter
+	scanString
+	scanEscape
+	scanUnicode
+	scanLiteral // in true, false or null
+	scanMinus   // after the sign of a number
+	scanZero    // after a leading 0
+	scanInt     // in the integer part
+	scanDot     // after the decimal point
+	scanFrac    // in the fraction
+	scanE       // after the exponent mark
+	scanESign   // after the sign of the exponent
+	scanExp     // in the exponent
+	scanEnd     // after a literal or a number: expecting a delimiter
+)
+
+// Status of valueScanner.scan
+const (
+	scanMore  = iota // the value is incomplete
+	scanDone         // the value is complete
+	scanError        // the value is malformed
+)
+
+func (s *valueScanner) reset() {
+	*s = valueScanner{stack: s.stack[:0]}
+}
+
+// scan continues the scan at doc[i]. It returns the index following the
+// value (scanDone), the index of the error (scanError), or len(doc) if the
+// value is incomplete (scanMore).
+func (s *valueScanner) scan(doc []byte, i int) (int, int) {
+	for ; i < len(doc); i++ {
+		c := doc[i]
+		switch s.state {
+		case scanValue, scanValueOrEnd:
+			switch {
+			case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			case c == ']' && s.state == scanValueOrEnd:
+				if s.pop() {
+					return i + 1, scanDone
+				}
+			case c == '{':
+				s.stack = append(s.stack, c)
+				s.state = scanKeyOrEnd
+			case c == '[':
+				s.stack = append(s.stack, c)
+				s.state = scanValueOrEnd
+			case c == '"':
+				s.state, s.key = scanString, false
+			case c == 't':
+				s.state, s.lit = scanLiteral, "rue"
+			case c == 'f':
+				s.state, s.lit = scanLiteral, "alse"
+			case c == 'n':
+				s.state, s.lit = scanLiteral, "ull"
+			case c == '-':
+				s.state = scanMinus
+			case c == '0':
+				s.state = scanZero
+			case '1' <= c && c <= '9':
+				s.state = scanInt
+			default:
+				return i, scanError
+			}
+		case scanKey, scanKeyOrEnd:
+			switch {
+			case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			case c == '}' && s.state == scanKeyOrEnd:
+				if s.pop() {
+					return i + 1, scanDone
+				}
+			case c == '"':
+				s.state, s.key = scanString, true
+			default:
+				return i, scanError
+			}
+		case scanColon:
+			switch c {
+			case ' ', '\t', '\n', '\r':
+			case ':':
+				s.state = scanValue
+			default:
+				return i, scanError
+			}
+		case scanNext:
+			top := s.stack[len(s.stack)-1]
+			switch {
+			case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			case c == ',' && top == '{':
+				s.state = scanKey
+			case c == ',':
+				s.state = scanValue
+			case c == top+2: // '}' or ']'
+				if s.pop() {
+					return i + 1, scanDone
+				}
+			default:
+				return i, scanError
+			}
+		case scanString:
+			switch {
+			case c == '"':
+				if s.key {
+					s.state = scanColon
+				} else if s.endValue() {
+					return i + 1, scanDone
+				}
+			case c == '\\':
+				s.state = scanEscape
+			case c < 0x20:
+				return i, scanError
+			}
+		case scanEscape:
+			switch c {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				s.state = scanString
+			case 'u':
+				s.state, s.hex = scanUnicode, 4
+			default:
+				return i, scanError
+			}
+		case scanUnicode:
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return i, scanError
+			}
+			if s.hex--; s.hex == 0 {
+				s.state = scanString
+			}
+		case scanLiteral:
+			if c != s.lit[0] {
+				return i, scanError
+			}
+			if s.lit = s.lit[1:]; s.lit == "" {
+				s.state = scanEnd
+			}
+		case scanMinus:
+			switch {
+			case c == '0':
+				s.state = scanZero
+			case '1' <= c && c <= '9':
+				s.state = scanInt
+			default:
+				return i, scanError
+			}
+		case scanZero, scanInt, scanFrac, scanExp:
+			switch {
+			case '0' <= c && c <= '9' && s.state != scanZero:
+			case c == '.' && (s.state == scanZero || s.state == scanInt):
+				s.state = scanDot
+			case (c == 'e' || c == 'E') && s.state != scanExp:
+				s.state = scanE
+			default:
+				// End of the number: c is checked as a delimiter
+				s.state = scanEnd
+				i--
+			}
+		case scanDot:
+			if c < '0' || c > '9' {
+				return i, scanError
+			}
+			s.state = scanFrac
+		case scanE, scanESign:
+			switch {
+			case (c == '+' || c == '-') && s.state == scanE:
+				s.state = scanESign
+			case '0' <= c && c <= '9':
+				s.state = scanExp
+			default:
+				return i, scanError
+			}
+		case scanEnd:
+			switch c {
+			case ',', '}', ']', ' ', '\t', '\n', '\r':
+			default:
+				return i, scanError
+			}
+			if s.endValue() {
+				return i, scanDone
+			}
+			// c is the delimiter following the value in its container
+			i--
+		}
+	}
+	return i, scanMore
+}
+
+// finish ends the scan at the end of the input.
+func (s *valueScanner) finish() int {
+	switch s.state {
+	case scanZero, scanInt, scanFrac, scanExp, scanEnd:
+		if len(s.stack) == 0 {
+			return scanDone
+		}
+	}
+	return scanError
+}
+
+// endValue updates the state after a complete value. It returns true if the
+// scanned value is complete.
+func (s *valueScanner) endValue() bool {
+	if len(s.stack) == 0 {
+		return true
+	}
+	s.state = scanNext
+	return false
+}
+
+// pop closes the innermost container.
+func (s *valueScanner) pop() bool {
+	s.stack = s.stack[:len(s.stack)-1]
+	return s.endValue()
+}
@@ -0,0 +1,497 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+// Package schema implements validation of JSON documents with JSON Schema
+// (draft 2020-12), reporting locations as JSON Pointers.
+//
+// Documents are in the data model of package [github.com/dolmen-go/jsonptr]:
+// trees of []interface{}, map[string]interface{} or *jsonptr.Object.
+//
+// Only references ($ref) inside the schema document are supported: JSON Pointer
+// fragments ("#/$defs/item") and plain name fragments ("#item", see $anchor).
+// $dynamicRef and $dynamicAnchor are processed like $ref and $anchor.
+// The "format" keyword is an annotation only.
+//
+// Specification: https://json-schema.org/draft/2020-12
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+// Schema is a compiled JSON Schema.
+type Schema struct {
+	root *node
+	id   string
+}
+
+// node is a compiled schema (or subschema).
+type node struct {
+	// loc is the location of the schema in the schema document
+	loc jsonptr.Pointer
+
+	// boolean schema
+	isBool bool
+	value  bool
+
+	ref    *node
+	refKey string // "$ref" or "$dynamicRef"
+
+	// Applicators
+	allOf, anyOf, oneOf []*node
+	not                 *node
+	ifSchema            *node
+	thenSchema          *node
+	elseSchema          *node
+	dependentSchemas    map[string]*node
+	prefixItems         []*node
+	items               *node
+	contains            *node
+	properties          map[string]*node
+	patternProperties   []patternNode
+	additionalProps     *node
+	propertyNames       *node
+	unevaluatedItems    *node
+	unevaluatedProps    *node
+
+	// Validation
+	types             []string
+	enum              []interface{}
+	hasEnum           bool
+	constValue        interface{}
+	hasConst          bool
+	multipleOf        *big.Rat
+	maximum           *float64
+	exclusiveMaximum  *float64
+	minimum           *float64
+	exclusiveMinimum  *float64
+	maxLength         int
+	minLength         int
+	pattern           *regexp.Regexp
+	maxItems          int
+	minItems          int
+	uniqueItems       bool
+	maxContains       int
+	minContains       int
+	maxProperties     int
+	minProperties     int
+	required          []string
+	dependentRequired map[string][]string
+}
+
+type patternNode struct {
+	source string
+	re     *regexp.Regexp
+	node   *node
+}
+
+type compiler struct {
+	doc   interface{}
+	id    string
+	nodes map[string]*node
+}
+
+// Compile compiles a JSON Schema document.
+//
+// doc is either a decoded document (a map[string]interface{}, a *jsonptr.Object
+// or a bool) or a serialized document ([encoding/json.RawMessage]).
+//
+// Errors in the schema are reported as *jsonptr.DocumentError with the location
+// in the schema document.
+func Compile(doc interface{}) (*Schema, error) {
+	if raw, ok := doc.(json.RawMessage); ok {
+		doc = nil
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, &jsonptr.DocumentError{Err: err}
+		}
+	}
+	c := compiler{doc: doc, nodes: make(map[string]*node)}
+	if id, ok := get(doc, "$id").(string); ok {
+		c.id = strings.TrimSuffix(id, "#")
+	}
+	root, err := c.compile(doc, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{root: root, id: c.id}, nil
+}
+
+// MustCompile is like [Compile] but panics in case of error.
+func MustCompile(doc interface{}) *Schema {
+	s, err := Compile(doc)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func schemaError(loc jsonptr.Pointer, format string, args ...interface{}) error {
+	ptr := loc.String()
+	return &jsonptr.DocumentError{
+		Ptr: ptr,
+		Err: fmt.Errorf("%q: "+format, append([]interface{}{ptr}, args...)...),
+	}
+}
+
+// at returns a copy of ptr extended with tokens.
+func at(ptr jsonptr.Pointer, tokens ...string) jsonptr.Pointer {
+	p := make(jsonptr.Pointer, len(ptr), len(ptr)+len(tokens))
+	copy(p, ptr)
+	return append(p, tokens...)
+}
+
+// lookup returns the value of a property of an object of the data model.
+func lookup(obj interface{}, key string) (interface{}, bool) {
+	switch obj := obj.(type) {
+	case map[string]interface{}:
+		v, ok := obj[key]
+		return v, ok
+	case *jsonptr.Object:
+		return obj.Get(key)
+	}
+	return nil, false
+}
+
+// get is like lookup, without reporting existence.
+func get(obj interface{}, key string) interface{} {
+	v, _ := lookup(obj, key)
+	return v
+}
+
+// keys returns the property names of an object of the data model, or false
+// if obj is not an object. Names of a map are sorted.
+func keys(obj interface{}) ([]string, bool) {
+	switch obj := obj.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return keys, true
+	case *jsonptr.Object:
+		return obj.Keys(), true
+	}
+	return nil, false
+}
+
+func (c *compiler) compile(schema interface{}, loc jsonptr.Pointer) (*node, error) {
+	key := loc.String()
+	if n, ok := c.nodes[key]; ok {
+		return n, nil
+	}
+	n := &node{
+		loc:           loc,
+		maxLength:     -1,
+		maxItems:      -1,
+		maxContains:   -1,
+		minContains:   1,
+		maxProperties: -1,
+	}
+	// Register before compiling subschemas, for recursive references
+	c.nodes[key] = n
+
+	if b, ok := schema.(bool); ok {
+		n.isBool = true
+		n.value = b
+		return n, nil
+	}
+	names, ok := keys(schema)
+	if !ok {
+		return nil, schemaError(loc, "schema must be an object or a boolean")
+	}
+
+	var err error
+	for _, kw := range names {
+		v := get(schema, kw)
+		kwLoc := at(loc, kw)
+		switch kw {
+		case "$ref", "$dynamicRef":
+			ref, ok := v.(string)
+			if !ok {
+				return nil, schemaError(kwLoc, "string expected")
+			}
+			n.refKey = kw
+			n.ref, err = c.resolve(ref, kwLoc)
+		case "allOf":
+			n.allOf, err = c.compileArray(v, kwLoc)
+		case "anyOf":
+			n.anyOf, err = c.compileArray(v, kwLoc)
+		case "oneOf":
+			n.oneOf, err = c.compileArray(v, kwLoc)
+		case "not":
+			n.not, err = c.compile(v, kwLoc)
+		case "if":
+			n.ifSchema, err = c.compile(v, kwLoc)
+		case "then":
+			n.thenSchema, err = c.compile(v, kwLoc)
+		case "else":
+			n.elseSchema, err = c.compile(v, kwLoc)
+		case "dependentSchemas":
+			n.dependentSchemas, err = c.compileMap(v, kwLoc)
+		case "prefixItems":
+			n.prefixItems, err = c.compileArray(v, kwLoc)
+		case "items":
+			n.items, err = c.compile(v, kwLoc)
+		case "contains":
+			n.contains, err = c.compile(v, kwLoc)
+		case "properties":
+			n.properties, err = c.compileMap(v, kwLoc)
+		case "patternProperties":
+			var m map[string]*node
+			if m, err = c.compileMap(v, kwLoc); err != nil {
+				break
+			}
+			patterns := make([]string, 0, len(m))
+			for p := range m {
+				patterns = append(patterns, p)
+			}
+			sort.Strings(patterns)
+			for _, p := range patterns {
+				re, err := regexp.Compile(p)
+				if err != nil {
+					return nil, schemaError(at(kwLoc, p), "invalid pattern: %v", err)
+				}
+				n.patternProperties = append(n.patternProperties, patternNode{p, re, m[p]})
+			}
+		case "additionalProperties":
+			n.additionalProps, err = c.compile(v, kwLoc)
+		case "propertyNames":
+			n.propertyNames, err = c.compile(v, kwLoc)
+		case "unevaluatedItems":
+			n.unevaluatedItems, err = c.compile(v, kwLoc)
+		case "unevaluatedProperties":
+			n.unevaluatedProps, err = c.compile(v, kwLoc)
+		case "type":
+			switch v := v.(type) {
+			case string:
+				n.types = []string{v}
+			case []interface{}:
+				for i, t := range v {
+					s, ok := t.(string)
+					if !ok {
+						return nil, schemaError(at(kwLoc, fmt.Sprint(i)), "string expected")
+					}
+					n.types = append(n.types, s)
+				}
+			default:
+				return nil, schemaError(kwLoc, "string or array expected")
+			}
+			for _, t := range n.types {
+				switch t {
+				case "null", "boolean", "object", "array", "number", "integer", "string":
+				default:
+					return nil, schemaError(kwLoc, "unknown type %q", t)
+				}
+			}
+		case "enum":
+			values, ok := v.([]interface{})
+			if !ok {
+				return nil, schemaError(kwLoc, "array expected")
+			}
+			n.enum = values
+			n.hasEnum = true
+		case "const":
+			n.constValue = v
+			n.hasConst = true
+		case "multipleOf":
+			n.multipleOf = decimal(v)
+			if n.multipleOf == nil || n.multipleOf.Sign() <= 0 {
+				return nil, schemaError(kwLoc, "number strictly greater than 0 expected")
+			}
+		case "maximum":
+			n.maximum, err = numberKeyword(v, kwLoc)
+		case "exclusiveMaximum":
+			n.exclusiveMaximum, err = numberKeyword(v, kwLoc)
+		case "minimum":
+			n.minimum, err = numberKeyword(v, kwLoc)
+		case "exclusiveMinimum":
+			n.exclusiveMinimum, err = numberKeyword(v, kwLoc)
+		case "maxLength":
+			n.maxLength, err = countKeyword(v, kwLoc)
+		case "minLength":
+			n.minLength, err = countKeyword(v, kwLoc)
+		case "pattern":
+			s, ok := v.(string)
+			if !ok {
+				return nil, schemaError(kwLoc, "string expected")
+			}
+			if n.pattern, err = regexp.Compile(s); err != nil {
+				return nil, schemaError(kwLoc, "invalid pattern: %v", err)
+			}
+		case "maxItems":
+			n.maxItems, err = countKeyword(v, kwLoc)
+		case "minItems":
+			n.minItems, err = countKeyword(v, kwLoc)
+		case "uniqueItems":
+			b, ok := v.(bool)
+			if !ok {
+				return nil, schemaError(kwLoc, "boolean expected")
+			}
+			n.uniqueItems = b
+		case "maxContains":
+			n.maxContains, err = countKeyword(v, kwLoc)
+		case "minContains":
+			n.minContains, err = countKeyword(v, kwLoc)
+		case "maxProperties":
+			n.maxProperties, err = countKeyword(v, kwLoc)
+		case "minProperties":
+			n.minProperties, err = countKeyword(v, kwLoc)
+		case "required":
+			n.required, err = stringsKeyword(v, kwLoc)
+		case "dependentRequired":
+			names, ok := keys(v)
+			if !ok {
+				return nil, schemaError(kwLoc, "object expected")
+			}
+			n.dependentRequired = make(map[string][]string, len(names))
+			for _, name := range names {
+				if n.dependentRequired[name], err = stringsKeyword(get(v, name), at(kwLoc, name)); err != nil {
+					return nil, err
+				}
+			}
+		case "$defs":
+			// Compiled when referenced
+			if _, ok := keys(v); !ok {
+				return nil, schemaError(kwLoc, "object expected")
+			}
+		default:
+			// $schema, $id, $anchor, $comment, format, annotations and
+			// unknown keywords are ignored
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+func (c *compiler) compileArray(v interface{}, loc jsonptr.Pointer) ([]*node, error) {
+	schemas, ok := v.([]interface{})
+	if !ok || len(schemas) == 0 {
+		return nil, schemaError(loc, "non-empty array expected")
+	}
+	nodes := make([]*node, len(schemas))
+	for i, s := range schemas {
+		var err error
+		if nodes[i], err = c.compile(s, at(loc, fmt.Sprint(i))); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+func (c *compiler) compileMap(v interface{}, loc jsonptr.Pointer) (map[string]*node, error) {
+	names, ok := keys(v)
+	if !ok {
+		return nil, schemaError(loc, "object expected")
+	}
+	nodes := make(map[string]*node, len(names))
+	for _, name := range names {
+		var err error
+		if nodes[name], err = c.compile(get(v, name), at(loc, name)); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+// resolve compiles the target of a reference.
+func (c *compiler) resolve(ref string, loc jsonptr.Pointer) (*node, error) {
+	if c.id != "" && strings.HasPrefix(ref, c.id) {
+		ref = ref[len(c.id):]
+		if ref == "" {
+			ref = "#"
+		}
+	}
+	if !strings.HasPrefix(ref, "#") {
+		return nil, schemaError(loc, "unsupported external reference %q", ref)
+	}
+	fragment, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return nil, schemaError(loc, "invalid reference %q: %v", ref, err)
+	}
+	if fragment != "" && fragment[0] != '/' {
+		target, found := c.findAnchor(c.doc, nil, fragment)
+		if !found {
+			return nil, schemaError(loc, "anchor %q not found", fragment)
+		}
+		schema, _ := target.In(c.doc)
+		return c.compile(schema, target)
+	}
+	target, err := jsonptr.Get(c.doc, fragment)
+	if err != nil {
+		return nil, schemaError(loc, "unresolvable reference %q: %v", ref, err)
+	}
+	// No error as the pointer has been checked by Get
+	ptr, _ := jsonptr.Parse(fragment)
+	return c.compile(target, ptr)
+}
+
+// findAnchor returns the location of the schema declaring the given $anchor
+// (or $dynamicAnchor).
+func (c *compiler) findAnchor(v interface{}, loc jsonptr.Pointer, anchor string) (jsonptr.Pointer, bool) {
+	switch v := v.(type) {
+	case []interface{}:
+		for i, item := range v {
+			if p, found := c.findAnchor(item, at(loc, fmt.Sprint(i)), anchor); found {
+				return p, true
+			}
+		}
+	default:
+		names, ok := keys(v)
+		if !ok {
+			return nil, false
+		}
+		for _, kw := range []string{"$anchor", "$dynamicAnchor"} {
+			if a, ok := get(v, kw).(string); ok && a == anchor {
+				return loc, true
+			}
+		}
+		for _, name := range names {
+			if p, found := c.findAnchor(get(v, name), at(loc, name), anchor); found {
+				return p, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func numberKeyword(v interface{}, loc jsonptr.Pointer) (*float64, error) {
+	f, ok := number(v)
+	if !ok {
+		return nil, schemaError(loc, "number expected")
+	}
+	return &f, nil
+}
+
+func countKeyword(v interface{}, loc jsonptr.Pointer) (int, error) {
+	f, ok := number(v)
+	if !ok || f < 0 || f != float64(int(f)) {
+		return 0, schemaError(loc, "non-negative integer expected")
+	}
+	return int(f), nil
+}
+
+func stringsKeyword(v interface{}, loc jsonptr.Pointer) ([]string, error) {
+	values, ok := v.([]interface{})
+	if !ok {
+		return nil, schemaError(loc, "array expected")
+	}
+	list := make([]string, len(values))
+	for i, s := range values {
+		if list[i], ok = s.(string); !ok {
+			return nil, schemaError(at(loc, fmt.Sprint(i)), "string expected")
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,273 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package schema_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dolmen-go/jsonptr"
+	"github.com/dolmen-go/jsonptr/schema"
+)
+
+func TestValidate(t *testing.T) {
+	for _, test := range []struct {
+		schema string
+		valid  []string
+		errors []string
+	}{
+		{`true`, []string{`1`, `null`, `{}`}, nil},
+		{`false`, nil, []string{`1`, `null`}},
+		{`{"type":"integer"}`, []string{`1`, `1.0`, `-3`}, []string{`1.5`, `"1"`, `null`}},
+		{`{"type":["string","null"]}`, []string{`"a"`, `null`}, []string{`1`, `[]`}},
+		{`{"enum":[1,"a",{"b":[null]}]}`, []string{`1.0`, `"a"`, `{"b":[null]}`}, []string{`2`, `{"b":[]}`, `{"b":[null],"c":1}`}},
+		{`{"const":{"a":1,"b":2}}`, []string{`{"b":2,"a":1}`}, []string{`{"a":1}`}},
+		{`{"multipleOf":0.1}`, []string{`0.3`, `7`, `"x"`}, []string{`0.35`}},
+		{`{"minimum":1,"exclusiveMaximum":3}`, []string{`1`, `2.9`}, []string{`0.9`, `3`}},
+		{`{"maximum":3,"exclusiveMinimum":1}`, []string{`3`, `1.1`}, []string{`1`, `3.1`}},
+		{`{"minLength":2,"maxLength":3}`, []string{`"ab"`, `"été"`, `1`}, []string{`"a"`, `"abcd"`}},
+		{`{"pattern":"^a+$"}`, []string{`"aaa"`}, []string{`"ab"`}},
+		{`{"minItems":1,"maxItems":2,"uniqueItems":true}`, []string{`[1]`, `[1,"1"]`}, []string{`[]`, `[1,2,3]`, `[1,1.0]`}},
+		{`{"prefixItems":[{"type":"string"}],"items":{"type":"integer"}}`, []string{`[]`, `["a",1,2]`}, []string{`[1]`, `["a","b"]`}},
+		{`{"items":false}`, []string{`[]`}, []string{`[1]`}},
+		{`{"contains":{"type":"string"},"minContains":2,"maxContains":3}`, []string{`["a","b",1]`}, []string{`["a",1]`, `["a","b","c","d"]`}},
+		{`{"contains":{"type":"string"},"minContains":0}`, []string{`[]`, `[1]`}, nil},
+		{`{"required":["a","b"],"minProperties":2,"maxProperties":3}`, []string{`{"a":1,"b":2}`, `[]`}, []string{`{"a":1}`, `{"a":1,"b":2,"c":3,"d":4}`}},
+		{`{"properties":{"a":{"type":"string"}},"patternProperties":{"^x-":{"type":"integer"}},"additionalProperties":false}`,
+			[]string{`{"a":"a","x-b":1}`},
+			[]string{`{"a":1}`, `{"x-b":"b"}`, `{"b":1}`}},
+		{`{"propertyNames":{"maxLength":2}}`, []string{`{"ab":1}`}, []string{`{"abc":1}`}},
+		{`{"dependentRequired":{"a":["b"]}}`, []string{`{"b":1}`, `{"a":1,"b":1}`}, []string{`{"a":1}`}},
+		{`{"dependentSchemas":{"a":{"required":["b"]}}}`, []string{`{"b":1}`, `{"a":1,"b":1}`}, []string{`{"a":1}`}},
+		{`{"allOf":[{"type":"number"},{"minimum":2}]}`, []string{`2`}, []string{`1`, `"a"`}},
+		{`{"anyOf":[{"type":"number"},{"type":"string"}]}`, []string{`2`, `"a"`}, []string{`null`}},
+		{`{"oneOf":[{"type":"number"},{"type":"integer"}]}`, []string{`1.5`}, []string{`1`, `"a"`}},
+		{`{"not":{"type":"string"}}`, []string{`1`}, []string{`"a"`}},
+		{`{"if":{"type":"number"},"then":{"minimum":1},"else":{"type":"string"}}`, []string{`1`, `"a"`}, []string{`0`, `null`}},
+		{`{"$defs":{"pos":{"type":"integer","minimum":0}},"items":{"$ref":"#/$defs/pos"}}`, []string{`[0,1]`}, []string{`[-1]`, `[0.5]`}},
+		{`{"$defs":{"a~b":{"$anchor":"ab","type":"string"}},"properties":{"x":{"$ref":"#ab"},"y":{"$ref":"#/$defs/a~0b"}}}`, []string{`{"x":"a","y":"b"}`}, []string{`{"x":1}`, `{"y":1}`}},
+		{`{"$id":"https://example.com/tree","type":"object","properties":{"value":{"type":"integer"},"children":{"type":"array","items":{"$ref":"https://example.com/tree"}}}}`,
+			[]string{`{"value":1,"children":[{"value":2},{"children":[]}]}`},
+			[]string{`{"children":[{"children":[{"value":"x"}]}]}`}},
+		{`{"properties":{"a":true},"allOf":[{"properties":{"b":true}}],"unevaluatedProperties":false}`, []string{`{"a":1,"b":2}`}, []string{`{"a":1,"c":3}`}},
+		{`{"anyOf":[{"properties":{"a":true},"required":["a"]},{"properties":{"b":true},"required":["b"]}],"unevaluatedProperties":false}`,
+			[]string{`{"a":1}`, `{"a":1,"b":2}`},
+			[]string{`{"a":1,"c":3}`}},
+		{`{"prefixItems":[true],"unevaluatedItems":{"type":"string"}}`, []string{`[1,"a"]`}, []string{`[1,2]`}},
+		{`{"contains":{"type":"string"},"unevaluatedItems":false}`, []string{`["a"]`}, []string{`["a",1]`}},
+	} {
+		s, err := schema.Compile(json.RawMessage(test.schema))
+		if err != nil {
+			t.Errorf("%s: %v", test.schema, err)
+			continue
+		}
+		for _, doc := range test.valid {
+			if err := s.Validate(json.RawMessage(doc)); err != nil {
+				t.Errorf("%s: %s: %v", test.schema, doc, err)
+			}
+		}
+		for _, doc := range test.errors {
+			err := s.Validate(json.RawMessage(doc))
+			if err == nil {
+				t.Errorf("%s: %s: error expected", test.schema, doc)
+				continue
+			}
+			t.Logf("%s: %s: %v", test.schema, doc, err)
+			if _, ok := err.(jsonptr.Errors); !ok {
+				t.Errorf("%s: %s: jsonptr.Errors expected, got %T", test.schema, doc, err)
+			}
+		}
+	}
+}
+
+func TestValidateLocations(t *testing.T) {
+	s := schema.MustCompile(json.RawMessage(`{
+		"$defs": {"price": {"type": "number", "minimum": 0}},
+		"properties": {
+			"items": {
+				"items": {
+					"required": ["name"],
+					"properties": {"price": {"$ref": "#/$defs/price"}}
+				}
+			}
+		}
+	}`))
+	err := s.Validate(json.RawMessage(`{"items":[{"name":"a","price":1},{"price":-1},{"name":"c","price":"x"}]}`))
+	errs, ok := err.(jsonptr.Errors)
+	if !ok {
+		t.Fatalf("jsonptr.Errors expected, got %#v", err)
+	}
+	type location struct{ instance, keyword string }
+	var got []location
+	for _, e := range errs {
+		se := e.Err.(*schema.Error)
+		if e.Ptr.String() != se.InstanceLocation.String() {
+			t.Errorf("%q: location mismatch %q", e.Ptr, se.InstanceLocation)
+		}
+		got = append(got, location{se.InstanceLocation.String(), se.KeywordLocation.String()})
+	}
+	expected := []location{
+		{"/items/1", "/properties/items/items/required"},
+		{"/items/1/price", "/properties/items/items/properties/price/$ref/minimum"},
+		{"/items/2/price", "/properties/items/items/properties/price/$ref/type"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %q", got)
+	}
+	if msg := errs[0].Error(); msg != `"/items/1": required: missing properties "name"` {
+		t.Errorf("unexpected message: %s", msg)
+	}
+}
+
+func TestValidateRefLoop(t *testing.T) {
+	for _, test := range []string{
+		`{"$ref":"#"}`,
+		`{"$defs":{"a":{"$ref":"#/$defs/b"},"b":{"allOf":[{"$ref":"#/$defs/a"}]}},"properties":{"x":{"$ref":"#/$defs/a"}}}`,
+		`{"anyOf":[{"$ref":"#"}]}`,
+	} {
+		s, err := schema.Compile(json.RawMessage(test))
+		if err != nil {
+			t.Errorf("%s: %v", test, err)
+			continue
+		}
+		err = s.Validate(json.RawMessage(`{"x":1}`))
+		if errs, ok := err.(jsonptr.Errors); !ok || !strings.Contains(errs.Error(), "infinite loop of references") {
+			t.Errorf("%s: got %v", test, err)
+		} else {
+			t.Logf("%s: %v", test, err)
+		}
+	}
+
+	// A reference may be followed again for another location
+	s := schema.MustCompile(json.RawMessage(`{"properties":{"a":{"$ref":"#"}},"required":["b"]}`))
+	if err := s.Validate(json.RawMessage(`{"b":1,"a":{"b":2,"a":{"b":3}}}`)); err != nil {
+		t.Error(err)
+	}
+	if err := s.Validate(json.RawMessage(`{"b":1,"a":{"a":{"b":3}}}`)); err == nil {
+		t.Error("error expected")
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, test := range []struct {
+		schema string
+		ptr    string
+	}{
+		{`1`, ``},
+		{`{"type":"int"}`, `/type`},
+		{`{"properties":{"a":{"minimum":"0"}}}`, `/properties/a/minimum`},
+		{`{"items":[]}`, `/items`},
+		{`{"allOf":[]}`, `/allOf`},
+		{`{"pattern":"("}`, `/pattern`},
+		{`{"multipleOf":0}`, `/multipleOf`},
+		{`{"required":["a",1]}`, `/required/1`},
+		{`{"$ref":"#/$defs/missing"}`, `/$ref`},
+		{`{"$ref":"#missing"}`, `/$ref`},
+		{`{"$ref":"other.json"}`, `/$ref`},
+	} {
+		_, err := schema.Compile(json.RawMessage(test.schema))
+		if err == nil {
+			t.Errorf("%s: error expected", test.schema)
+			continue
+		}
+		t.Logf("%s: %v", test.schema, err)
+		de, ok := err.(*jsonptr.DocumentError)
+		if !ok {
+			t.Errorf("%s: DocumentError expected, got %T", test.schema, err)
+		} else if de.Ptr != test.ptr {
+			t.Errorf("%s: got location %q, expected %q", test.schema, de.Ptr, test.ptr)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	s := schema.MustCompile(json.RawMessage(`{
+		"$id": "https://example.com/polygon",
+		"$defs": {
+			"point": {
+				"type": "object",
+				"properties": {"x": {"type": "number"}, "y": {"type": "number"}},
+				"required": ["x", "y"]
+			}
+		},
+		"type": "array",
+		"items": {"$ref": "#/$defs/point"},
+		"minItems": 3
+	}`))
+	doc := json.RawMessage(`[{"x":2.5,"y":1.3},{"x":1,"z":6.7}]`)
+
+	for _, test := range []struct {
+		format   schema.Format
+		expected string
+	}{
+		{schema.Flag, `{"valid":false}`},
+		{schema.Basic, `{"valid":false,"keywordLocation":"","instanceLocation":"","errors":[` +
+			`{"valid":false,"keywordLocation":"/minItems","instanceLocation":"","error":"2 items, less than 3"},` +
+			`{"valid":false,"keywordLocation":"/items","instanceLocation":"","error":"item 1 is invalid"},` +
+			`{"valid":false,"keywordLocation":"/items/$ref","instanceLocation":"/1","error":"does not match the referenced schema"},` +
+			`{"valid":false,"keywordLocation":"/items/$ref/required","absoluteKeywordLocation":"https://example.com/polygon#/$defs/point/required","instanceLocation":"/1","error":"missing properties \"y\""}]}`},
+		{schema.Detailed, `{"valid":false,"keywordLocation":"","instanceLocation":"","errors":[` +
+			`{"valid":false,"keywordLocation":"/minItems","instanceLocation":"","error":"2 items, less than 3"},` +
+			`{"valid":false,"keywordLocation":"/items","instanceLocation":"","error":"item 1 is invalid","errors":[` +
+			`{"valid":false,"keywordLocation":"/items/$ref","instanceLocation":"/1","error":"does not match the referenced schema","errors":[` +
+			`{"valid":false,"keywordLocation":"/items/$ref/required","absoluteKeywordLocation":"https://example.com/polygon#/$defs/point/required","instanceLocation":"/1","error":"missing properties \"y\""}]}]}]}`},
+	} {
+		out, err := s.Evaluate(doc, test.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.Marshal(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != test.expected {
+			t.Errorf("format %d:\ngot      %s\nexpected %s", test.format, got, test.expected)
+		}
+	}
+
+	out, err := s.Evaluate([]interface{}{
+		map[string]interface{}{"x": 1, "y": 2},
+		map[string]interface{}{"x": 1, "y": 2},
+		map[string]interface{}{"x": 1, "y": 2},
+	}, schema.Detailed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := json.Marshal(out); string(got) != `{"valid":true,"keywordLocation":"","instanceLocation":""}` {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestValidateObject(t *testing.T) {
+	s := schema.MustCompile(json.RawMessage(`{"const":{"a":[1,{"b":true}]},"properties":{"a":{"minItems":2}}}`))
+	doc, err := jsonptr.UnmarshalOrdered([]byte(`{"a":[1,{"b":true}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Validate(doc); err != nil {
+		t.Error(err)
+	}
+}
+
+func ExampleSchema_Validate() {
+	s := schema.MustCompile(json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		},
+		"required": ["name", "tags"]
+	}`))
+	err := s.Validate(json.RawMessage(`{"name":"","tags":["a",2]}`))
+	for _, e := range err.(jsonptr.Errors) {
+		fmt.Println(e)
+	}
+	// Output:
+	// "/name": minLength: length 0 is less than 1
+	// "/tags/1": type: string expected, got number
+}
@@ -0,0 +1,876 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+// Format is an output format of the evaluation of a schema.
+//
+// Specification: https://json-schema.org/draft/2020-12/json-schema-core#section-12.4
+type Format int
+
+const (
+	// Flag reports only the validation result.
+	Flag Format = iota
+	// Basic reports a flat list of the errors.
+	Basic
+	// Detailed reports the errors in a tree following the structure of the schema.
+	Detailed
+)
+
+// Output is an output unit of the evaluation of a schema.
+type Output struct {
+	Valid bool
+	// KeywordLocation is the path to the keyword through the evaluation,
+	// including the references followed.
+	KeywordLocation jsonptr.Pointer
+	// AbsoluteKeywordLocation is the location of the keyword in the schema
+	// document, as an URI. Only set if the evaluation followed a reference.
+	AbsoluteKeywordLocation string
+	// InstanceLocation is the location in the validated document.
+	InstanceLocation jsonptr.Pointer
+	Error            string
+	Errors           []*Output
+
+	flag bool
+}
+
+// MarshalJSON implements [encoding/json.Marshaler].
+func (out *Output) MarshalJSON() ([]byte, error) {
+	if out.flag {
+		return json.Marshal(struct {
+			Valid bool `json:"valid"`
+		}{out.Valid})
+	}
+	return json.Marshal(struct {
+		Valid                   bool      `json:"valid"`
+		KeywordLocation         string    `json:"keywordLocation"`
+		AbsoluteKeywordLocation string    `json:"absoluteKeywordLocation,omitempty"`
+		InstanceLocation        string    `json:"instanceLocation"`
+		Error                   string    `json:"error,omitempty"`
+		Errors                  []*Output `json:"errors,omitempty"`
+	}{
+		out.Valid,
+		out.KeywordLocation.String(),
+		out.AbsoluteKeywordLocation,
+		out.InstanceLocation.String(),
+		out.Error,
+		out.Errors,
+	})
+}
+
+// Error is a validation error.
+type Error struct {
+	InstanceLocation jsonptr.Pointer
+	KeywordLocation  jsonptr.Pointer
+	Message          string
+}
+
+// Error implements the 'error' interface.
+func (e *Error) Error() string {
+	msg := e.Message
+	if len(e.KeywordLocation) > 0 {
+		msg = e.KeywordLocation.LeafName() + ": " + msg
+	}
+	return strconv.Quote(e.InstanceLocation.String()) + ": " + msg
+}
+
+// Validate validates a document.
+//
+// doc is either a decoded document or a serialized document
+// ([encoding/json.RawMessage]).
+//
+// If the document is invalid, the error is a [jsonptr.Errors] of *[Error],
+// located at the instance location, one for each failed assertion.
+// References looping on the same instance (such as {"$ref":"#"}) fail.
+func (s *Schema) Validate(doc interface{}) error {
+	doc, err := decode(doc)
+	if err != nil {
+		return err
+	}
+	out := s.evaluate(doc)
+	if out.Valid {
+		return nil
+	}
+	var errs jsonptr.Errors
+	var collect func(out *Output)
+	collect = func(out *Output) {
+		leaf := true
+		for _, o := range out.Errors {
+			if !o.Valid {
+				leaf = false
+				collect(o)
+			}
+		}
+		if leaf && out.Error != "" {
+			errs.Add(out.InstanceLocation, &Error{
+				InstanceLocation: out.InstanceLocation,
+				KeywordLocation:  out.KeywordLocation,
+				Message:          out.Error,
+			})
+		}
+	}
+	collect(out)
+	return errs.Err()
+}
+
+// Evaluate evaluates a document and returns the result in the given format.
+//
+// doc is either a decoded document or a serialized document
+// ([encoding/json.RawMessage]).
+func (s *Schema) Evaluate(doc interface{}, format Format) (*Output, error) {
+	doc, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	out := s.evaluate(doc)
+	switch format {
+	case Flag:
+		return &Output{Valid: out.Valid, flag: true}, nil
+	case Basic:
+		basic := &Output{Valid: out.Valid}
+		var collect func(out *Output)
+		collect = func(out *Output) {
+			if !out.Valid && out.Error != "" {
+				basic.Errors = append(basic.Errors, &Output{
+					KeywordLocation:         out.KeywordLocation,
+					AbsoluteKeywordLocation: out.AbsoluteKeywordLocation,
+					InstanceLocation:        out.InstanceLocation,
+					Error:                   out.Error,
+				})
+			}
+			for _, o := range out.Errors {
+				if !o.Valid {
+					collect(o)
+				}
+			}
+		}
+		collect(out)
+		return basic, nil
+	default:
+		if out.Valid {
+			out.Errors = nil
+			return out, nil
+		}
+		return prune(out), nil
+	}
+}
+
+// prune removes the successful units from the tree of an invalid result
+// and replaces the units with a single child by that child.
+func prune(out *Output) *Output {
+	var errs []*Output
+	for _, o := range out.Errors {
+		if !o.Valid {
+			errs = append(errs, prune(o))
+		}
+	}
+	if len(errs) == 1 && out.Error == "" && len(out.KeywordLocation) > 0 {
+		return errs[0]
+	}
+	out.Errors = errs
+	return out
+}
+
+func decode(doc interface{}) (interface{}, error) {
+	if raw, ok := doc.(json.RawMessage); ok {
+		doc = nil
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, &jsonptr.DocumentError{Err: err}
+		}
+	}
+	return doc, nil
+}
+
+func (s *Schema) evaluate(doc interface{}) *Output {
+	e := evaluator{id: s.id}
+	return e.eval(s.root, doc, nil, nil).out
+}
+
+type evaluator struct {
+	id string
+	// refs holds the references being followed, to detect loops
+	refs map[refVisit]bool
+}
+
+// refVisit is a reference target evaluated at an instance location.
+type refVisit struct {
+	n       *node
+	instLoc string
+}
+
+// result is the result of the evaluation of a schema, with the annotations
+// used by unevaluatedProperties and unevaluatedItems.
+type result struct {
+	e     *evaluator
+	n     *node
+	out   *Output
+	props map[string]bool
+	items map[int]bool
+}
+
+// unit returns a new output unit for a keyword of the schema.
+func (r *result) unit(kw string) *Output {
+	out := &Output{
+		Valid:            true,
+		KeywordLocation:  at(r.out.KeywordLocation, kw),
+		InstanceLocation: r.out.InstanceLocation,
+	}
+	abs := at(r.n.loc, kw)
+	if abs.String() != out.KeywordLocation.String() {
+		out.AbsoluteKeywordLocation = r.e.id + abs.URIFragment()
+	}
+	return out
+}
+
+// fail records the failure of an assertion keyword.
+func (r *result) fail(kw string, format string, args ...interface{}) {
+	out := r.unit(kw)
+	out.Valid = false
+	out.Error = fmt.Sprintf(format, args...)
+	r.out.Errors = append(r.out.Errors, out)
+	r.out.Valid = false
+}
+
+// merge records the result of a subschema below the unit of a keyword.
+// The annotations of a valid subschema are collected.
+func (r *result) merge(kwOut *Output, sub *result) {
+	kwOut.Errors = append(kwOut.Errors, sub.out)
+	if !sub.out.Valid {
+		kwOut.Valid = false
+		return
+	}
+	for k := range sub.props {
+		r.evaluatedProp(k)
+	}
+	for i := range sub.items {
+		r.evaluatedItem(i)
+	}
+}
+
+// close records the unit of an applicator keyword. If invalid, the error
+// message is formatted from format and args.
+func (r *result) close(kwOut *Output, format string, args ...interface{}) {
+	if !kwOut.Valid {
+		kwOut.Error = fmt.Sprintf(format, args...)
+		r.out.Valid = false
+	}
+	r.out.Errors = append(r.out.Errors, kwOut)
+}
+
+func (r *result) evaluatedProp(name string) {
+	if r.props == nil {
+		r.props = make(map[string]bool)
+	}
+	r.props[name] = true
+}
+
+func (r *result) evaluatedItem(index int) {
+	if r.items == nil {
+		r.items = make(map[int]bool)
+	}
+	r.items[index] = true
+}
+
+func (e *evaluator) eval(n *node, inst interface{}, instLoc, kwLoc jsonptr.Pointer) *result {
+	r := &result{
+		e: e,
+		n: n,
+		out: &Output{
+			Valid:            true,
+			KeywordLocation:  kwLoc,
+			InstanceLocation: instLoc,
+		},
+	}
+	if n.loc.String() != kwLoc.String() {
+		r.out.AbsoluteKeywordLocation = e.id + n.loc.URIFragment()
+	}
+	if n.isBool {
+		if !n.value {
+			r.out.Valid = false
+			r.out.Error = "no value is allowed"
+		}
+		return r
+	}
+
+	if n.ref != nil {
+		// Following the same reference again for the same instance would
+		// never end
+		visit := refVisit{n.ref, instLoc.String()}
+		if e.refs[visit] {
+			r.fail(n.refKey, "infinite loop of references")
+		} else {
+			if e.refs == nil {
+				e.refs = make(map[refVisit]bool)
+			}
+			e.refs[visit] = true
+			kwOut := r.unit(n.refKey)
+			r.merge(kwOut, e.eval(n.ref, inst, instLoc, kwOut.KeywordLocation))
+			r.close(kwOut, "does not match the referenced schema")
+			delete(e.refs, visit)
+		}
+	}
+
+	e.evalLogic(r, inst)
+
+	t := jsonType(inst)
+	e.evalAny(r, inst, t)
+	switch t {
+	case "number":
+		e.evalNumber(r, inst)
+	case "string":
+		e.evalString(r, inst.(string))
+	case "array":
+		e.evalArray(r, inst.([]interface{}))
+	case "object":
+		e.evalObject(r, inst)
+	}
+	return r
+}
+
+// evalLogic evaluates the in-place applicators.
+func (e *evaluator) evalLogic(r *result, inst interface{}) {
+	n := r.n
+	instLoc := r.out.InstanceLocation
+
+	if n.allOf != nil {
+		kwOut := r.unit("allOf")
+		var failed []string
+		for i, sub := range n.allOf {
+			s := e.eval(sub, inst, instLoc, at(kwOut.KeywordLocation, strconv.Itoa(i)))
+			r.merge(kwOut, s)
+			if !s.out.Valid {
+				failed = append(failed, strconv.Itoa(i))
+			}
+		}
+		r.close(kwOut, "does not match subschemas %s", strings.Join(failed, ", "))
+	}
+
+	if n.anyOf != nil {
+		kwOut := r.unit("anyOf")
+		matched := false
+		for i, sub := range n.anyOf {
+			s := e.eval(sub, inst, instLoc, at(kwOut.KeywordLocation, strconv.Itoa(i)))
+			kwOut.Errors = append(kwOut.Errors, s.out)
+			if s.out.Valid {
+				matched = true
+				r.merge(&Output{}, s)
+			}
+		}
+		kwOut.Valid = matched
+		r.close(kwOut, "does not match any subschema")
+	}
+
+	if n.oneOf != nil {
+		kwOut := r.unit("oneOf")
+		var matched []string
+		var valid *result
+		for i, sub := range n.oneOf {
+			s := e.eval(sub, inst, instLoc, at(kwOut.KeywordLocation, strconv.Itoa(i)))
+			kwOut.Errors = append(kwOut.Errors, s.out)
+			if s.out.Valid {
+				matched = append(matched, strconv.Itoa(i))
+				valid = s
+			}
+		}
+		switch len(matched) {
+		case 0:
+			kwOut.Valid = false
+			r.close(kwOut, "does not match any subschema")
+		case 1:
+			r.merge(&Output{}, valid)
+			r.close(kwOut, "")
+		default:
+			kwOut.Valid = false
+			r.close(kwOut, "matches more than one subschema: %s", strings.Join(matched, ", "))
+		}
+	}
+
+	if n.not != nil {
+		kwOut := r.unit("not")
+		s := e.eval(n.not, inst, instLoc, kwOut.KeywordLocation)
+		kwOut.Valid = !s.out.Valid
+		r.close(kwOut, "matches a forbidden schema")
+	}
+
+	if n.ifSchema != nil {
+		ifOut := r.unit("if")
+		s := e.eval(n.ifSchema, inst, instLoc, ifOut.KeywordLocation)
+		var kw string
+		var branch *node
+		if s.out.Valid {
+			r.merge(&Output{}, s)
+			kw, branch = "then", n.thenSchema
+		} else {
+			kw, branch = "else", n.elseSchema
+		}
+		if branch != nil {
+			kwOut := r.unit(kw)
+			r.merge(kwOut, e.eval(branch, inst, instLoc, kwOut.KeywordLocation))
+			r.close(kwOut, "does not match the %q subschema", kw)
+		}
+	}
+
+	if n.dependentSchemas != nil {
+		kwOut := r.unit("dependentSchemas")
+		var failed []string
+		names, _ := keys(inst)
+		for _, name := range names {
+			sub, ok := n.dependentSchemas[name]
+			if !ok {
+				continue
+			}
+			s := e.eval(sub, inst, instLoc, at(kwOut.KeywordLocation, name))
+			r.merge(kwOut, s)
+			if !s.out.Valid {
+				failed = append(failed, strconv.Quote(name))
+			}
+		}
+		r.close(kwOut, "does not match the schemas depending on %s", strings.Join(failed, ", "))
+	}
+}
+
+// evalAny evaluates the assertions applying to any type.
+func (e *evaluator) evalAny(r *result, inst interface{}, t string) {
+	n := r.n
+	if n.types != nil {
+		ok := false
+		for _, typ := range n.types {
+			if typ == t || (typ == "integer" && t == "number" && isInteger(inst)) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			if len(n.types) == 1 {
+				r.fail("type", "%s expected, got %s", n.types[0], t)
+			} else {
+				r.fail("type", "one of %s expected, got %s", strings.Join(n.types, ", "), t)
+			}
+		}
+	}
+	if n.hasEnum {
+		ok := false
+		for _, v := range n.enum {
+			if equal(inst, v) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			r.fail("enum", "value is not one of the allowed values")
+		}
+	}
+	if n.hasConst && !equal(inst, n.constValue) {
+		r.fail("const", "value is not the expected constant")
+	}
+}
+
+func (e *evaluator) evalNumber(r *result, inst interface{}) {
+	n := r.n
+	f, _ := number(inst)
+	if n.multipleOf != nil {
+		if d := decimal(inst); d == nil || !d.Quo(d, n.multipleOf).IsInt() {
+			r.fail("multipleOf", "%v is not a multiple of %v", f, n.multipleOf.RatString())
+		}
+	}
+	if n.maximum != nil && f > *n.maximum {
+		r.fail("maximum", "%v is greater than %v", f, *n.maximum)
+	}
+	if n.exclusiveMaximum != nil && f >= *n.exclusiveMaximum {
+		r.fail("exclusiveMaximum", "%v is not less than %v", f, *n.exclusiveMaximum)
+	}
+	if n.minimum != nil && f < *n.minimum {
+		r.fail("minimum", "%v is less than %v", f, *n.minimum)
+	}
+	if n.exclusiveMinimum != nil && f <= *n.exclusiveMinimum {
+		r.fail("exclusiveMinimum", "%v is not greater than %v", f, *n.exclusiveMinimum)
+	}
+}
+
+func (e *evaluator) evalString(r *result, s string) {
+	n := r.n
+	if n.maxLength >= 0 || n.minLength > 0 {
+		l := utf8.RuneCountInString(s)
+		if n.maxLength >= 0 && l > n.maxLength {
+			r.fail("maxLength", "length %d is greater than %d", l, n.maxLength)
+		}
+		if l < n.minLength {
+			r.fail("minLength", "length %d is less than %d", l, n.minLength)
+		}
+	}
+	if n.pattern != nil && !n.pattern.MatchString(s) {
+		r.fail("pattern", "does not match pattern %q", n.pattern.String())
+	}
+}
+
+func (e *evaluator) evalArray(r *result, arr []interface{}) {
+	n := r.n
+	instLoc := r.out.InstanceLocation
+
+	if n.maxItems >= 0 && len(arr) > n.maxItems {
+		r.fail("maxItems", "%d items, more than %d", len(arr), n.maxItems)
+	}
+	if len(arr) < n.minItems {
+		r.fail("minItems", "%d items, less than %d", len(arr), n.minItems)
+	}
+	if n.uniqueItems {
+	unique:
+		for i := 1; i < len(arr); i++ {
+			for j := 0; j < i; j++ {
+				if equal(arr[i], arr[j]) {
+					r.fail("uniqueItems", "items %d and %d are equal", j, i)
+					break unique
+				}
+			}
+		}
+	}
+
+	// evalItems applies a subschema to an item
+	evalItems := func(kwOut *Output, sub *node, i int, kwLoc jsonptr.Pointer) bool {
+		s := e.eval(sub, arr[i], at(instLoc, strconv.Itoa(i)), kwLoc)
+		kwOut.Errors = append(kwOut.Errors, s.out)
+		if !s.out.Valid {
+			kwOut.Valid = false
+			return false
+		}
+		r.evaluatedItem(i)
+		return true
+	}
+	failed := func(list []string) string {
+		if len(list) == 1 {
+			return "item " + list[0] + " is invalid"
+		}
+		return "items " + strings.Join(list, ", ") + " are invalid"
+	}
+
+	if n.prefixItems != nil {
+		kwOut := r.unit("prefixItems")
+		var bad []string
+		for i, sub := range n.prefixItems {
+			if i >= len(arr) {
+				break
+			}
+			if !evalItems(kwOut, sub, i, at(kwOut.KeywordLocation, strconv.Itoa(i))) {
+				bad = append(bad, strconv.Itoa(i))
+			}
+		}
+		r.close(kwOut, "%s", failed(bad))
+	}
+	if n.items != nil {
+		kwOut := r.unit("items")
+		var bad []string
+		for i := len(n.prefixItems); i < len(arr); i++ {
+			if !evalItems(kwOut, n.items, i, kwOut.KeywordLocation) {
+				bad = append(bad, strconv.Itoa(i))
+			}
+		}
+		r.close(kwOut, "%s", failed(bad))
+	}
+	if n.contains != nil {
+		kwOut := r.unit("contains")
+		count := 0
+		for i := range arr {
+			s := e.eval(n.contains, arr[i], at(instLoc, strconv.Itoa(i)), kwOut.KeywordLocation)
+			if s.out.Valid {
+				count++
+				r.evaluatedItem(i)
+			}
+		}
+		if count < n.minContains {
+			r.fail("contains", "%d matching items, less than %d", count, n.minContains)
+		}
+		if n.maxContains >= 0 && count > n.maxContains {
+			r.fail("maxContains", "%d matching items, more than %d", count, n.maxContains)
+		}
+	}
+
+	if n.unevaluatedItems != nil {
+		kwOut := r.unit("unevaluatedItems")
+		var bad []string
+		for i := range arr {
+			if r.items[i] {
+				continue
+			}
+			if !evalItems(kwOut, n.unevaluatedItems, i, kwOut.KeywordLocation) {
+				bad = append(bad, strconv.Itoa(i))
+			}
+		}
+		r.close(kwOut, "%s", failed(bad))
+	}
+}
+
+func (e *evaluator) evalObject(r *result, obj interface{}) {
+	n := r.n
+	instLoc := r.out.InstanceLocation
+	names, _ := keys(obj)
+
+	if n.maxProperties >= 0 && len(names) > n.maxProperties {
+		r.fail("maxProperties", "%d properties, more than %d", len(names), n.maxProperties)
+	}
+	if len(names) < n.minProperties {
+		r.fail("minProperties", "%d properties, less than %d", len(names), n.minProperties)
+	}
+	if n.required != nil {
+		var missing []string
+		for _, name := range n.required {
+			if _, ok := lookup(obj, name); !ok {
+				missing = append(missing, strconv.Quote(name))
+			}
+		}
+		if missing != nil {
+			r.fail("required", "missing properties %s", strings.Join(missing, ", "))
+		}
+	}
+	if n.dependentRequired != nil {
+		for _, name := range names {
+			var missing []string
+			for _, dep := range n.dependentRequired[name] {
+				if _, ok := lookup(obj, dep); !ok {
+					missing = append(missing, strconv.Quote(dep))
+				}
+			}
+			if missing != nil {
+				r.fail("dependentRequired", "missing properties %s, required by %q", strings.Join(missing, ", "), name)
+			}
+		}
+	}
+
+	// evalProp applies a subschema to a property
+	evalProp := func(kwOut *Output, sub *node, name string, kwLoc jsonptr.Pointer) bool {
+		s := e.eval(sub, get(obj, name), at(instLoc, name), kwLoc)
+		kwOut.Errors = append(kwOut.Errors, s.out)
+		if !s.out.Valid {
+			kwOut.Valid = false
+			return false
+		}
+		r.evaluatedProp(name)
+		return true
+	}
+	failed := func(list []string) string {
+		if len(list) == 1 {
+			return "property " + list[0] + " is invalid"
+		}
+		return "properties " + strings.Join(list, ", ") + " are invalid"
+	}
+
+	// Properties matched by properties or patternProperties
+	var matched map[string]bool
+	if n.additionalProps != nil {
+		matched = make(map[string]bool)
+	}
+
+	if n.properties != nil {
+		kwOut := r.unit("properties")
+		var bad []string
+		for _, name := range names {
+			sub, ok := n.properties[name]
+			if !ok {
+				continue
+			}
+			if matched != nil {
+				matched[name] = true
+			}
+			if !evalProp(kwOut, sub, name, at(kwOut.KeywordLocation, name)) {
+				bad = append(bad, strconv.Quote(name))
+			}
+		}
+		r.close(kwOut, "%s", failed(bad))
+	}
+	if n.patternProperties != nil {
+		kwOut := r.unit("patternProperties")
+		var bad []string
+		for _, name := range names {
+			ok := true
+			for _, p := range n.patternProperties {
+				if !p.re.MatchString(name) {
+					continue
+				}
+				if matched != nil {
+					matched[name] = true
+				}
+				if !evalProp(kwOut, p.node, name, at(kwOut.KeywordLocation, p.source)) {
+					ok = false
+				}
+			}
+			if !ok {
+				bad = append(bad, strconv.Quote(name))
+			}
+		}
+		r.close(kwOut, "%s", failed(bad))
+	}
+	if n.additionalProps != nil {
+		kwOut := r.unit("additionalProperties")
+		var bad []string
+		for _, name := range names {
+			if matched[name] {
+				continue
+			}
+			if !evalProp(kwOut, n.additionalProps, name, kwOut.KeywordLocation) {
+				bad = append(bad, strconv.Quote(name))
+			}
+		}
+		r.close(kwOut, "%s", failed(bad))
+	}
+	if n.propertyNames != nil {
+		kwOut := r.unit("propertyNames")
+		var bad []string
+		for _, name := range names {
+			s := e.eval(n.propertyNames, name, at(instLoc, name), kwOut.KeywordLocation)
+			kwOut.Errors = append(kwOut.Errors, s.out)
+			if !s.out.Valid {
+				kwOut.Valid = false
+				bad = append(bad, strconv.Quote(name))
+			}
+		}
+		r.close(kwOut, "invalid property names %s", strings.Join(bad, ", "))
+	}
+
+	if n.unevaluatedProps != nil {
+		kwOut := r.unit("unevaluatedProperties")
+		var bad []string
+		for _, name := range names {
+			if r.props[name] {
+				continue
+			}
+			if !evalProp(kwOut, n.unevaluatedProps, name, kwOut.KeywordLocation) {
+				bad = append(bad, strconv.Quote(name))
+			}
+		}
+		r.close(kwOut, "%s", failed(bad))
+	}
+}
+
+// jsonType returns the JSON type of a value of the data model.
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}, *jsonptr.Object:
+		return "object"
+	}
+	if _, ok := number(v); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// number converts a number of the data model to float64.
+func number(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// decimal converts a number of the data model to an exact rational,
+// using its shortest decimal representation.
+func decimal(v interface{}) *big.Rat {
+	var s string
+	if n, ok := v.(json.Number); ok {
+		s = string(n)
+	} else if f, ok := number(v); ok && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		s = strconv.FormatFloat(f, 'g', -1, 64)
+	} else {
+		return nil
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil
+	}
+	return r
+}
+
+func isInteger(v interface{}) bool {
+	f, _ := number(v)
+	return f == math.Trunc(f) && !math.IsInf(f, 0)
+}
+
+// equal reports if values of the data model are equal in the JSON sense:
+// numbers are compared by value, objects regardless of the order of properties.
+func equal(a, b interface{}) bool {
+	if fa, ok := number(a); ok {
+		fb, ok := number(b)
+		return ok && fa == fb
+	}
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case bool:
+		b, ok := b.(bool)
+		return ok && a == b
+	case string:
+		b, ok := b.(string)
+		return ok && a == b
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	ka, ok := keys(a)
+	if !ok {
+		return false
+	}
+	kb, ok := keys(b)
+	if !ok || len(ka) != len(kb) {
+		return false
+	}
+	for _, k := range ka {
+		vb, ok := lookup(b, k)
+		if !ok || !equal(get(a, k), vb) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// Seek moves decoder to the object or array pointed by ptr, without
+// buffering the skipped values. On success, the decoder is left just
+// inside the target container (its opening delimiter has been read), ready
+// for [EachElement] or [EachProperty], or for use of the decoder's own
+// methods (More, Token, Decode).
+//
+// Unlike [Get], Seek stops at the first occurrence of a key in an object,
+// as reading ahead for duplicates would defeat streaming.
+func Seek(decoder JSONDecoder, ptr Pointer) error {
+	for i := 0; ; i++ {
+		tok, err := decoder.Token()
+		if err != nil {
+			return jsonError(ptr[:i].String(), err)
+		}
+		delim, ok := tok.(json.Delim)
+		if !ok {
+			return docError(ptr[:i].String(), tok)
+		}
+		if i == len(ptr) {
+			return nil
+		}
+		switch delim {
+		case '{':
+			var keys []string
+			found := false
+			for decoder.More() {
+				tok, err := decoder.Token()
+				if err != nil {
+					return jsonError(ptr[:i+1].String(), err)
+				}
+				if tok.(string) == ptr[i] {
+					found = true
+					break
+				}
+				keys = append(keys, tok.(string))
+				if err := skipValue(decoder); err != nil {
+					return jsonError(ptr[:i+1].String(), err)
+				}
+			}
+			if !found {
+				return propertyError(ptr[:i+1].String(), ptr[i], keys)
+			}
+		case '[':
+			n, err := arrayIndex(ptr[i])
+			if err != nil {
+				return tokenError(ptr.String(), len(ptr[:i+1].String()), err)
+			}
+			if n < 0 {
+				return indexError(ptr[:i+1].String(), -1)
+			}
+			j := -1
+			for decoder.More() {
+				j++
+				if j == n {
+					break
+				}
+				if err := skipValue(decoder); err != nil {
+					return jsonError(ptr[:i+1].String(), err)
+				}
+			}
+			if j < n {
+				return indexError(ptr[:i+1].String(), j+1)
+			}
+		}
+	}
+}
+
+// EachElement calls fn for each remaining element of the array in which decoder
+// is positioned (see [Seek]), then reads the closing ']'.
+// Elements are read one at a time, so the array is never fully buffered.
+//
+// If fn returns an error, the iteration stops and that error is returned.
+// Errors from the decoder are returned as a DocumentError with a pointer
+// relative to the array.
+func EachElement(decoder JSONDecoder, fn func(index int, value json.RawMessage) error) error {
+	for i := 0; decoder.More(); i++ {
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return jsonError("/"+strconv.Itoa(i), err)
+		}
+		if err := fn(i, value); err != nil {
+			return err
+		}
+	}
+	if _, err := decoder.Token(); err != nil {
+		return jsonError("", err)
+	}
+	return nil
+}
+
+// EachProperty calls fn for each remaining property of the object in which
+// decoder is positioned (see [Seek]), then reads the closing '}'.
+// Properties are read one at a time, so the object is never fully buffered.
+//
+// If fn returns an error, the iteration stops and that error is returned.
+// Errors from the decoder are returned as a DocumentError with a pointer
+// relative to the object.
+func EachProperty(decoder JSONDecoder, fn func(key string, value json.RawMessage) error) error {
+	for decoder.More() {
+		tok, err := decoder.Token()
+		if err != nil {
+			return jsonError("", err)
+		}
+		key, ok := tok.(string)
+		if !ok {
+			// decoder is not inside an object
+			return docError("", tok)
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return jsonError("/"+EscapeString(key), err)
+		}
+		if err := fn(key, value); err != nil {
+			return err
+		}
+	}
+	if _, err := decoder.Token(); err != nil {
+		return jsonError("", err)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+func TestSeek(t *testing.T) {
+	const doc = `{"a":1,"b":{"c":[10,{"d":[]},[5,6]],"e":{}},"f":"x"}`
+
+	for _, test := range []struct {
+		ptr    jsonptr.Pointer
+		next   string // JSON of the remaining members, or error
+		errPtr string
+	}{
+		{nil, `{"a":1,"b":{"c":[10,{"d":[]},[5,6]],"e":{}},"f":"x"}`, ""},
+		{jsonptr.Pointer{"b"}, `{"c":[10,{"d":[]},[5,6]],"e":{}}`, ""},
+		{jsonptr.Pointer{"b", "c"}, `[10,{"d":[]},[5,6]]`, ""},
+		{jsonptr.Pointer{"b", "c", "1"}, `{"d":[]}`, ""},
+		{jsonptr.Pointer{"b", "c", "1", "d"}, `[]`, ""},
+		{jsonptr.Pointer{"b", "c", "2"}, `[5,6]`, ""},
+		{jsonptr.Pointer{"b", "e"}, `{}`, ""},
+		{jsonptr.Pointer{"a"}, ``, "/a"},
+		{jsonptr.Pointer{"x"}, ``, "/x"},
+		{jsonptr.Pointer{"b", "c", "3"}, ``, "/b/c/3"},
+		{jsonptr.Pointer{"b", "c", "-"}, ``, "/b/c/-"},
+		{jsonptr.Pointer{"b", "c", "0", "x"}, ``, "/b/c/0"},
+	} {
+		decoder := json.NewDecoder(iotest.OneByteReader(strings.NewReader(doc)))
+		err := jsonptr.Seek(decoder, test.ptr)
+		if test.errPtr != "" {
+			var errPtr string
+			switch err := err.(type) {
+			case *jsonptr.PtrError:
+				errPtr = err.Ptr
+			case *jsonptr.DocumentError:
+				errPtr = err.Ptr
+			default:
+				t.Errorf("%q: unexpected error %#v", test.ptr, err)
+				continue
+			}
+			if errPtr != test.errPtr {
+				t.Errorf("%q: got error %q", test.ptr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.ptr, err)
+			continue
+		}
+
+		var isObject bool
+		if v, err := jsonptr.Get(json.RawMessage(test.next), ""); err == nil {
+			_, isObject = v.(map[string]interface{})
+		}
+		var got []string
+		if isObject {
+			err = jsonptr.EachProperty(decoder, func(key string, value json.RawMessage) error {
+				got = append(got, fmt.Sprintf("%q:%s", key, value))
+				return nil
+			})
+			if s := "{" + strings.Join(got, ",") + "}"; err == nil && s != test.next {
+				t.Errorf("%q: got %s", test.ptr, s)
+			}
+		} else {
+			err = jsonptr.EachElement(decoder, func(index int, value json.RawMessage) error {
+				if index != len(got) {
+					t.Errorf("%q: unexpected index %d", test.ptr, index)
+				}
+				got = append(got, string(value))
+				return nil
+			})
+			if s := "[" + strings.Join(got, ",") + "]"; err == nil && s != test.next {
+				t.Errorf("%q: got %s", test.ptr, s)
+			}
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.ptr, err)
+		}
+	}
+}
+
+// tokenDecoder is a JSONDecoder on which Decode is forbidden.
+type tokenDecoder struct {
+	*json.Decoder
+}
+
+func (tokenDecoder) Decode(interface{}) error {
+	return fmt.Errorf("Decode called")
+}
+
+func TestSeekSkipsByToken(t *testing.T) {
+	const doc = `{"a":{"big":[1,[2,{"x":"y"}],{}]},"b":[[],{"c":[3]}],"d":[7]}`
+	decoder := tokenDecoder{json.NewDecoder(strings.NewReader(doc))}
+	if err := jsonptr.Seek(decoder, jsonptr.Pointer{"d"}); err != nil {
+		t.Fatal(err)
+	}
+	if tok, err := decoder.Token(); err != nil || tok != 7.0 {
+		t.Errorf("got %v, %v", tok, err)
+	}
+
+	decoder = tokenDecoder{json.NewDecoder(strings.NewReader(`{"a":[1,{"b":`))}
+	if err := jsonptr.Seek(decoder, jsonptr.Pointer{"x"}); err == nil {
+		t.Error("error expected")
+	} else {
+		t.Log(err)
+	}
+}
+
+func TestSeekErrorsLikeGet(t *testing.T) {
+	const doc = `{"a":[1,[2,3]]}`
+	for _, ptr := range []jsonptr.Pointer{{"a", "x"}, {"a", "01"}, {"a", ""}, {"a", "-"}, {"a", "2"}, {"a", "1", "y"}} {
+		err := jsonptr.Seek(json.NewDecoder(strings.NewReader(doc)), append(ptr, "z"))
+		_, expected := jsonptr.Get(json.NewDecoder(strings.NewReader(doc)), ptr.String()+"/z")
+		if fmt.Sprintf("%#v", err) != fmt.Sprintf("%#v", expected) {
+			t.Errorf("%q: got %#v, expected %#v", ptr, err, expected)
+		}
+	}
+}
+
+func TestEachElementStop(t *testing.T) {
+	decoder := json.NewDecoder(strings.NewReader(`[1,2,3]`))
+	if err := jsonptr.Seek(decoder, nil); err != nil {
+		t.Fatal(err)
+	}
+	stop := fmt.Errorf("stop")
+	var n int
+	err := jsonptr.EachElement(decoder, func(index int, value json.RawMessage) error {
+		n++
+		if index == 1 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || n != 2 {
+		t.Fatalf("got %v after %d elements", err, n)
+	}
+}
+
+func ExampleSeek() {
+	stream := strings.NewReader(`{"count":3,"items":[{"id":"a"},{"id":"b"},{"id":"c"}]}`)
+	decoder := json.NewDecoder(stream)
+
+	if err := jsonptr.Seek(decoder, jsonptr.Pointer{"items"}); err != nil {
+		panic(err)
+	}
+	err := jsonptr.EachElement(decoder, func(index int, value json.RawMessage) error {
+		var item struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(value, &item); err != nil {
+			return err
+		}
+		fmt.Println(index, item.ID)
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	// Output:
+	// 0 a
+	// 1 b
+	// 2 c
+}
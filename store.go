@@ -0,0 +1,328 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+import (
+	"strings"
+	"sync"
+)
+
+// Store is a document shared by goroutines. Reads are done under a read
+// lock, updates under a write lock, in atomic transactions. Watchers are
+// notified of the changes that touch a subtree.
+//
+// The document must be a deserialized document (made of []interface{},
+// map[string]interface{}, *[Object] and terminal values). Updates copy the
+// containers on the path of each change instead of modifying them: values
+// returned by the Store (and carried by events) are never modified, and may
+// be read without lock after the call. They must not be modified either.
+type Store struct {
+	mu  sync.RWMutex
+	doc interface{}
+
+	// Notifications are delivered in the order of the updates: each update
+	// takes a ticket under mu, then waits for its turn without holding mu,
+	// so that watchers can read the store
+	notifyMu   sync.Mutex
+	notifyCond *sync.Cond
+	tickets    uint64 // next ticket, protected by mu
+	served     uint64 // ticket being notified, protected by notifyMu
+	watchers   []*watcher
+}
+
+// Event is a change notified to watchers of a [Store].
+type Event struct {
+	// Ptr is the location of the change (a "-" array index is resolved).
+	Ptr Pointer
+	// Op is "add", "replace" or "remove", as in JSON Patch (RFC 6902).
+	Op string
+	// Old is the previous value, for "replace" and "remove".
+	Old interface{}
+	// New is the new value, for "add" and "replace".
+	New interface{}
+}
+
+type watcher struct {
+	prefix Pointer
+	fn     func(Event)
+}
+
+// NewStore returns a Store holding doc.
+func NewStore(doc interface{}) *Store {
+	return &Store{doc: doc}
+}
+
+// Get is like the [Get] function, on the document of the store.
+func (s *Store) Get(ptr string) (interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return Get(s.doc, ptr)
+}
+
+// View calls fn with the document, under a read lock. The document must not
+// be modified.
+func (s *Store) View(fn func(doc interface{}) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return fn(s.doc)
+}
+
+// Set is like the [Set] function, on the document of the store.
+func (s *Store) Set(ptr string, value interface{}) error {
+	return s.Update(func(tx *Tx) error {
+		return tx.Set(ptr, value)
+	})
+}
+
+// Delete is like the [Delete] function, on the document of the store.
+func (s *Store) Delete(ptr string) (interface{}, error) {
+	var old interface{}
+	err := s.Update(func(tx *Tx) (err error) {
+		old, err = tx.Delete(ptr)
+		return err
+	})
+	return old, err
+}
+
+// Tx is a transaction on a [Store], open during the call to [Store.Update].
+type Tx struct {
+	doc *interface{}
+	// root is the document before the transaction, for rollback
+	root interface{}
+	// owned holds the locations of the containers copied in the
+	// transaction, which can be modified in place
+	owned   map[string]bool
+	changes []*change
+}
+
+// Get is like the [Get] function, and sees the changes made in the transaction.
+func (tx *Tx) Get(ptr string) (interface{}, error) {
+	return Get(*tx.doc, ptr)
+}
+
+// Set is like the [Set] function.
+func (tx *Tx) Set(ptr string, value interface{}) error {
+	if err := checkSyntax(ptr); err != nil {
+		return err
+	}
+	tx.own(ptr)
+	c, err := setChange(tx.doc, ptr, value, nil)
+	if err != nil {
+		return err
+	}
+	tx.changed(c)
+	return nil
+}
+
+// Delete is like the [Delete] function.
+func (tx *Tx) Delete(ptr string) (interface{}, error) {
+	if err := checkSyntax(ptr); err != nil {
+		return nil, err
+	}
+	tx.own(ptr)
+	c, err := removeChange(tx.doc, ptr, nil)
+	if err != nil {
+		return nil, err
+	}
+	tx.changed(c)
+	return c.old, nil
+}
+
+// own replaces the containers on the path to the parent of ptr by copies,
+// unless they have already been copied in the transaction, so that the
+// change at ptr doesn't modify the document published before.
+func (tx *Tx) own(ptr string) {
+	if ptr == "" || ptr[0] != '/' {
+		// Errors are reported by the change
+		return
+	}
+	if tx.owned == nil {
+		tx.owned = make(map[string]bool)
+	}
+	parentPtr := ptr[:strings.LastIndexByte(ptr, '/')]
+	for p := 0; p >= 0; {
+		prefix := parentPtr[:p]
+		if !tx.owned[prefix] {
+			v, err := get(*tx.doc, prefix, nil)
+			if err != nil {
+				return
+			}
+			if v, ok := copyContainer(v); !ok {
+				return
+			} else if err := set(tx.doc, prefix, v, nil); err != nil {
+				return
+			}
+			tx.owned[prefix] = true
+		}
+		if p == len(parentPtr) {
+			break
+		}
+		if q := strings.IndexByte(parentPtr[p+1:], '/'); q >= 0 {
+			p += q + 1
+		} else {
+			p = len(parentPtr)
+		}
+	}
+}
+
+// changed records change c. The containers replaced or moved by c are no
+// longer owned.
+func (tx *Tx) changed(c *change) {
+	tx.changes = append(tx.changes, c)
+	prefix := c.ptr
+	if c.shift {
+		prefix = prefix[:strings.LastIndexByte(prefix, '/')]
+	} else {
+		delete(tx.owned, prefix)
+	}
+	prefix += "/"
+	for ptr := range tx.owned {
+		if strings.HasPrefix(ptr, prefix) {
+			delete(tx.owned, ptr)
+		}
+	}
+}
+
+// rollback reverts the changes of the transaction: the document before the
+// transaction has not been modified.
+func (tx *Tx) rollback() {
+	*tx.doc = tx.root
+	tx.changes = nil
+	tx.owned = nil
+}
+
+// copyContainer returns a shallow copy of an array or object.
+func copyContainer(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, x := range v {
+			m[k] = x
+		}
+		return m, true
+	case *Object:
+		if v == nil {
+			return v, true
+		}
+		obj := &Object{
+			keys:   append(make([]string, 0, len(v.keys)), v.keys...),
+			values: make(map[string]interface{}, len(v.values)),
+		}
+		for k, x := range v.values {
+			obj.values[k] = x
+		}
+		return obj, true
+	case []interface{}:
+		return append(make([]interface{}, 0, len(v)), v...), true
+	default:
+		return copyTyped(v)
+	}
+}
+
+// Update runs fn in a transaction, under the write lock: if fn returns an
+// error (or panics), all the changes made through tx are reverted and the
+// error is returned. Otherwise the watchers are notified of the changes,
+// once the lock is released.
+//
+// Watchers are notified synchronously, in the goroutine of Update. They may
+// read the store, but must not update it (except from another goroutine).
+func (s *Store) Update(fn func(tx *Tx) error) error {
+	s.mu.Lock()
+	tx := &Tx{doc: &s.doc, root: s.doc}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.rollback()
+			s.mu.Unlock()
+		}
+	}()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	committed = true
+	if len(tx.changes) == 0 {
+		s.mu.Unlock()
+		return nil
+	}
+	ticket := s.tickets
+	s.tickets++
+	watchers := s.watchers
+	s.mu.Unlock()
+
+	s.notifyMu.Lock()
+	if s.notifyCond == nil {
+		s.notifyCond = sync.NewCond(&s.notifyMu)
+	}
+	for s.served != ticket {
+		s.notifyCond.Wait()
+	}
+	s.notifyMu.Unlock()
+	defer func() {
+		s.notifyMu.Lock()
+		s.served++
+		s.notifyCond.Broadcast()
+		s.notifyMu.Unlock()
+	}()
+
+	for _, c := range tx.changes {
+		var ev *Event
+		ptr, _ := Parse(c.ptr)
+		for _, w := range watchers {
+			if !touches(c, ptr, w.prefix) {
+				continue
+			}
+			if ev == nil {
+				ev = &Event{Ptr: ptr, Op: c.op, Old: c.old, New: c.value}
+			}
+			w.fn(*ev)
+		}
+	}
+	return nil
+}
+
+// touches reports if change c at ptr affects the subtree at prefix.
+func touches(c *change, ptr Pointer, prefix Pointer) bool {
+	n := len(ptr)
+	if len(prefix) < n {
+		n = len(prefix)
+	}
+	for i := 0; i < n; i++ {
+		if ptr[i] != prefix[i] {
+			// Elements following a removed array element have moved
+			if c.shift && i == len(ptr)-1 {
+				removed, _ := arrayIndex(ptr[i])
+				moved, err := arrayIndex(prefix[i])
+				return err == nil && moved > removed
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// Watch registers fn to be notified of the changes touching the subtree at
+// prefix: changes inside it, or changes of a parent (which replace it).
+// When an array element is removed, the following elements are considered
+// changed.
+//
+// Watch returns a function that cancels the subscription.
+func (s *Store) Watch(prefix Pointer, fn func(Event)) (cancel func()) {
+	w := &watcher{prefix: prefix.Copy(), fn: fn}
+	s.mu.Lock()
+	// Copy on write, as the list may be in use by notifications
+	s.watchers = append(s.watchers[:len(s.watchers):len(s.watchers)], w)
+	s.mu.Unlock()
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, x := range s.watchers {
+			if x == w {
+				watchers := make([]*watcher, 0, len(s.watchers)-1)
+				s.watchers = append(append(watchers, s.watchers[:i]...), s.watchers[i+1:]...)
+				return
+			}
+		}
+	}
+}
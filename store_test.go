@@ -0,0 +1,498 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+func TestStoreRollback(t *testing.T) {
+	const original = `{"a":{"x":1,"y":2,"z":3},"b":[1,2,3],"c":"c","m":{"k":true}}`
+	doc, err := jsonptr.UnmarshalOrdered([]byte(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := jsonptr.NewStore(doc)
+	fail := errors.New("fail")
+	for _, ops := range []func(tx *jsonptr.Tx) error{
+		func(tx *jsonptr.Tx) error { return tx.Set("/c", "x") },
+		func(tx *jsonptr.Tx) error { return tx.Set("/d", "x") },
+		func(tx *jsonptr.Tx) error { return tx.Set("/b/1", "x") },
+		func(tx *jsonptr.Tx) error { return tx.Set("/b/-", "x") },
+		func(tx *jsonptr.Tx) error { return tx.Set("/b/5", "x") },
+		func(tx *jsonptr.Tx) error { return tx.Set("/m/k", "x") },
+		func(tx *jsonptr.Tx) error { return tx.Set("/m/l", "x") },
+		func(tx *jsonptr.Tx) error { return tx.Set("", "x") },
+		func(tx *jsonptr.Tx) error { _, err := tx.Delete("/a/y"); return err },
+		func(tx *jsonptr.Tx) error { _, err := tx.Delete("/a/x"); return err },
+		func(tx *jsonptr.Tx) error { _, err := tx.Delete("/b/0"); return err },
+		func(tx *jsonptr.Tx) error { _, err := tx.Delete("/b/2"); return err },
+		func(tx *jsonptr.Tx) error { _, err := tx.Delete("/c"); return err },
+		func(tx *jsonptr.Tx) error {
+			for _, ptr := range []string{"/b/1", "/b/0", "/a/y", "/a/z", "/a/x"} {
+				if _, err := tx.Delete(ptr); err != nil {
+					return err
+				}
+			}
+			if err := tx.Set("/a/w", []interface{}{}); err != nil {
+				return err
+			}
+			if err := tx.Set("/a/w/-", 1); err != nil {
+				return err
+			}
+			if v, err := tx.Get("/a/w/0"); err != nil || v != 1 {
+				t.Errorf("Get in transaction: got %v, %v", v, err)
+			}
+			return tx.Set("/b/0", "x")
+		},
+	} {
+		err := s.Update(func(tx *jsonptr.Tx) error {
+			if err := ops(tx); err != nil {
+				t.Error(err)
+			}
+			return fail
+		})
+		if err != fail {
+			t.Errorf("got %v", err)
+		}
+		_ = s.View(func(doc interface{}) error {
+			if b, _ := json.Marshal(doc); string(b) != original {
+				t.Errorf("not rolled back: %s", b)
+			}
+			return nil
+		})
+	}
+
+	// Rollback on panic
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("got %v", r)
+			}
+		}()
+		_ = s.Update(func(tx *jsonptr.Tx) error {
+			_ = tx.Set("/c", 1)
+			panic("boom")
+		})
+	}()
+	if v, _ := s.Get("/c"); v != "c" {
+		t.Errorf("got %v", v)
+	}
+
+	// Errors
+	if err := s.Set("/x/y", 1); err == nil {
+		t.Error("error expected")
+	}
+	if err := s.Set("x", 1); err == nil {
+		t.Error("error expected")
+	}
+	if _, err := s.Delete(""); err == nil {
+		t.Error("error expected")
+	}
+	if _, err := s.Delete("/b/3"); err == nil {
+		t.Error("error expected")
+	}
+}
+
+func TestStoreWatch(t *testing.T) {
+	s := jsonptr.NewStore(map[string]interface{}{
+		"server": map[string]interface{}{"port": 80, "host": "localhost"},
+		"users":  []interface{}{"a", "b", "c"},
+	})
+	var events []string
+	watch := func(prefix string) func() {
+		return s.Watch(jsonptr.MustParse(prefix), func(ev jsonptr.Event) {
+			events = append(events, fmt.Sprintf("%s: %s %s %v -> %v", prefix, ev.Op, ev.Ptr, ev.Old, ev.New))
+		})
+	}
+	cancel := watch("/server/port")
+	watch("/server")
+	watch("/users/1")
+	watch("")
+
+	for _, test := range []struct {
+		update   func(tx *jsonptr.Tx) error
+		expected []string
+	}{
+		{
+			func(tx *jsonptr.Tx) error { return tx.Set("/server/port", 8080) },
+			[]string{
+				"/server/port: replace /server/port 80 -> 8080",
+				"/server: replace /server/port 80 -> 8080",
+				": replace /server/port 80 -> 8080",
+			},
+		},
+		{
+			func(tx *jsonptr.Tx) error { return tx.Set("/server/tls", true) },
+			[]string{
+				"/server: add /server/tls <nil> -> true",
+				": add /server/tls <nil> -> true",
+			},
+		},
+		{
+			func(tx *jsonptr.Tx) error { return tx.Set("/users/-", "d") },
+			[]string{": add /users/3 <nil> -> d"},
+		},
+		{
+			func(tx *jsonptr.Tx) error { _, err := tx.Delete("/users/0"); return err },
+			[]string{
+				"/users/1: remove /users/0 a -> <nil>",
+				": remove /users/0 a -> <nil>",
+			},
+		},
+		{
+			func(tx *jsonptr.Tx) error { _, err := tx.Delete("/users/2"); return err },
+			[]string{": remove /users/2 d -> <nil>"},
+		},
+		{
+			func(tx *jsonptr.Tx) error {
+				if err := tx.Set("/server", map[string]interface{}{}); err != nil {
+					return err
+				}
+				return tx.Set("/server/port", 443)
+			},
+			[]string{
+				"/server/port: replace /server map[host:localhost port:8080 tls:true] -> map[]",
+				"/server: replace /server map[host:localhost port:8080 tls:true] -> map[]",
+				": replace /server map[host:localhost port:8080 tls:true] -> map[]",
+				"/server/port: add /server/port <nil> -> 443",
+				"/server: add /server/port <nil> -> 443",
+				": add /server/port <nil> -> 443",
+			},
+		},
+		{
+			func(tx *jsonptr.Tx) error { return nil },
+			nil,
+		},
+	} {
+		events = nil
+		if err := s.Update(test.update); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(events, test.expected) {
+			t.Errorf("got:\n%q\nexpected:\n%q", events, test.expected)
+		}
+	}
+
+	cancel()
+	events = nil
+	_ = s.Set("/server/port", 1)
+	if len(events) != 2 {
+		t.Errorf("got %q", events)
+	}
+}
+
+func TestStoreConcurrency(t *testing.T) {
+	s := jsonptr.NewStore(map[string]interface{}{"counters": map[string]interface{}{}})
+	var mu sync.Mutex
+	notified := 0
+	s.Watch(jsonptr.Pointer{"counters"}, func(ev jsonptr.Event) {
+		mu.Lock()
+		notified++
+		mu.Unlock()
+	})
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			key := "/counters/" + strconv.Itoa(g%2)
+			for i := 0; i < 100; i++ {
+				err := s.Update(func(tx *jsonptr.Tx) error {
+					v, err := tx.Get(key)
+					if err != nil {
+						v = 0
+					}
+					return tx.Set(key, v.(int)+1)
+				})
+				if err != nil {
+					t.Error(err)
+				}
+				_, _ = s.Get("/counters")
+			}
+		}(g)
+	}
+	wg.Wait()
+	for _, ptr := range []string{"/counters/0", "/counters/1"} {
+		if v, _ := s.Get(ptr); v != 400 {
+			t.Errorf("%s: got %v", ptr, v)
+		}
+	}
+	if notified != 800 {
+		t.Errorf("got %d notifications", notified)
+	}
+}
+
+func TestStoreWatcherReads(t *testing.T) {
+	s := jsonptr.NewStore(map[string]interface{}{"n": 0})
+	// Watchers read the store while other updates are waiting
+	var seen []int
+	s.Watch(jsonptr.Pointer{"n"}, func(ev jsonptr.Event) {
+		// Leave time to another update to commit
+		time.Sleep(100 * time.Microsecond)
+		if _, err := s.Get("/n"); err != nil {
+			t.Error(err)
+		}
+		seen = append(seen, ev.New.(int))
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					_ = s.Update(func(tx *jsonptr.Tx) error {
+						v, _ := tx.Get("/n")
+						return tx.Set("/n", v.(int)+1)
+					})
+				}
+			}()
+		}
+		wg.Wait()
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("deadlock")
+	}
+	// Notifications are in the order of the updates
+	if len(seen) != 800 {
+		t.Fatalf("got %d notifications", len(seen))
+	}
+	for i, n := range seen {
+		if n != i+1 {
+			t.Fatalf("notification %d: got %d", i, n)
+		}
+	}
+}
+
+func TestStoreSnapshots(t *testing.T) {
+	doc, err := jsonptr.UnmarshalOrdered([]byte(`{"a":{"b":[1,{"c":2}],"o":{"x":1}},"t":{"s":[]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.(*jsonptr.Object).Set("typed", map[string][]interface{}{"l": {1}})
+	s := jsonptr.NewStore(doc)
+	snapshot := func() string {
+		var b []byte
+		_ = s.View(func(doc interface{}) error {
+			b, _ = json.Marshal(doc)
+			return nil
+		})
+		return string(b)
+	}
+	before := snapshot()
+	var root, a interface{}
+	_ = s.View(func(doc interface{}) error { root = doc; return nil })
+	a, _ = s.Get("/a")
+	var events []jsonptr.Event
+	s.Watch(nil, func(ev jsonptr.Event) { events = append(events, ev) })
+
+	err = s.Update(func(tx *jsonptr.Tx) error {
+		for _, ptr := range []string{"/a/b/1/c", "/a/b/-", "/a/o/y", "/t/s/-", "/typed/l/0", "/a/b/1/d"} {
+			if err := tx.Set(ptr, 3); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Delete("/a/b/0"); err != nil {
+			return err
+		}
+		// The new value is not modified by the following changes
+		if err := tx.Set("/n", map[string]interface{}{}); err != nil {
+			return err
+		}
+		if err := tx.Set("/n/x", 1); err != nil {
+			return err
+		}
+		return tx.Patch([]jsonptr.Operation{
+			{Op: "move", From: "/a/o", Path: "/a/b/0/o"},
+			{Op: "add", Path: "/a/b/0/o/z", Value: 4},
+			{Op: "remove", Path: "/t/s/0"},
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Values published before are unchanged
+	if b, _ := json.Marshal(root); string(b) != before {
+		t.Errorf("document modified:\n%s\n%s", b, before)
+	}
+	if b, _ := json.Marshal(a); string(b) != `{"b":[1,{"c":2}],"o":{"x":1}}` {
+		t.Errorf("got %s", b)
+	}
+	const expected = `{"a":{"b":[{"c":3,"d":3,"o":{"x":1,"y":3,"z":4}},3]},"t":{"s":[]},"typed":{"l":[3]},"n":{"x":1}}`
+	if got := snapshot(); got != expected {
+		t.Errorf("got %s", got)
+	}
+	for _, ev := range events {
+		if ev.Ptr.String() == "/n" {
+			if b, _ := json.Marshal(ev.New); string(b) != `{}` {
+				t.Errorf("event: got %s", b)
+			}
+		}
+	}
+
+	// A failed patch keeps the previous changes of the transaction
+	before = snapshot()
+	err = s.Update(func(tx *jsonptr.Tx) error {
+		if err := tx.Set("/a/b/1", 5); err != nil {
+			return err
+		}
+		if err := tx.Patch([]jsonptr.Operation{
+			{Op: "add", Path: "/a/b/0/q", Value: 1},
+			{Op: "remove", Path: "/a/b/1"},
+			{Op: "remove", Path: "/a/nope"},
+		}); err == nil {
+			t.Error("error expected")
+		}
+		if v, err := tx.Get("/a/b"); err != nil || !reflect.DeepEqual(v, []interface{}{jsonptr.MustValue(tx.Get("/a/b/0")), 5}) {
+			t.Errorf("got %v, %v", v, err)
+		}
+		if _, err := tx.Get("/a/b/0/q"); err == nil {
+			t.Error("patch not reverted")
+		}
+		return errors.New("fail")
+	})
+	if got := snapshot(); got != before {
+		t.Errorf("not rolled back: %s", got)
+	}
+}
+
+func TestStoreRace(t *testing.T) {
+	s := jsonptr.NewStore(map[string]interface{}{
+		"m": map[string]interface{}{"a": []interface{}{1, 2}},
+		"l": []interface{}{},
+	})
+	// Readers walk the values returned by the store while writers update them
+	var walk func(v interface{}) int
+	walk = func(v interface{}) int {
+		n := 1
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for _, x := range v {
+				n += walk(x)
+			}
+		case []interface{}:
+			for _, x := range v {
+				n += walk(x)
+			}
+		}
+		return n
+	}
+	var mu sync.Mutex
+	var published []interface{}
+	s.Watch(nil, func(ev jsonptr.Event) {
+		mu.Lock()
+		published = append(published, ev.Old, ev.New)
+		mu.Unlock()
+	})
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				v, _ := s.Get("/m")
+				walk(v)
+				var doc interface{}
+				_ = s.View(func(d interface{}) error {
+					doc = d
+					return nil
+				})
+				walk(doc)
+				mu.Lock()
+				values := published
+				mu.Unlock()
+				for _, v := range values {
+					walk(v)
+				}
+			}
+		}()
+	}
+	var writers sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		writers.Add(1)
+		go func(g int) {
+			defer writers.Done()
+			key := strconv.Itoa(g)
+			for i := 0; i < 200; i++ {
+				_ = s.Update(func(tx *jsonptr.Tx) error {
+					if err := tx.Set("/m/"+key, map[string]interface{}{"i": i}); err != nil {
+						return err
+					}
+					if err := tx.Set("/m/a/-", i); err != nil {
+						return err
+					}
+					if _, err := tx.Delete("/m/a/0"); err != nil {
+						return err
+					}
+					if err := tx.Set("/l/-", key); err != nil {
+						return err
+					}
+					return tx.Patch([]jsonptr.Operation{{Op: "replace", Path: "/m/" + key + "/i", Value: -i}})
+				})
+			}
+		}(g)
+	}
+	writers.Wait()
+	close(done)
+	wg.Wait()
+
+	if v, err := s.Get("/m/a"); err != nil || len(v.([]interface{})) != 2 {
+		t.Errorf("got %v, %v", v, err)
+	}
+	if v, err := s.Get("/l"); err != nil || len(v.([]interface{})) != 800 {
+		t.Errorf("got %d elements, %v", len(v.([]interface{})), err)
+	}
+}
+
+func ExampleStore() {
+	config := jsonptr.NewStore(map[string]interface{}{
+		"server": map[string]interface{}{"host": "localhost", "port": 8080},
+	})
+	config.Watch(jsonptr.Pointer{"server"}, func(ev jsonptr.Event) {
+		fmt.Println(ev.Op, ev.Ptr, ev.Old, "->", ev.New)
+	})
+
+	// Atomic update of several values
+	_ = config.Update(func(tx *jsonptr.Tx) error {
+		if err := tx.Set("/server/host", "example.com"); err != nil {
+			return err
+		}
+		return tx.Set("/server/port", 443)
+	})
+	// A failed transaction changes nothing
+	err := config.Update(func(tx *jsonptr.Tx) error {
+		_ = tx.Set("/server/port", 80)
+		return tx.Set("/server/tls/cert", "x")
+	})
+	fmt.Println(err)
+	port, _ := config.Get("/server/port")
+	fmt.Println(port)
+	// Output:
+	// replace /server/host localhost -> example.com
+	// replace /server/port 8080 -> 443
+	// "/server/tls": property not found
+	// 443
+}
@@ -0,0 +1,227 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrParam is wrapped in the error of Template.Expand for a missing
+	// parameter.
+	ErrParam = errors.New("missing template parameter")
+
+	// ErrNoRoute is wrapped in the error of Router.Dispatch if no template
+	// matches.
+	ErrNoRoute = errors.New("no matching template")
+)
+
+// Template is a JSON Pointer pattern with named parameters, such as
+// /users/{id}/roles/{role}. A parameter matches a whole reference token.
+type Template struct {
+	src    string
+	tokens []templateToken
+}
+
+type templateToken struct {
+	value   string // unescaped literal, or parameter name
+	isParam bool
+}
+
+// ParseTemplate parses a template: a JSON Pointer where reference tokens
+// written {name} are parameters. Braces are not allowed in other tokens.
+// Parameter names must be unique.
+//
+// In case of error a *BadPointerError is returned.
+func ParseTemplate(template string) (*Template, error) {
+	if err := checkSyntax(template); err != nil {
+		return nil, err
+	}
+	t := &Template{src: template}
+	if template == "" {
+		return t, nil
+	}
+	names := make(map[string]bool)
+	p := 1
+	for _, tok := range strings.Split(template[1:], "/") {
+		p += len(tok)
+		switch {
+		case strings.HasPrefix(tok, "{") && strings.HasSuffix(tok, "}") && len(tok) > 2:
+			name := tok[1 : len(tok)-1]
+			if strings.ContainsAny(name, "{}~") || names[name] {
+				return nil, tokenError(template, p, ErrSyntax)
+			}
+			names[name] = true
+			t.tokens = append(t.tokens, templateToken{value: name, isParam: true})
+		case strings.ContainsAny(tok, "{}"):
+			return nil, tokenError(template, p, ErrSyntax)
+		default:
+			// No error as the syntax has been checked
+			tok, _ = UnescapeString(tok)
+			t.tokens = append(t.tokens, templateToken{value: tok})
+		}
+		p++
+	}
+	return t, nil
+}
+
+// MustParseTemplate wraps ParseTemplate and panics in case of error.
+func MustParseTemplate(template string) *Template {
+	t, err := ParseTemplate(template)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// String returns the template as given to [ParseTemplate].
+func (t *Template) String() string {
+	return t.src
+}
+
+// Params returns the names of the parameters, in order.
+func (t *Template) Params() []string {
+	var names []string
+	for _, tok := range t.tokens {
+		if tok.isParam {
+			names = append(names, tok.value)
+		}
+	}
+	return names
+}
+
+// Match reports if ptr matches the template, and returns the values
+// (unescaped) of the parameters.
+func (t *Template) Match(ptr Pointer) (map[string]string, bool) {
+	if len(ptr) != len(t.tokens) {
+		return nil, false
+	}
+	for i, tok := range t.tokens {
+		if !tok.isParam && ptr[i] != tok.value {
+			return nil, false
+		}
+	}
+	var params map[string]string
+	for i, tok := range t.tokens {
+		if tok.isParam {
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[tok.value] = ptr[i]
+		}
+	}
+	return params, true
+}
+
+// Expand returns the pointer made by replacing the parameters with their
+// values in params, escaped with [EscapeString].
+//
+// A missing parameter is reported as a *BadPointerError wrapping ErrParam.
+func (t *Template) Expand(params map[string]string) (string, error) {
+	var dst []byte
+	p := 0
+	for _, tok := range t.tokens {
+		dst = append(dst, '/')
+		if !tok.isParam {
+			dst = AppendEscape(dst, tok.value)
+			p += 1 + len(EscapeString(tok.value))
+			continue
+		}
+		p += 3 + len(tok.value)
+		v, ok := params[tok.value]
+		if !ok {
+			return "", tokenError(t.src, p, ErrParam)
+		}
+		dst = AppendEscape(dst, v)
+	}
+	return string(dst), nil
+}
+
+// compare orders templates by specificity: at the first position where they
+// differ, a literal is more specific than a parameter. 0 means the templates
+// match the same pointers.
+func (t *Template) compare(u *Template) int {
+	if len(t.tokens) != len(u.tokens) {
+		return len(t.tokens) - len(u.tokens)
+	}
+	for i, a := range t.tokens {
+		b := u.tokens[i]
+		switch {
+		case a.isParam && b.isParam:
+		case a.isParam:
+			return 1
+		case b.isParam:
+			return -1
+		case a.value != b.value:
+			return strings.Compare(a.value, b.value)
+		}
+	}
+	return 0
+}
+
+// RouteFunc is the handler of a route of a [Router].
+type RouteFunc func(ptr Pointer, params map[string]string) error
+
+// Router dispatches pointers to the handler of the most specific template
+// that matches: at the first reference token where two matching templates
+// differ, the one with a literal wins over the one with a parameter.
+//
+// The zero value is an empty router, ready to use. Handle must not be
+// called concurrently with Match or Dispatch.
+type Router struct {
+	routes []route // Sorted by specificity
+}
+
+type route struct {
+	t  *Template
+	fn RouteFunc
+}
+
+// Handle registers fn for the template pattern.
+//
+// Like [net/http.ServeMux.Handle], it panics if the pattern is invalid or
+// if a template matching the same pointers is already registered.
+func (r *Router) Handle(pattern string, fn RouteFunc) {
+	t := MustParseTemplate(pattern)
+	i := sort.Search(len(r.routes), func(i int) bool {
+		return r.routes[i].t.compare(t) >= 0
+	})
+	if i < len(r.routes) && r.routes[i].t.compare(t) == 0 {
+		panic("jsonptr: template " + pattern + " conflicts with " + r.routes[i].t.src)
+	}
+	r.routes = append(r.routes, route{})
+	copy(r.routes[i+1:], r.routes[i:])
+	r.routes[i] = route{t: t, fn: fn}
+}
+
+// Match returns the most specific template matching ptr, and the values of
+// its parameters. The template is nil if none matches.
+func (r *Router) Match(ptr Pointer) (*Template, map[string]string) {
+	_, t, params := r.match(ptr)
+	return t, params
+}
+
+func (r *Router) match(ptr Pointer) (RouteFunc, *Template, map[string]string) {
+	for _, rt := range r.routes {
+		if params, ok := rt.t.Match(ptr); ok {
+			return rt.fn, rt.t, params
+		}
+	}
+	return nil, nil, nil
+}
+
+// Dispatch calls the handler of the most specific template matching ptr
+// and returns its error.
+//
+// If no template matches, a *PtrError wrapping ErrNoRoute is returned.
+func (r *Router) Dispatch(ptr Pointer) error {
+	fn, t, params := r.match(ptr)
+	if t == nil {
+		return &PtrError{Ptr: ptr.String(), Err: ErrNoRoute}
+	}
+	return fn(ptr, params)
+}
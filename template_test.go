@@ -0,0 +1,154 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+func TestTemplate(t *testing.T) {
+	for _, test := range []struct {
+		template string
+		ptr      string
+		params   map[string]string
+		match    bool
+	}{
+		{``, ``, nil, true},
+		{``, `/a`, nil, false},
+		{`/users/{id}/roles/{role}`, `/users/42/roles/admin`, map[string]string{"id": "42", "role": "admin"}, true},
+		{`/users/{id}/roles/{role}`, `/users/a~1b/roles/~0`, map[string]string{"id": "a/b", "role": "~"}, true},
+		{`/users/{id}/roles/{role}`, `/users/42/roles`, nil, false},
+		{`/users/{id}/roles/{role}`, `/users/42/role/admin`, nil, false},
+		{`/users/{id}/roles/{role}`, `/users/42/roles/admin/x`, nil, false},
+		{`/a~1b/{x}`, `/a~1b/`, map[string]string{"x": ""}, true},
+		{`/a~1b/{x}`, `/a/b/c`, nil, false},
+	} {
+		tmpl, err := jsonptr.ParseTemplate(test.template)
+		if err != nil {
+			t.Errorf("%s: %v", test.template, err)
+			continue
+		}
+		params, ok := tmpl.Match(jsonptr.MustParse(test.ptr))
+		if ok != test.match || !reflect.DeepEqual(params, test.params) {
+			t.Errorf("%s %q: got %v, %v", test.template, test.ptr, params, ok)
+		}
+		if ok {
+			if ptr, err := tmpl.Expand(params); err != nil || ptr != test.ptr {
+				t.Errorf("%s %v: Expand: got %q, %v", test.template, params, ptr, err)
+			}
+		}
+	}
+
+	tmpl := jsonptr.MustParseTemplate(`/users/{id}/roles/{role}`)
+	if got := tmpl.Params(); !reflect.DeepEqual(got, []string{"id", "role"}) {
+		t.Errorf("Params: got %v", got)
+	}
+	_, err := tmpl.Expand(map[string]string{"id": "1"})
+	if e, ok := err.(*jsonptr.BadPointerError); !ok || e.Err != jsonptr.ErrParam || e.BadPtr != `/users/{id}/roles/{role}` || e.Offset != 18 {
+		t.Errorf("Expand: got %#v", err)
+	} else {
+		t.Log(err)
+	}
+
+	for _, bad := range []string{`users`, `/a/{x}/{x}`, `/a{x}`, `/{x}y`, `/{a{b}`, `/{a~0}`, `/{}`, `/~2`} {
+		_, err := jsonptr.ParseTemplate(bad)
+		if e, ok := err.(*jsonptr.BadPointerError); !ok || e.Err != jsonptr.ErrSyntax {
+			t.Errorf("%s: got %v", bad, err)
+		} else {
+			t.Log(err)
+		}
+	}
+}
+
+func TestRouter(t *testing.T) {
+	var r jsonptr.Router
+	var got string
+	for _, pattern := range []string{
+		`/users/{id}/roles/{role}`,
+		`/users/{id}/roles/admin`,
+		`/users/me/roles/{role}`,
+		`/users/{id}`,
+		`/users`,
+		`/{coll}/{id}`,
+		``,
+	} {
+		pattern := pattern
+		r.Handle(pattern, func(ptr jsonptr.Pointer, params map[string]string) error {
+			got = fmt.Sprint(pattern, " ", params)
+			return nil
+		})
+	}
+	for _, test := range []struct {
+		ptr      string
+		expected string
+	}{
+		{``, ` map[]`},
+		{`/users`, `/users map[]`},
+		{`/users/1`, `/users/{id} map[id:1]`},
+		{`/groups/1`, `/{coll}/{id} map[coll:groups id:1]`},
+		{`/users/1/roles/dev`, `/users/{id}/roles/{role} map[id:1 role:dev]`},
+		{`/users/1/roles/admin`, `/users/{id}/roles/admin map[id:1]`},
+		{`/users/me/roles/admin`, `/users/me/roles/{role} map[role:admin]`},
+		{`/users/me/roles/dev`, `/users/me/roles/{role} map[role:dev]`},
+	} {
+		got = ""
+		if err := r.Dispatch(jsonptr.MustParse(test.ptr)); err != nil {
+			t.Errorf("%q: %v", test.ptr, err)
+		} else if got != test.expected {
+			t.Errorf("%q: got %q, expected %q", test.ptr, got, test.expected)
+		}
+	}
+
+	err := r.Dispatch(jsonptr.Pointer{"users", "1", "groups"})
+	if e, ok := err.(*jsonptr.PtrError); !ok || e.Err != jsonptr.ErrNoRoute || e.Ptr != "/users/1/groups" {
+		t.Errorf("got %v", err)
+	}
+	if tmpl, _ := r.Match(jsonptr.Pointer{"a"}); tmpl != nil {
+		t.Errorf("got %v", tmpl)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic expected")
+			}
+		}()
+		r.Handle(`/users/{name}/roles/{r}`, nil)
+	}()
+}
+
+func ExampleRouter() {
+	var r jsonptr.Router
+	r.Handle("/users/{id}", func(ptr jsonptr.Pointer, params map[string]string) error {
+		fmt.Println("user", params["id"])
+		return nil
+	})
+	r.Handle("/users/{id}/roles/{role}", func(ptr jsonptr.Pointer, params map[string]string) error {
+		fmt.Println("role", params["role"], "of user", params["id"])
+		return nil
+	})
+	r.Handle("/users/{id}/roles/admin", func(ptr jsonptr.Pointer, params map[string]string) error {
+		fmt.Println("admin role of user", params["id"])
+		return nil
+	})
+
+	_ = r.Dispatch(jsonptr.MustParse("/users/alice"))
+	_ = r.Dispatch(jsonptr.MustParse("/users/alice/roles/editor"))
+	_ = r.Dispatch(jsonptr.MustParse("/users/bob/roles/admin"))
+	fmt.Println(r.Dispatch(jsonptr.MustParse("/groups/staff")))
+
+	ptr, _ := jsonptr.MustParseTemplate("/users/{id}").Expand(map[string]string{"id": "a/b"})
+	fmt.Println(ptr)
+	// Output:
+	// user alice
+	// role editor of user alice
+	// admin role of user bob
+	// "/groups/staff": no matching template
+	// /users/a~1b
+}
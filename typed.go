@@ -0,0 +1,372 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr
+
+// Containers of common concrete Go types are handled by type switches,
+// without reflection, in addition to the data model of encoding/json.
+//
+// Arrays: []string, []bool, []int, []float64, []map[string]interface{}.
+//
+// Objects: map[string]string, map[string]bool, map[string]int,
+// map[string]float64, map[string][]interface{},
+// map[string]map[string]interface{}.
+
+type containerKind int8
+
+const (
+	notTyped containerKind = iota
+	typedArray
+	typedObject
+)
+
+// typedKind tells if doc is one of the typed containers.
+func typedKind(doc interface{}) containerKind {
+	switch doc.(type) {
+	case []string, []bool, []int, []float64, []map[string]interface{}:
+		return typedArray
+	case map[string]string, map[string]bool, map[string]int, map[string]float64,
+		map[string][]interface{}, map[string]map[string]interface{}:
+		return typedObject
+	default:
+		return notTyped
+	}
+}
+
+// typedLen returns the length of a typed array.
+func typedLen(doc interface{}) int {
+	switch a := doc.(type) {
+	case []string:
+		return len(a)
+	case []bool:
+		return len(a)
+	case []int:
+		return len(a)
+	case []float64:
+		return len(a)
+	case []map[string]interface{}:
+		return len(a)
+	}
+	return 0
+}
+
+// typedKeys returns the keys of a typed object, for propertyError.
+func typedKeys(doc interface{}) []string {
+	var keys []string
+	switch m := doc.(type) {
+	case map[string]string:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]bool:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]int:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]float64:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string][]interface{}:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]map[string]interface{}:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// getTypedIndex returns element n of a typed array.
+func getTypedIndex(doc interface{}, n int) (interface{}, bool) {
+	if n < 0 || n >= typedLen(doc) {
+		return nil, false
+	}
+	switch a := doc.(type) {
+	case []string:
+		return a[n], true
+	case []bool:
+		return a[n], true
+	case []int:
+		return a[n], true
+	case []float64:
+		return a[n], true
+	case []map[string]interface{}:
+		return a[n], true
+	}
+	return nil, false
+}
+
+// getTypedKey returns the value of property key of a typed object.
+func getTypedKey(doc interface{}, key string) (v interface{}, found bool) {
+	switch m := doc.(type) {
+	case map[string]string:
+		v, found = m[key]
+	case map[string]bool:
+		v, found = m[key]
+	case map[string]int:
+		v, found = m[key]
+	case map[string]float64:
+		v, found = m[key]
+	case map[string][]interface{}:
+		v, found = m[key]
+	case map[string]map[string]interface{}:
+		v, found = m[key]
+	}
+	return
+}
+
+// setTypedIndex sets element n of a typed array, growing the array with zero
+// values if n is beyond its end. If the array grows, the new slice is
+// returned and has to replace the old one in its parent.
+//
+// ok is false if value doesn't have the type of the elements.
+func setTypedIndex(doc interface{}, n int, value interface{}) (grown interface{}, ok bool) {
+	switch a := doc.(type) {
+	case []string:
+		var v string
+		if v, ok = value.(string); ok {
+			if n < len(a) {
+				a[n] = v
+			} else {
+				grown = append(append(a, make([]string, n-len(a))...), v)
+			}
+		}
+	case []bool:
+		var v bool
+		if v, ok = value.(bool); ok {
+			if n < len(a) {
+				a[n] = v
+			} else {
+				grown = append(append(a, make([]bool, n-len(a))...), v)
+			}
+		}
+	case []int:
+		var v int
+		if v, ok = value.(int); ok {
+			if n < len(a) {
+				a[n] = v
+			} else {
+				grown = append(append(a, make([]int, n-len(a))...), v)
+			}
+		}
+	case []float64:
+		var v float64
+		if v, ok = value.(float64); ok {
+			if n < len(a) {
+				a[n] = v
+			} else {
+				grown = append(append(a, make([]float64, n-len(a))...), v)
+			}
+		}
+	case []map[string]interface{}:
+		var v map[string]interface{}
+		if v, ok = value.(map[string]interface{}); ok || value == nil {
+			ok = true
+			if n < len(a) {
+				a[n] = v
+			} else {
+				grown = append(append(a, make([]map[string]interface{}, n-len(a))...), v)
+			}
+		}
+	}
+	return
+}
+
+// setTypedKey sets property key of a typed object. If the object is a nil
+// map, a new map is returned and has to replace it in its parent.
+//
+// ok is false if value doesn't have the type of the elements.
+func setTypedKey(doc interface{}, key string, value interface{}) (created interface{}, ok bool) {
+	switch m := doc.(type) {
+	case map[string]string:
+		var v string
+		if v, ok = value.(string); ok {
+			if m == nil {
+				return map[string]string{key: v}, true
+			}
+			m[key] = v
+		}
+	case map[string]bool:
+		var v bool
+		if v, ok = value.(bool); ok {
+			if m == nil {
+				return map[string]bool{key: v}, true
+			}
+			m[key] = v
+		}
+	case map[string]int:
+		var v int
+		if v, ok = value.(int); ok {
+			if m == nil {
+				return map[string]int{key: v}, true
+			}
+			m[key] = v
+		}
+	case map[string]float64:
+		var v float64
+		if v, ok = value.(float64); ok {
+			if m == nil {
+				return map[string]float64{key: v}, true
+			}
+			m[key] = v
+		}
+	case map[string][]interface{}:
+		var v []interface{}
+		if v, ok = value.([]interface{}); ok || value == nil {
+			if m == nil {
+				return map[string][]interface{}{key: v}, true
+			}
+			m[key] = v
+			ok = true
+		}
+	case map[string]map[string]interface{}:
+		var v map[string]interface{}
+		if v, ok = value.(map[string]interface{}); ok || value == nil {
+			if m == nil {
+				return map[string]map[string]interface{}{key: v}, true
+			}
+			m[key] = v
+			ok = true
+		}
+	}
+	return
+}
+
+// deleteTypedIndex removes element n (which must exist) of a typed array,
+// and returns it with the shortened array.
+func deleteTypedIndex(doc interface{}, n int) (v interface{}, rest interface{}) {
+	switch a := doc.(type) {
+	case []string:
+		v = a[n]
+		rest = append(a[:n], a[n+1:]...)
+	case []bool:
+		v = a[n]
+		rest = append(a[:n], a[n+1:]...)
+	case []int:
+		v = a[n]
+		rest = append(a[:n], a[n+1:]...)
+	case []float64:
+		v = a[n]
+		rest = append(a[:n], a[n+1:]...)
+	case []map[string]interface{}:
+		v = a[n]
+		rest = append(a[:n], a[n+1:]...)
+	}
+	return
+}
+
+// deleteTypedKey removes property key of a typed object.
+func deleteTypedKey(doc interface{}, key string) (v interface{}, found bool) {
+	if v, found = getTypedKey(doc, key); !found {
+		return nil, false
+	}
+	switch m := doc.(type) {
+	case map[string]string:
+		delete(m, key)
+	case map[string]bool:
+		delete(m, key)
+	case map[string]int:
+		delete(m, key)
+	case map[string]float64:
+		delete(m, key)
+	case map[string][]interface{}:
+		delete(m, key)
+	case map[string]map[string]interface{}:
+		delete(m, key)
+	}
+	return v, true
+}
+
+// copyTyped returns a shallow copy of a typed container. A nil container
+// is returned as is.
+func copyTyped(doc interface{}) (interface{}, bool) {
+	switch d := doc.(type) {
+	case []string:
+		if d != nil {
+			d = append(make([]string, 0, len(d)), d...)
+		}
+		return d, true
+	case []bool:
+		if d != nil {
+			d = append(make([]bool, 0, len(d)), d...)
+		}
+		return d, true
+	case []int:
+		if d != nil {
+			d = append(make([]int, 0, len(d)), d...)
+		}
+		return d, true
+	case []float64:
+		if d != nil {
+			d = append(make([]float64, 0, len(d)), d...)
+		}
+		return d, true
+	case []map[string]interface{}:
+		if d != nil {
+			d = append(make([]map[string]interface{}, 0, len(d)), d...)
+		}
+		return d, true
+	case map[string]string:
+		if d == nil {
+			return d, true
+		}
+		m := make(map[string]string, len(d))
+		for k, v := range d {
+			m[k] = v
+		}
+		return m, true
+	case map[string]bool:
+		if d == nil {
+			return d, true
+		}
+		m := make(map[string]bool, len(d))
+		for k, v := range d {
+			m[k] = v
+		}
+		return m, true
+	case map[string]int:
+		if d == nil {
+			return d, true
+		}
+		m := make(map[string]int, len(d))
+		for k, v := range d {
+			m[k] = v
+		}
+		return m, true
+	case map[string]float64:
+		if d == nil {
+			return d, true
+		}
+		m := make(map[string]float64, len(d))
+		for k, v := range d {
+			m[k] = v
+		}
+		return m, true
+	case map[string][]interface{}:
+		if d == nil {
+			return d, true
+		}
+		m := make(map[string][]interface{}, len(d))
+		for k, v := range d {
+			m[k] = v
+		}
+		return m, true
+	case map[string]map[string]interface{}:
+		if d == nil {
+			return d, true
+		}
+		m := make(map[string]map[string]interface{}, len(d))
+		for k, v := range d {
+			m[k] = v
+		}
+		return m, true
+	}
+	return doc, false
+}
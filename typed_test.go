@@ -0,0 +1,229 @@
+// Copyright 2026 Olivier Mengué. All rights reserved.
+// Use of this source code is governed by the Apache 2.0 license that
+// can be found in the LICENSE file.
+
+package jsonptr_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dolmen-go/jsonptr"
+)
+
+func typedDoc() interface{} {
+	return map[string]interface{}{
+		"strings": []string{"a", "b"},
+		"bools":   []bool{true},
+		"ints":    []int{1, 2, 3},
+		"floats":  []float64{1.5},
+		"records": []map[string]interface{}{{"id": 1}},
+		"labels":  map[string]string{"env": "prod", "a/b": "c"},
+		"flags":   map[string]bool{"debug": false},
+		"counts":  map[string]int{"x": 1},
+		"weights": map[string]float64{"y": 0.5},
+		"lists":   map[string][]interface{}{"l": {"v"}},
+		"objects": map[string]map[string]interface{}{"o": {"k": []string{"deep"}}},
+	}
+}
+
+func TestTypedGet(t *testing.T) {
+	doc := typedDoc()
+	for _, test := range []struct {
+		ptr      string
+		expected interface{}
+	}{
+		{"/strings/1", "b"},
+		{"/bools/0", true},
+		{"/ints/2", 3},
+		{"/floats/0", 1.5},
+		{"/records/0/id", 1},
+		{"/labels/env", "prod"},
+		{"/labels/a~1b", "c"},
+		{"/flags/debug", false},
+		{"/counts/x", 1},
+		{"/weights/y", 0.5},
+		{"/lists/l/0", "v"},
+		{"/objects/o/k/0", "deep"},
+		{"/strings", []string{"a", "b"}},
+	} {
+		got, err := jsonptr.Get(doc, test.ptr)
+		if err != nil || !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("Get %q: got %#v, %v", test.ptr, got, err)
+		}
+		got, err = jsonptr.MustParse(test.ptr).In(doc)
+		if err != nil || !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("In %q: got %#v, %v", test.ptr, got, err)
+		}
+		got, err = jsonptr.MustCompile(test.ptr).Get(doc)
+		if err != nil || !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("Compiled.Get %q: got %#v, %v", test.ptr, got, err)
+		}
+	}
+}
+
+func TestTypedGetErrors(t *testing.T) {
+	doc := typedDoc()
+	for _, test := range []struct {
+		ptr string
+		err string
+	}{
+		{"/strings/2", `"/strings/2": invalid array index`},
+		{"/strings/-", `"/strings/-": invalid array index`},
+		{"/ints/x", `"/ints/x": invalid JSON pointer`},
+		{"/labels/envv", `"/labels/envv": property not found`},
+		{"/labels/env/x", `"/labels/env": not an object or array but string`},
+		{"/counts/x/0", `"/counts/x": not an object or array but int`},
+	} {
+		_, err := jsonptr.Get(doc, test.ptr)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("Get %q: got %v, expected %s", test.ptr, err, test.err)
+		}
+		_, cerr := jsonptr.MustCompile(test.ptr).Get(doc)
+		if !reflect.DeepEqual(cerr, err) {
+			t.Errorf("Compiled.Get %q: got %#v, expected %#v", test.ptr, cerr, err)
+		}
+		if _, err := jsonptr.MustParse(test.ptr).In(doc); err == nil {
+			t.Errorf("In %q: error expected", test.ptr)
+		}
+	}
+
+	_, err := jsonptr.Get(doc, "/labels/en")
+	if e, ok := err.(*jsonptr.PtrError); !ok || !reflect.DeepEqual(e.Keys, []string{"env"}) {
+		t.Errorf("got %#v", err)
+	}
+}
+
+func TestTypedSetDelete(t *testing.T) {
+	doc := typedDoc()
+	for _, test := range []struct {
+		ptr   string
+		value interface{}
+	}{
+		{"/strings/0", "z"},
+		{"/strings/-", "c"},
+		{"/bools/2", true},
+		{"/ints/0", 7},
+		{"/floats/0", 2.5},
+		{"/records/1", map[string]interface{}{"id": 2}},
+		{"/records/0/id", 9},
+		{"/labels/new", "x"},
+		{"/flags/debug", true},
+		{"/counts/y", 2},
+		{"/weights/z", 0.25},
+		{"/lists/m", []interface{}{}},
+	} {
+		if err := jsonptr.Set(&doc, test.ptr, test.value); err != nil {
+			t.Errorf("Set %q: %v", test.ptr, err)
+			continue
+		}
+		if strings.HasSuffix(test.ptr, "/-") {
+			continue
+		}
+		if got, err := jsonptr.Get(doc, test.ptr); err != nil || !reflect.DeepEqual(got, test.value) {
+			t.Errorf("Set %q: got %#v, %v", test.ptr, got, err)
+		}
+	}
+	// nil is allowed in maps and slices
+	if err := jsonptr.Set(&doc, "/objects/o", nil); err != nil {
+		t.Error(err)
+	} else if o := jsonptr.MustValue(jsonptr.Get(doc, "/objects")).(map[string]map[string]interface{}); o["o"] != nil {
+		t.Errorf("got %#v", o)
+	}
+	if got := jsonptr.MustValue(jsonptr.Get(doc, "/strings")); !reflect.DeepEqual(got, []string{"z", "b", "c"}) {
+		t.Errorf("strings: got %#v", got)
+	}
+	if got := jsonptr.MustValue(jsonptr.Get(doc, "/bools")); !reflect.DeepEqual(got, []bool{true, false, true}) {
+		t.Errorf("bools: got %#v", got)
+	}
+
+	// Nil map replaced
+	var nilMap interface{} = map[string]interface{}{"m": map[string]string(nil)}
+	if err := jsonptr.Set(&nilMap, "/m/a", "b"); err != nil {
+		t.Error(err)
+	} else if !reflect.DeepEqual(nilMap, map[string]interface{}{"m": map[string]string{"a": "b"}}) {
+		t.Errorf("got %#v", nilMap)
+	}
+
+	for _, test := range []struct {
+		ptr      string
+		expected interface{}
+		rest     interface{}
+	}{
+		{"/strings/1", "b", []string{"z", "c"}},
+		{"/ints/0", 7, []int{2, 3}},
+		{"/records/0", map[string]interface{}{"id": 9}, []map[string]interface{}{{"id": 2}}},
+		{"/labels/env", "prod", map[string]string{"a/b": "c", "new": "x"}},
+		{"/counts/x", 1, map[string]int{"y": 2}},
+	} {
+		v, err := jsonptr.Delete(&doc, test.ptr)
+		if err != nil || !reflect.DeepEqual(v, test.expected) {
+			t.Errorf("Delete %q: got %#v, %v", test.ptr, v, err)
+			continue
+		}
+		parent := jsonptr.MustParse(test.ptr)
+		parent.Pop()
+		if got, _ := parent.In(doc); !reflect.DeepEqual(got, test.rest) {
+			t.Errorf("Delete %q: got %#v", test.ptr, got)
+		}
+	}
+}
+
+func TestTypedSetErrors(t *testing.T) {
+	doc := typedDoc()
+	for _, test := range []struct {
+		ptr   string
+		value interface{}
+		err   string
+	}{
+		{"/strings/0", 1, `"/strings/0": value doesn't match the element type of []string`},
+		{"/ints/0", 1.0, `"/ints/0": value doesn't match the element type of []int`},
+		{"/labels/x", nil, `"/labels/x": value doesn't match the element type of map[string]string`},
+		{"/strings/x", "a", `"/strings/x": invalid JSON pointer`},
+	} {
+		err := jsonptr.Set(&doc, test.ptr, test.value)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("Set %q: got %v, expected %s", test.ptr, err, test.err)
+		}
+	}
+	err := jsonptr.Set(&doc, "/counts/x", "1")
+	if e, ok := err.(*jsonptr.DocumentError); !ok || e.Err != jsonptr.ErrElemType || e.JSONType != "object" {
+		t.Errorf("got %#v", err)
+	}
+	if b, _ := json.Marshal(err); !strings.Contains(string(b), `"error":"value doesn't match the element type"`) {
+		t.Errorf("got %s", b)
+	}
+
+	for _, test := range []struct {
+		ptr string
+		err string
+	}{
+		{"/strings/5", `"/strings/5": invalid array index`},
+		{"/labels/nope", `"/labels/nope": property not found`},
+	} {
+		_, err := jsonptr.Delete(&doc, test.ptr)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("Delete %q: got %v, expected %s", test.ptr, err, test.err)
+		}
+	}
+	if !reflect.DeepEqual(doc, typedDoc()) {
+		t.Errorf("document modified: %#v", doc)
+	}
+}
+
+func ExampleGet_typed() {
+	var config interface{} = map[string]interface{}{
+		"hosts":  []string{"a.example.com", "b.example.com"},
+		"labels": map[string]string{"env": "prod"},
+	}
+	fmt.Println(jsonptr.Get(config, "/hosts/1"))
+	fmt.Println(jsonptr.Get(config, "/labels/env"))
+	fmt.Println(jsonptr.Set(&config, "/hosts/-", 42))
+	// Output:
+	// b.example.com <nil>
+	// prod <nil>
+	// "/hosts/-": value doesn't match the element type of []string
+}